@@ -0,0 +1,235 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/controller"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logger"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/pkg/jwt"
+	"gin-api-scaffold-v1/pkg/mailer"
+	"gin-api-scaffold-v1/pkg/oidc"
+	"gin-api-scaffold-v1/pkg/search"
+	"gin-api-scaffold-v1/pkg/session"
+	"gin-api-scaffold-v1/pkg/snowflake"
+	"gin-api-scaffold-v1/pkg/storage"
+	myValidator "gin-api-scaffold-v1/pkg/validator"
+)
+
+// App 一个应用实例：配置、日志、MySQL / Redis 连接、ID 生成器以及在它们之上组装出来的各层
+// 所有依赖都挂在 App 上，没有包级别的全局变量，同一个进程里可以创建多个互相隔离的 App (比如并行的测试)
+type App struct {
+	Config *viper.Viper
+	Logger *zap.Logger
+	DB     *gorm.DB      // 使用 WithDB(nil) 时为 nil
+	RDB    *redis.Client // 使用 WithRedis(nil) 时为 nil
+	IDs    *snowflake.Node
+	Trans  ut.Translator
+
+	JWT     *jwt.Manager
+	Cookies *session.Manager
+	Store   *dao.Store
+	Service *logic.Service
+	Handler *controller.Handler
+
+	closers []func() error // App 自己建立的连接，Close 时关闭
+}
+
+// Option New 的可选参数，用来替换按配置创建的组件 (主要给测试用)
+type Option func(*options)
+
+type options struct {
+	logger  *zap.Logger
+	db      *gorm.DB
+	dbSet   bool
+	rdb     *redis.Client
+	rdbSet  bool
+	repos   *logic.Repositories
+	mailer  mailer.Mailer
+	storage storage.Storage
+	search  search.Engine
+}
+
+// WithLogger 使用传进来的 Logger，不再按 log.* 配置创建
+func WithLogger(l *zap.Logger) Option {
+	return func(o *options) { o.logger = l }
+}
+
+// WithDB 使用已经建立好的 MySQL 连接，不再按 mysql.* 配置连接
+// 传 nil 表示不连 MySQL (列路由、校验配置、配合 WithRepositories 使用内存实现的测试)
+// 这时只有注册、登录、Token、会话、个人资料、角色、两步验证这些用户相关的功能可用；
+// 社区、帖子、评论、API Key、第三方登录、文件上传和 MySQL 搜索没有内存实现，接口返回 dao.ErrorNoMySQL
+func WithDB(db *gorm.DB) Option {
+	return func(o *options) { o.db, o.dbSet = db, true }
+}
+
+// WithRedis 使用已经建立好的 Redis 连接 (比如 miniredis)，不再按 redis.* 配置连接；传 nil 表示不连 Redis
+func WithRedis(rdb *redis.Client) Option {
+	return func(o *options) { o.rdb, o.rdbSet = rdb, true }
+}
+
+// WithRepositories 替换用户、角色、两步验证的数据访问实现 (比如 logic.NewMemoryRepositories)
+// 其他数据仍然通过 dao.Store 直接读写 MySQL，见 WithDB
+func WithRepositories(r logic.Repositories) Option {
+	return func(o *options) { o.repos = &r }
+}
+
+// WithMailer 替换按 mail.driver 创建的发信器
+func WithMailer(m mailer.Mailer) Option {
+	return func(o *options) { o.mailer = m }
+}
+
+// WithStorage 替换按 storage.driver 创建的文件存储
+func WithStorage(s storage.Storage) Option {
+	return func(o *options) { o.storage = s }
+}
+
+// WithSearch 替换按 search.driver 选择的搜索引擎
+func WithSearch(e search.Engine) Option {
+	return func(o *options) { o.search = e }
+}
+
+// New 按配置创建一个应用实例
+// 先初始化不依赖外部服务的组件 (出错说明配置有问题)，再连接 MySQL 和 Redis；
+// 中途失败时已经建立的连接会被关掉
+func New(cfg *viper.Viper, opts ...Option) (_ *App, err error) {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	a := &App{Config: cfg, Logger: o.logger}
+	defer func() {
+		if err != nil {
+			a.Close()
+		}
+	}()
+	if a.Logger == nil {
+		a.Logger = logger.New(cfg)
+	}
+
+	// 起始时间和机器 ID 来自 app.start_time / app.machine_id，分布式部署时每台机器的 machine_id 必须不同
+	startTime, machineID := snowflakeConfig(cfg)
+	if a.IDs, err = snowflake.NewNode(startTime, machineID); err != nil {
+		return nil, fmt.Errorf("init snowflake failed: %w", err)
+	}
+	// 加载中文语言包，参数校验的错误信息才是中文
+	if a.Trans, err = myValidator.NewTranslator("zh"); err != nil {
+		return nil, fmt.Errorf("init validator failed: %w", err)
+	}
+	// 配置了 auth.jwt_keys 时加载 RS256 / EdDSA 密钥，没配置时继续使用 auth.jwt_secret 做 HS256 签名
+	if a.JWT, err = jwt.NewManager(cfg); err != nil {
+		return nil, fmt.Errorf("init jwt keys failed: %w", err)
+	}
+	a.Cookies = session.New(cfg)
+	// mail.driver 为 smtp 时通过 SMTP 服务器发信，默认 log 只写日志 / 文件
+	m := o.mailer
+	if m == nil {
+		if m, err = mailer.New(cfg, a.Logger); err != nil {
+			return nil, fmt.Errorf("init mailer failed: %w", err)
+		}
+	}
+	// storage.driver 为 s3 时存到兼容 S3 的对象存储，默认 local 存本地磁盘
+	st := o.storage
+	if st == nil {
+		if st, err = storage.New(cfg); err != nil {
+			return nil, fmt.Errorf("init storage failed: %w", err)
+		}
+	}
+
+	if err = a.connect(o); err != nil {
+		return nil, err
+	}
+	a.Store = dao.NewStore(a.DB, a.RDB)
+
+	// search.driver 为 mysql (默认) 时用 MySQL FULLTEXT 索引，memory 只适合测试和本地开发
+	engine := o.search
+	if engine == nil {
+		if engine, err = search.New(cfg, dao.MySQLSearchEngine{DB: a.Store.DB}); err != nil {
+			return nil, fmt.Errorf("init search failed: %w", err)
+		}
+	}
+	repos := logic.NewGormRepositories(a.Store.DB)
+	if o.repos != nil {
+		repos = *o.repos
+	}
+
+	a.Service = logic.NewService(logic.Deps{
+		Config:  cfg,
+		Logger:  a.Logger,
+		Store:   a.Store,
+		Repos:   repos,
+		IDs:     a.IDs,
+		JWT:     a.JWT,
+		Mailer:  m,
+		Storage: st,
+		Search:  engine,
+		OIDC:    oidc.NewRegistry(cfg),
+		Cursor:  common.NewCursorCodec(cfg.GetString("app.cursor_secret"), cfg.GetString("auth.jwt_secret")),
+	})
+	a.Handler = controller.NewHandler(a.Service, a.Logger, a.JWT, a.Cookies)
+	return a, nil
+}
+
+// connect 连接 MySQL 和 Redis，通过 WithDB / WithRedis 传进来的连接直接使用
+func (a *App) connect(o *options) (err error) {
+	if o.dbSet {
+		a.DB = o.db
+	} else {
+		if a.DB, err = dao.OpenMySQL(a.Config); err != nil {
+			return fmt.Errorf("init mysql failed: %w", err)
+		}
+		a.closers = append(a.closers, func() error {
+			sqlDB, err := a.DB.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		})
+	}
+	if o.rdbSet {
+		a.RDB = o.rdb
+	} else {
+		if a.RDB, err = dao.OpenRedis(a.Config); err != nil {
+			return fmt.Errorf("init redis failed: %w", err)
+		}
+		a.closers = append(a.closers, a.RDB.Close)
+	}
+	return nil
+}
+
+// Close 关闭 App 自己建立的 MySQL / Redis 连接，并刷新日志缓冲
+// 通过 WithDB / WithRedis 传进来的连接由调用方负责关闭
+func (a *App) Close() error {
+	var errs []error
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		errs = append(errs, a.closers[i]())
+	}
+	a.closers = nil
+	if a.Logger != nil {
+		_ = a.Logger.Sync()
+	}
+	return errors.Join(errs...)
+}
+
+// snowflakeConfig 雪花算法的起始时间和机器 ID，没配置时用上线时的默认值 2026-01-01 / 1
+// 起始时间上线后不能再改，否则新生成的 ID 可能和已有的重复
+func snowflakeConfig(cfg *viper.Viper) (startTime string, machineID int64) {
+	startTime, machineID = "2026-01-01", 1
+	if cfg.IsSet("app.start_time") {
+		startTime = cfg.GetString("app.start_time")
+	}
+	if cfg.IsSet("app.machine_id") {
+		machineID = cfg.GetInt64("app.machine_id")
+	}
+	return
+}
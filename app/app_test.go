@@ -0,0 +1,119 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/app"
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/mailer"
+	"gin-api-scaffold-v1/pkg/search"
+	"gin-api-scaffold-v1/pkg/storage"
+	"gin-api-scaffold-v1/routers"
+)
+
+// newTestApp 创建一个不连 MySQL 的应用实例，Redis 用各自的 miniredis
+func newTestApp(t *testing.T, secret string) *app.App {
+	cfg := viper.New()
+	cfg.Set("auth.jwt_secret", secret)
+	cfg.Set("auth.access_expire", 15)
+	cfg.Set("auth.refresh_expire", 24)
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	a, err := app.New(cfg,
+		app.WithLogger(zap.NewNop()),
+		app.WithDB(nil),
+		app.WithRedis(rdb),
+		app.WithRepositories(logic.NewMemoryRepositories()),
+		app.WithMailer(&mailer.FileMailer{}),
+		app.WithStorage(&storage.LocalStorage{Dir: t.TempDir(), Secret: []byte(secret)}),
+		app.WithSearch(search.NewMemoryEngine()),
+	)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { a.Close() })
+	return a
+}
+
+func postJSON(t *testing.T, r *gin.Engine, path string, body interface{}) (code common.ResCode, data json.RawMessage) {
+	jsonBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Code common.ResCode  `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Code, resp.Data
+}
+
+// TestIsolatedApps 同一个进程里的两个实例各自持有数据和密钥，互不影响
+func TestIsolatedApps(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a1, a2 := newTestApp(t, "secret-1"), newTestApp(t, "secret-2")
+	r1, r2 := routers.SetupRouter(a1), routers.SetupRouter(a2)
+
+	signUp := models.ParamSignUp{Username: "alice", Password: "123456", RePassword: "123456", Email: "alice@example.com"}
+	code, _ := postJSON(t, r1, "/api/v1/signup", signUp)
+	assert.Equal(t, common.CodeSuccess, code)
+
+	login := models.ParamLogin{Username: "alice", Password: "123456"}
+	code, data := postJSON(t, r1, "/api/v1/login", login)
+	assert.Equal(t, common.CodeSuccess, code)
+	code, _ = postJSON(t, r2, "/api/v1/login", login)
+	assert.Equal(t, common.CodeUserNotExist, code, "用户只注册在第一个实例里")
+
+	// 第一个实例签发的 Token 第二个实例不认
+	var token models.ResToken
+	assert.NoError(t, json.Unmarshal(data, &token))
+	_, err := a1.JWT.ParseToken(token.AccessToken)
+	assert.NoError(t, err)
+	_, err = a2.JWT.ParseToken(token.AccessToken)
+	assert.Error(t, err)
+
+	// 参数校验的错误信息经过各自实例的翻译器
+	code, _ = postJSON(t, r2, "/api/v1/signup", models.ParamSignUp{Username: "bob"})
+	assert.Equal(t, common.CodeInvalidParam, code)
+}
+
+// TestWithoutMySQL 不连 MySQL 时，没有内存实现的功能返回错误，不会空指针 panic
+func TestWithoutMySQL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestApp(t, "secret")
+	r := routers.SetupRouter(a)
+
+	for _, path := range []string{"/api/v1/communities", "/api/v1/posts/1"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		var resp struct {
+			Code common.ResCode `json:"code"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, common.CodeServerBusy, resp.Code, path)
+	}
+
+	_, err := a.Store.GetCommunityByID(t.Context(), 1)
+	assert.ErrorIs(t, err, dao.ErrorNoMySQL)
+}
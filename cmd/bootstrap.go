@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"gin-api-scaffold-v1/app"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/settings"
+)
+
+// 所有子命令共用的初始化步骤，serve 和 seed / create-admin / gen-token 走的是同一个 app.New，
+// 命令行工具跑出来的数据和线上服务的行为保持一致
+
+// loadConfig 加载配置 (命令行 --config 指定的文件或者当前目录下的 config.yaml)，所有子命令的第一步
+func loadConfig() (*viper.Viper, error) {
+	return settings.Load(configPath)
+}
+
+// bootstrap 完整初始化 (配置、日志、核心组件、MySQL、Redis、搜索)，需要读写业务数据的子命令使用
+// autoMigrate 为 true 时按 mysql.auto_migrate 在连上数据库后执行还没执行的迁移
+// 调用方用完后要调用 App.Close
+func bootstrap(autoMigrate bool) (*app.App, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	a, err := app.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// 多个实例同时启动也只会执行一次
+	if autoMigrate && cfg.GetBool("mysql.auto_migrate") {
+		if err = autoMigrateDB(a); err != nil {
+			a.Close()
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// autoMigrateDB 执行还没执行的迁移
+func autoMigrateDB(a *app.App) error {
+	m, err := dao.NewMigrator(a.DB)
+	if err != nil {
+		return fmt.Errorf("load migrations failed: %w", err)
+	}
+	done, err := m.Up(context.Background(), 0)
+	if err != nil {
+		return fmt.Errorf("auto migrate failed: %w", err)
+	}
+	for _, mg := range done {
+		a.Logger.Info("migration applied", zap.Int64("version", mg.Version), zap.String("name", mg.Name))
+	}
+	n, err := wrapLegacyPasswords(context.Background(), a.DB)
+	if err != nil {
+		return fmt.Errorf("wrap legacy passwords failed: %w", err)
+	}
+	if n > 0 {
+		a.Logger.Info("legacy passwords wrapped", zap.Int("count", n))
+	}
+	return nil
+}
+
+// wrapLegacyPasswords 表结构迁移之后的数据迁移：老的 MD5 密码包一层 argon2id
+// 要在 password 列加宽 (0002) 之后执行，所以只在迁移到最新版本后调用；可以重复执行
+func wrapLegacyPasswords(ctx context.Context, db *gorm.DB) (int, error) {
+	return logic.WrapLegacyPasswords(ctx, dao.GormUserRepository{DB: db})
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"gin-api-scaffold-v1/app"
+)
+
+const configUsage = `用法: ./main [--config FILE] config validate [--connect]
+
+  validate    检查配置文件：必填项、雪花算法、JWT 密钥、发信 / 存储 / 搜索配置
+              --connect 同时尝试连接 MySQL 和 Redis
+`
+
+// runConfig 执行 config 子命令，目前只有 validate
+// 部署前跑一遍，配置写错时在上线前发现，而不是等服务启动失败
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+	fs := newFlagSet("config validate")
+	fs.Usage = func() { fmt.Fprint(fs.Output(), configUsage) }
+	connect := fs.Bool("connect", false, "同时尝试连接 MySQL 和 Redis")
+	if err := fs.Parse(args[1:]); err != nil {
+		return usageExitCode(err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	errs := validateConfig(cfg)
+	if *connect && len(errs) == 0 {
+		// 完整地创建一次应用实例，会真正连接 MySQL 和 Redis
+		a, err := app.New(cfg)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			a.Close()
+		}
+	}
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		}
+		return 1
+	}
+	fmt.Printf("✓ %s is valid\n", cfg.ConfigFileUsed())
+	return 0
+}
+
+// validateConfig 检查已经加载的配置，返回发现的所有问题 (不是遇到第一个就停)
+func validateConfig(cfg *viper.Viper) (errs []error) {
+	if port, err := strconv.Atoi(cfg.GetString("app.port")); err != nil || port <= 0 || port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid app.port %q", cfg.GetString("app.port")))
+	}
+	if cfg.IsSet("app.start_time") {
+		if st, err := time.Parse("2006-01-02", cfg.GetString("app.start_time")); err != nil {
+			errs = append(errs, fmt.Errorf("invalid app.start_time %q, want YYYY-MM-DD", cfg.GetString("app.start_time")))
+		} else if st.After(time.Now()) {
+			errs = append(errs, fmt.Errorf("app.start_time %q is in the future", cfg.GetString("app.start_time")))
+		}
+	}
+	// 雪花算法的机器 ID 只有 10 位
+	if cfg.IsSet("app.machine_id") {
+		if id, err := strconv.ParseInt(cfg.GetString("app.machine_id"), 10, 64); err != nil || id < 0 || id > 1023 {
+			errs = append(errs, fmt.Errorf("invalid app.machine_id %q, want 0-1023", cfg.GetString("app.machine_id")))
+		}
+	}
+	for _, key := range []string{"mysql.host", "mysql.user", "mysql.dbname", "redis.host"} {
+		if cfg.GetString(key) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+	// 配置了 jwt_keys 时由 jwt.NewManager 检查密钥，否则必须有 HS256 用的 jwt_secret
+	if !cfg.IsSet("auth.jwt_keys") && cfg.GetString("auth.jwt_secret") == "" {
+		errs = append(errs, errors.New("auth.jwt_secret is required when auth.jwt_keys is not set"))
+	}
+	if cfg.GetInt("auth.access_expire") <= 0 && cfg.GetInt("auth.jwt_expire") <= 0 {
+		errs = append(errs, errors.New("auth.access_expire (or the legacy auth.jwt_expire) must be positive"))
+	}
+	if cfg.GetInt("auth.refresh_expire") <= 0 {
+		errs = append(errs, errors.New("auth.refresh_expire must be positive"))
+	}
+	for _, origin := range cfg.GetStringSlice("cors.allowed_origins") {
+		if !validOrigin(origin) {
+			errs = append(errs, fmt.Errorf("invalid cors.allowed_origins entry %q, want scheme://host[:port] or \"*\"", origin))
+		}
+	}
+	// 雪花算法、JWT 密钥、发信器、文件存储、搜索的初始化只读配置和本地文件，不连数据库直接跑一遍
+	a, err := app.New(cfg, app.WithDB(nil), app.WithRedis(nil))
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		a.Close()
+	}
+	return errs
+}
+
+// validOrigin 浏览器发来的 Origin 形如 https://example.com:8443，没有路径和结尾的斜杠，写错了永远匹配不上
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	return u.Scheme+"://"+u.Host == origin
+}
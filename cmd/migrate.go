@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/pkg/migrate"
+)
+
+// migrationsDir migrate create 生成文件的位置，只在开发时 (项目根目录下) 使用
+const migrationsDir = "migrations"
+
+const migrateUsage = `用法: ./main [--config FILE] migrate <命令> [参数]
+
+  up [N]           执行还没执行的迁移，默认全部；全部执行完后把老的 MD5 密码包一层 argon2id
+  down [N]         回滚最近执行的 N 个迁移，默认 1 个
+  status           查看每个迁移的执行状态
+  force VERSION    不执行 SQL，直接把数据库标记为 VERSION 版本 (修复 dirty 状态 / 接管以前手动建表的数据库)
+  create NAME      在 migrations 目录下新建一对迁移文件
+`
+
+// runMigrate 执行 migrate 子命令，返回进程退出码
+func runMigrate(args []string) int {
+	fs := newFlagSet("migrate")
+	fs.Usage = func() { fmt.Fprint(fs.Output(), migrateUsage) }
+	if err := fs.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	cmd, args := args[0], args[1:]
+
+	// create 只生成文件，不需要连数据库
+	if cmd == "create" {
+		if len(args) != 1 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+		up, down, err := migrate.Create(migrationsDir, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "create migration failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("created %s\ncreated %s\n", up, down)
+		return 0
+	}
+
+	n := 0
+	if cmd == "up" || cmd == "down" || cmd == "force" {
+		if len(args) > 1 || (cmd == "force" && len(args) != 1) {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+		if len(args) == 1 {
+			v, err := strconv.Atoi(args[0])
+			if err != nil || v < 0 {
+				fmt.Fprintf(os.Stderr, "invalid number %q\n", args[0])
+				return 2
+			}
+			n = v
+		}
+	}
+
+	// 只需要配置和数据库，不初始化 Redis 等其他组件
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	db, err := dao.OpenMySQL(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init mysql failed: %v\n", err)
+		return 1
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	m, err := dao.NewMigrator(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load migrations failed: %v\n", err)
+		return 1
+	}
+	ctx := context.Background()
+
+	switch cmd {
+	case "up":
+		done, err := m.Up(ctx, n)
+		printMigrations("applied", done)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate up failed: %v\n", err)
+			return 1
+		}
+		if len(done) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		// 迁移到最新版本后顺带处理老的 MD5 密码
+		if n == 0 {
+			wrapped, err := wrapLegacyPasswords(ctx, db)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "wrap legacy passwords failed: %v\n", err)
+				return 1
+			}
+			if wrapped > 0 {
+				fmt.Printf("wrapped %d legacy md5 passwords with argon2id\n", wrapped)
+			}
+		}
+	case "down":
+		done, err := m.Down(ctx, n)
+		printMigrations("reverted", done)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate down failed: %v\n", err)
+			return 1
+		}
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate status failed: %v\n", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range list {
+			status, appliedAt := "pending", ""
+			if s.Applied {
+				status, appliedAt = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Dirty {
+				status = "dirty"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		w.Flush()
+	case "force":
+		if err = m.Force(ctx, int64(n)); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate force failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("forced to version %d\n", n)
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
+
+func printMigrations(action string, list []migrate.Migration) {
+	for _, mg := range list {
+		fmt.Printf("%s %04d_%s\n", action, mg.Version, mg.Name)
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// configPath 命令行 --config 指定的配置文件，为空时读取当前目录下的 config.yaml
+var configPath string
+
+// command 一个子命令
+type command struct {
+	name    string
+	summary string // 出现在总的帮助信息里的一行说明
+	run     func(args []string) int
+}
+
+// commands 所有子命令，按帮助信息里的顺序排列
+var commands = []command{
+	{"serve", "启动 HTTP 服务 (不带子命令时默认执行)", runServe},
+	{"migrate", "执行 / 回滚 / 查看数据库迁移", runMigrate},
+	{"seed", "写入本地开发用的演示数据 (用户、社区、帖子)", runSeed},
+	{"create-admin", "创建管理员账号，用户已存在时直接授予 admin 角色", runCreateAdmin},
+	{"gen-token", "为指定用户 ID 签发 Access Token，方便调试接口", runGenToken},
+	{"config", "检查配置文件 (config validate)", runConfig},
+	{"routes", "列出所有注册的路由 (routes list)", runRoutes},
+}
+
+// Execute 解析命令行参数并执行对应的子命令，返回进程退出码
+// 用法: ./main [--config FILE] <子命令> [参数]
+func Execute(args []string) int {
+	fs := newFlagSet("main")
+	fs.Usage = func() { printUsage(fs.Output()) }
+	if err := fs.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+	args = fs.Args()
+
+	// 不带子命令时启动服务，兼容以前直接 ./main 的部署方式 (docker-compose)
+	if len(args) == 0 {
+		return runServe(nil)
+	}
+	if args[0] == "help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	printUsage(os.Stderr)
+	return 2
+}
+
+// newFlagSet 创建子命令的参数解析器，每个子命令都认 --config，写在子命令前后都可以
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&configPath, "config", configPath, "配置文件路径 (默认 ./config.yaml)")
+	return fs
+}
+
+// usageExitCode -h / --help 正常退出，其他参数错误按用法错误退出
+// 错误信息和用法已经由 flag 包打印过了
+func usageExitCode(err error) int {
+	if err == flag.ErrHelp {
+		return 0
+	}
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	var b strings.Builder
+	b.WriteString("用法: ./main [--config FILE] <命令> [参数]\n\n命令:\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "  %-14s %s\n", c.name, c.summary)
+	}
+	b.WriteString("\n查看某个命令的参数: ./main <命令> -h\n")
+	fmt.Fprint(w, b.String())
+}
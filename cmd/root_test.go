@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validConfig = `
+app:
+  port: 8080
+mysql:
+  user: "root"
+  host: "127.0.0.1"
+  dbname: "gin_project"
+redis:
+  host: "127.0.0.1"
+auth:
+  jwt_secret: "test-secret"
+  access_expire: 15
+  refresh_expire: 168
+storage:
+  local:
+    secret: "test-secret"
+`
+
+func writeConfig(t *testing.T, content string) string {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+	t.Cleanup(func() { configPath = "" })
+	return filename
+}
+
+func TestExecuteUsage(t *testing.T) {
+	assert.Equal(t, 2, Execute([]string{"unknown"}))
+	assert.Equal(t, 0, Execute([]string{"help"}))
+	assert.Equal(t, 0, Execute([]string{"gen-token", "-h"}))
+	assert.Equal(t, 2, Execute([]string{"gen-token"}))
+	assert.Equal(t, 2, Execute([]string{"gen-token", "abc"}))
+	assert.Equal(t, 2, Execute([]string{"config"}))
+	assert.Equal(t, 2, Execute([]string{"routes"}))
+}
+
+func TestConfigValidate(t *testing.T) {
+	filename := writeConfig(t, validConfig)
+	assert.Equal(t, 0, Execute([]string{"--config", filename, "config", "validate"}))
+	// --config 写在子命令后面也可以
+	assert.Equal(t, 0, Execute([]string{"config", "validate", "--config", filename}))
+
+	filename = writeConfig(t, "app:\n  port: 0\nmail:\n  driver: \"pigeon\"\n")
+	assert.Equal(t, 1, Execute([]string{"--config", filename, "config", "validate"}))
+
+	for _, snowflake := range []string{"  machine_id: 1024\n", "  machine_id: -1\n", "  start_time: \"2026/01/01\"\n", "  start_time: \"2999-01-01\"\n"} {
+		filename = writeConfig(t, strings.Replace(validConfig, "app:\n", "app:\n"+snowflake, 1))
+		assert.Equal(t, 1, Execute([]string{"--config", filename, "config", "validate"}), snowflake)
+	}
+	filename = writeConfig(t, strings.Replace(validConfig, "app:\n", "app:\n  start_time: \"2025-06-01\"\n  machine_id: 0\n", 1))
+	assert.Equal(t, 0, Execute([]string{"--config", filename, "config", "validate"}))
+
+	filename = writeConfig(t, validConfig+"cors:\n  allowed_origins: [\"https://example.com/\"]\n")
+	assert.Equal(t, 1, Execute([]string{"--config", filename, "config", "validate"}), "Origin 不能带路径")
+
+	assert.Equal(t, 1, Execute([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml"), "config", "validate"}))
+}
+
+func TestRoutesList(t *testing.T) {
+	filename := writeConfig(t, validConfig)
+	assert.Equal(t, 0, Execute([]string{"--config", filename, "routes", "list"}))
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-api-scaffold-v1/app"
+	"gin-api-scaffold-v1/routers"
+)
+
+// runRoutes 列出 routers.SetupRouter 注册的所有路由 (routes list)
+// 注册路由不需要连数据库，只加载配置创建一个不连 MySQL / Redis 的应用实例 (限流等中间件的开关读配置)
+func runRoutes(args []string) int {
+	if len(args) == 0 || args[0] != "list" {
+		fmt.Fprintln(os.Stderr, "用法: ./main [--config FILE] routes list")
+		return 2
+	}
+	fs := newFlagSet("routes list")
+	if err := fs.Parse(args[1:]); err != nil {
+		return usageExitCode(err)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	a, err := app.New(cfg, app.WithDB(nil), app.WithRedis(nil))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer a.Close()
+
+	// 关掉 gin 调试模式下注册每条路由时打印的日志，只输出下面的表格
+	gin.SetMode(gin.ReleaseMode)
+	r := routers.SetupRouter(a)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER")
+	for _, route := range r.Routes() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", route.Method, route.Path, route.Handler)
+	}
+	w.Flush()
+	return 0
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/routers"
+)
+
+// runServe 启动 HTTP 服务，收到 SIGINT / SIGTERM 后优雅关机
+func runServe(args []string) int {
+	fs := newFlagSet("serve")
+	port := fs.String("port", "", "监听端口，默认读取 app.port")
+	if err := fs.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+
+	// 配置、日志、MySQL (mysql.auto_migrate 时自动迁移)、Redis 等全部初始化
+	// 连不上数据库时后端服务没有意义，直接退出
+	a, err := bootstrap(true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	// 退出前关闭数据库连接，并把内存里缓存的日志强制刷入硬盘，防止最后几条关键日志丢失
+	defer a.Close()
+	log := a.Logger
+	log.Debug("logger init success...")
+
+	// =========================================================================
+	// 启动后台任务
+	// =========================================================================
+	// 定时把投票期已经结束的帖子的投票记录从 Redis 归档到 MySQL，关机时通过 cancel 停掉
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go a.Service.RunVoteArchiver(jobCtx)
+
+	// =========================================================================
+	// 注册路由 (Gin) 并启动服务 (HTTP Server)
+	// =========================================================================
+	r := routers.SetupRouter(a)
+
+	if *port == "" {
+		*port = a.Config.GetString("app.port") // 从配置文件读取端口
+	}
+
+	// 手动创建一个 http.Server，而不是直接用 r.Run()
+	// 原因：r.Run() 内部也是创建 server，但它不方便我们在外部调用 Shutdown 做优雅关机。
+	srv := &http.Server{
+		Addr:    ":" + *port,
+		Handler: r,
+	}
+
+	// srv.ListenAndServe() 会一直阻塞等待请求，放在协程里，主线程才能继续往下等待关机信号
+	go func() {
+		fmt.Printf("服务正在启动，端口: %s\n", *port)
+		log.Info("Server is starting...", zap.String("port", *port))
+
+		// 如果返回错误，且错误不是“服务器已关闭(http.ErrServerClosed)”，说明启动失败（比如端口被占用）。
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("listen: ", zap.Error(err))
+		}
+	}()
+
+	// =========================================================================
+	// 优雅关机 (Graceful Shutdown)
+	// =========================================================================
+	// 收到 SIGINT (Ctrl+C) 或者 SIGTERM (Docker 停止容器/K8s 销毁 Pod) 时不直接退出，
+	// 先把手里的请求处理完
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Info("Shutdown Server ...")
+	stopJobs()
+
+	// 给服务器 5 秒钟的时间去处理还没处理完的请求，超时就强制关闭
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// srv.Shutdown 马上停止接收新的请求，并等待正在处理的请求处理完（或者直到 ctx 超时）
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error("Server Shutdown:", zap.Error(err))
+		return 1
+	}
+
+	log.Info("Server exiting")
+	return 0
+}
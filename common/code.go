@@ -13,18 +13,70 @@ const (
 
 	CodeNeedLogin
 	CodeInvalidToken
+	CodeTokenReused
+	CodeAccountLocked
+	CodeForbidden
+	CodeInvalidMFACode
+	CodeMFANotEnabled
+	CodeEmailExist
+	CodeInvalidEmailToken
+	CodeEmailAlreadyVerified
+	CodeRoleNotExist
+	CodeAPIKeyNotExist
+	CodeAPIKeyLimit
+	CodeInvalidCSRFToken
+	CodeOIDCProviderNotExist
+	CodeOIDCLoginFailed
+	CodeSessionNotExist
+	CodeFileTooLarge
+	CodeFileTypeNotAllowed
+	CodeFileNotExist
+	CodeCommunityExist
+	CodeCommunityNotExist
+	CodeNotCommunityMember
+	CodeCommunityOwnerLeave
+	CodePostNotExist
+	CodeVoteTimeExpired
+	CodeCommentNotExist
+	CodeRequestTimeout
 )
 
 // codeMsgMap 状态码映射
 var codeMsgMap = map[ResCode]string{
-	CodeSuccess:         "success",
-	CodeInvalidParam:    "请求参数错误",
-	CodeUserExist:       "用户名已存在",
-	CodeUserNotExist:    "用户不存在",
-	CodeInvalidPassword: "用户名或密码错误",
-	CodeServerBusy:      "服务繁忙",
-	CodeNeedLogin:       "需要登录",
-	CodeInvalidToken:    "无效的Token",
+	CodeSuccess:              "success",
+	CodeInvalidParam:         "请求参数错误",
+	CodeUserExist:            "用户名已存在",
+	CodeUserNotExist:         "用户不存在",
+	CodeInvalidPassword:      "用户名或密码错误",
+	CodeServerBusy:           "服务繁忙",
+	CodeNeedLogin:            "需要登录",
+	CodeInvalidToken:         "无效的Token",
+	CodeTokenReused:          "登录状态异常，请重新登录",
+	CodeAccountLocked:        "登录失败次数过多，账号已被临时锁定",
+	CodeForbidden:            "没有权限",
+	CodeInvalidMFACode:       "验证码错误",
+	CodeMFANotEnabled:        "未开启两步验证",
+	CodeEmailExist:           "邮箱已被注册",
+	CodeInvalidEmailToken:    "链接无效或已过期",
+	CodeEmailAlreadyVerified: "邮箱已验证",
+	CodeRoleNotExist:         "角色不存在",
+	CodeAPIKeyNotExist:       "API Key 不存在",
+	CodeAPIKeyLimit:          "API Key 数量已达上限",
+	CodeInvalidCSRFToken:     "CSRF Token 校验失败",
+	CodeOIDCProviderNotExist: "不支持的登录方式",
+	CodeOIDCLoginFailed:      "第三方登录失败",
+	CodeSessionNotExist:      "会话不存在",
+	CodeFileTooLarge:         "文件太大",
+	CodeFileTypeNotAllowed:   "不支持的文件类型",
+	CodeFileNotExist:         "文件不存在",
+	CodeCommunityExist:       "社区已存在",
+	CodeCommunityNotExist:    "社区不存在",
+	CodeNotCommunityMember:   "不是社区成员",
+	CodeCommunityOwnerLeave:  "创建者不能退出社区",
+	CodePostNotExist:         "帖子不存在",
+	CodeVoteTimeExpired:      "投票时间已过",
+	CodeCommentNotExist:      "评论不存在",
+	CodeRequestTimeout:       "请求超时，请稍后再试",
 }
 
 // Msg 方法：获取状态码对应的提示信息
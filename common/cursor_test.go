@@ -0,0 +1,49 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCursor 游标编码后能原样解出来，改动任何一个字符都校验不过
+func TestCursor(t *testing.T) {
+	cc := NewCursorCodec("", "test_secret")
+	c := Cursor{Key: "2026-03-01 12:00:00", ID: 1234567890123}
+	s := cc.Encode(c)
+
+	got, err := cc.Decode(s)
+	assert.NoError(t, err)
+	assert.Equal(t, &c, got)
+
+	// 第一页不传游标
+	got, err = cc.Decode("")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	// 篡改内容 / 签名 / 格式
+	forged := cc.Encode(Cursor{ID: 1})
+	_, err = cc.Decode(s[:len(s)-22] + forged[len(forged)-22:])
+	assert.ErrorIs(t, err, ErrorInvalidCursor)
+	_, err = cc.Decode(s + "x")
+	assert.ErrorIs(t, err, ErrorInvalidCursor)
+	_, err = cc.Decode("1234567890123")
+	assert.ErrorIs(t, err, ErrorInvalidCursor)
+}
+
+// TestNewCursorPage 多取的那一条决定有没有下一页，游标指向这一页的最后一条
+func TestNewCursorPage(t *testing.T) {
+	cc := NewCursorCodec("cursor_secret", "")
+	cursorOf := func(id int64) Cursor { return Cursor{ID: id} }
+
+	items, page := NewCursorPage(cc, []int64{1, 2, 3}, 2, cursorOf)
+	assert.Equal(t, []int64{1, 2}, items)
+	assert.True(t, page.HasMore)
+	next, err := cc.Decode(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), next.ID)
+
+	items, page = NewCursorPage(cc, []int64{1, 2}, 2, cursorOf)
+	assert.Equal(t, []int64{1, 2}, items)
+	assert.Equal(t, CursorPage{}, page)
+}
@@ -1,15 +1,21 @@
 package common
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 
 	// 引入你的 validator 包
 	myValidator "gin-api-scaffold-v1/pkg/validator"
 )
 
+// ContextTranslatorKey gin.Context 里保存校验错误翻译器的 key (由 middleware.Translator 设置)
+const ContextTranslatorKey = "translator"
+
 // Response 定义标准 JSON 结构
 type Response struct {
 	Code ResCode     `json:"code"` // 引用 code.go 里的 ResCode
@@ -31,6 +37,12 @@ func Error(c *gin.Context, code ResCode, err error) {
 	var response Response
 	response.Code = code
 
+	// 请求超过了 middleware.Timeout 设置的期限，查询是被取消掉的，不是服务出错
+	if code == CodeServerBusy && timedOut(c, err) {
+		code = CodeRequestTimeout
+		response.Code = code
+	}
+
 	if err == nil {
 		response.Msg = code.Msg()
 		response.Data = nil
@@ -43,8 +55,7 @@ func Error(c *gin.Context, code ResCode, err error) {
 	if ok {
 		response.Code = CodeInvalidParam
 		response.Msg = CodeInvalidParam.Msg()
-		translations := errs.Translate(myValidator.Trans)
-		response.Data = myValidator.RemoveTopStruct(translations)
+		response.Data = myValidator.RemoveTopStruct(translate(c, errs))
 	} else {
 		// 普通错误
 		response.Msg = code.Msg()
@@ -63,3 +74,23 @@ func ErrorWithMsg(c *gin.Context, code ResCode, msg string) {
 		Data: nil,
 	})
 }
+
+// timedOut 错误是因为请求超时 (查询被取消或者 Redis / MySQL 读超时)
+func timedOut(c *gin.Context, err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return c.Request != nil && errors.Is(c.Request.Context().Err(), context.DeadlineExceeded)
+}
+
+// translate 用请求上下文里的翻译器翻译校验错误，没有翻译器时直接用英文原文
+func translate(c *gin.Context, errs validator.ValidationErrors) map[string]string {
+	if trans, ok := c.Value(ContextTranslatorKey).(ut.Translator); ok {
+		return errs.Translate(trans)
+	}
+	res := make(map[string]string, len(errs))
+	for _, fe := range errs {
+		res[fe.Namespace()] = fe.Error()
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+)
+
+// CreateAPIKeyHandler 创建 API Key
+// @Summary      创建 API Key
+// @Description  创建一个个人 API Key，明文只在这次返回，之后无法再查看。调用接口时放在 Authorization: ApiKey <key> 或 X-API-Key 头里
+// @Tags         API Key
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamCreateAPIKey  true  "API Key 参数"
+// @Success      200  {object} common.Response{data=models.ResCreateAPIKey} "创建成功"
+// @Router       /api-keys [post]
+func (h *Handler) CreateAPIKeyHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	var p models.ParamCreateAPIKey
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.CreateAPIKey(c.Request.Context(), userID, &p)
+	if err != nil {
+		h.log.Error("logic.CreateAPIKey failed", zap.Int64("user_id", userID), zap.Error(err))
+		if errors.Is(err, logic.ErrorAPIKeyLimit) {
+			common.Error(c, common.CodeAPIKeyLimit, err)
+			return
+		}
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// ListAPIKeysHandler 列出 API Key
+// @Summary      API Key 列表
+// @Description  列出当前用户还没吊销的 API Key (不含明文)
+// @Tags         API Key
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Success      200  {object} common.Response{data=[]models.ResAPIKey} "API Key 列表"
+// @Router       /api-keys [get]
+func (h *Handler) ListAPIKeysHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	data, err := h.svc.ListAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		h.log.Error("logic.ListAPIKeys failed", zap.Int64("user_id", userID), zap.Error(err))
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// RevokeAPIKeyHandler 吊销 API Key
+// @Summary      吊销 API Key
+// @Description  吊销指定的 API Key，立即生效
+// @Tags         API Key
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "API Key ID"
+// @Success      200  {object} common.Response "吊销成功"
+// @Router       /api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKeyHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err = h.svc.RevokeAPIKey(c.Request.Context(), userID, keyID); err != nil {
+		h.log.Error("logic.RevokeAPIKey failed", zap.Int64("user_id", userID), zap.Int64("key_id", keyID), zap.Error(err))
+		if errors.Is(err, dao.ErrorAPIKeyNotFound) {
+			common.Error(c, common.CodeAPIKeyNotExist, err)
+			return
+		}
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	common.Success(c, nil)
+}
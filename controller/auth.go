@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/session"
+)
+
+// RefreshTokenHandler 用 Refresh Token 换取新的 Token 对
+// @Summary      刷新 Token
+// @Description  使用 Refresh Token 换取新的 Access Token 和 Refresh Token，旧的 Refresh Token 立即作废。Cookie 登录模式下可以不传请求体
+// @Tags         认证相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Param        object body  models.ParamRefreshToken  true  "刷新参数"
+// @Success      200  {object} common.Response{data=models.ResToken} "刷新成功"
+// @Router       /auth/refresh [post]
+func (h *Handler) RefreshTokenHandler(c *gin.Context) {
+	// 1. 获取参数 (Cookie 登录模式下 Refresh Token 在 Cookie 里，请求体可以为空)
+	var p models.ParamRefreshToken
+	if err := c.ShouldBindJSON(&p); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Error("RefreshToken with invalid param", zap.Error(err))
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if p.RefreshToken == "" {
+		p.RefreshToken = h.cookies.RefreshToken(c)
+	}
+	if p.RefreshToken == "" {
+		common.Error(c, common.CodeInvalidParam, nil)
+		return
+	}
+
+	// 2. 业务处理
+	token, err := h.svc.RefreshToken(c.Request.Context(), &p, clientInfo(c))
+	if err != nil {
+		h.log.Error("logic.RefreshToken failed", zap.Error(err))
+		switch {
+		case errors.Is(err, dao.ErrorRefreshTokenReused):
+			common.Error(c, common.CodeTokenReused, err)
+		case errors.Is(err, logic.ErrorInvalidRefreshToken),
+			errors.Is(err, logic.ErrorTokenRevoked),
+			errors.Is(err, dao.ErrorRefreshTokenNotFound):
+			common.Error(c, common.CodeInvalidToken, err)
+		default:
+			common.Error(c, common.CodeServerBusy, err)
+		}
+		return
+	}
+
+	// 3. 返回响应
+	h.respondToken(c, token)
+}
+
+// respondToken 返回登录 / 刷新结果
+// 开启 Cookie 登录模式时把 Token 写进 HttpOnly Cookie，cookie 模式下响应体里不再返回 Token
+func (h *Handler) respondToken(c *gin.Context, token *models.ResToken) {
+	if h.cookies.Enabled() && token.AccessToken != "" {
+		if err := h.cookies.SetTokens(c, token.AccessToken, h.jwt.AccessExpire(), token.RefreshToken, h.jwt.RefreshExpire()); err != nil {
+			h.log.Error("session.SetTokens failed", zap.Error(err))
+			common.Error(c, common.CodeServerBusy, err)
+			return
+		}
+		if h.cookies.Mode() == session.ModeCookie {
+			token.Token, token.AccessToken, token.RefreshToken = "", "", ""
+		}
+	}
+	common.Success(c, token)
+}
+
+// handleLoginLocked 登录被锁定时返回 CodeAccountLocked，并通过 Retry-After 告诉客户端多久之后可以再试
+// 返回 true 表示已经写了响应
+func handleLoginLocked(c *gin.Context, err error) bool {
+	var locked *logic.LoginLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	c.Header("Retry-After", strconv.FormatInt(locked.RetryAfterSeconds(), 10))
+	common.ErrorWithMsg(c, common.CodeAccountLocked, locked.Error())
+	return true
+}
+
+// LogoutHandler 退出当前设备
+// @Summary      退出登录
+// @Description  注销当前 Access Token，并吊销同一次登录签发的 Refresh Token
+// @Tags         认证相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Success      200  {object} common.Response "退出成功"
+// @Router       /logout [post]
+func (h *Handler) LogoutHandler(c *gin.Context) {
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	if err := h.svc.Logout(c.Request.Context(), mc); err != nil {
+		h.log.Error("logic.Logout failed", zap.Int64("user_id", mc.UserID), zap.Error(err))
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	if h.cookies.Enabled() {
+		h.cookies.Clear(c)
+	}
+	common.Success(c, nil)
+}
+
+// LogoutAllHandler 退出所有设备
+// @Summary      退出所有设备
+// @Description  让当前用户之前签发的所有 Access Token 和 Refresh Token 立即失效
+// @Tags         认证相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Success      200  {object} common.Response "退出成功"
+// @Router       /logout-all [post]
+func (h *Handler) LogoutAllHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	if err := h.svc.LogoutAll(c.Request.Context(), userID); err != nil {
+		h.log.Error("logic.LogoutAll failed", zap.Int64("user_id", userID), zap.Error(err))
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	if h.cookies.Enabled() {
+		h.cookies.Clear(c)
+	}
+	common.Success(c, nil)
+}
+
+// JWKSHandler 公开当前可用于验签的公钥 (JWKS)
+// @Summary      JWKS 公钥集合
+// @Description  返回 RS256 / EdDSA 验签公钥 (RFC 7517)，HS256 密钥不会公开。注意该接口不在 /api/v1 下，也不使用统一响应结构
+// @Tags         认证相关接口
+// @Produce      application/json
+// @Success      200  {object} jwt.JSONWebKeySet "公钥集合"
+// @Router       /.well-known/jwks.json [get]
+func (h *Handler) JWKSHandler(c *gin.Context) {
+	// 允许其他服务缓存一会儿，轮换密钥时新公钥要提前发布，所以缓存时间不宜太长
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwt.JWKS())
+}
+
+// UnlockLoginHandler 管理员解除登录锁定
+// @Summary      解除登录锁定
+// @Description  清除指定用户名 (以及可选 IP) 的登录失败计数和锁定状态，仅管理员可用
+// @Tags         管理接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamUnlockLogin  true  "解锁参数"
+// @Success      200  {object} common.Response "解锁成功"
+// @Router       /admin/login/unlock [post]
+func (h *Handler) UnlockLoginHandler(c *gin.Context) {
+	var p models.ParamUnlockLogin
+	if err := c.ShouldBindJSON(&p); err != nil {
+		h.log.Error("UnlockLogin with invalid param", zap.Error(err))
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err := h.svc.UnlockLogin(c.Request.Context(), p.Username, p.IP); err != nil {
+		h.log.Error("logic.UnlockLogin failed", zap.String("username", p.Username), zap.Error(err))
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	common.Success(c, nil)
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+)
+
+// CreateCommentHandler 发表评论
+// @Summary      发表评论
+// @Description  评论帖子，传 parent_id 表示回复某条评论
+// @Tags         评论相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "帖子 ID"
+// @Param        object body  models.ParamCreateComment  true  "评论参数"
+// @Success      200  {object} common.Response{data=models.ResComment} "发表成功"
+// @Router       /posts/{id}/comments [post]
+func (h *Handler) CreateCommentHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	postID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	var p models.ParamCreateComment
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.CreateComment(c.Request.Context(), userID, postID, &p)
+	if err != nil {
+		h.log.Error("logic.CreateComment failed", zap.Int64("user_id", userID), zap.Int64("post_id", postID), zap.Error(err))
+		handleCommentError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// ListCommentsHandler 帖子的评论树
+// @Summary      帖子的评论树
+// @Description  一级评论按发表时间正序、游标分页 (has_more 为 true 时把 next_cursor 传回来取下一页)，每条一级评论下面展开 depth - 1 层回复；已删除的评论显示为 [deleted]
+// @Tags         评论相关接口
+// @Produce      application/json
+// @Param        id path  string  true  "帖子 ID"
+// @Param        cursor query string false "上一页返回的 next_cursor，第一页不传"
+// @Param        size query int false "每页一级评论数量，默认 20，最多 100"
+// @Param        depth query int false "展开几层，默认 3，最多 10"
+// @Success      200  {object} common.Response{data=models.ResCommentTree} "评论树"
+// @Router       /posts/{id}/comments [get]
+func (h *Handler) ListCommentsHandler(c *gin.Context) {
+	postID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	var p models.ParamCommentTree
+	if err = c.ShouldBindQuery(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.GetCommentTree(c.Request.Context(), postID, &p)
+	if err != nil {
+		h.log.Error("logic.GetCommentTree failed", zap.Int64("post_id", postID), zap.Error(err))
+		handleCommentError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// ListCommentRepliesHandler 某条评论的回复
+// @Summary      某条评论的回复
+// @Description  评论树里每条评论只展开前几条回复，reply_count 比展开的多时用这个接口分页加载；直接回复按发表时间正序、游标分页，每条回复下面展开 depth - 1 层
+// @Tags         评论相关接口
+// @Produce      application/json
+// @Param        id path  string  true  "评论 ID"
+// @Param        cursor query string false "上一页返回的 next_cursor，第一页不传"
+// @Param        size query int false "每页回复数量，默认 20，最多 100"
+// @Param        depth query int false "展开几层，默认 3，最多 10"
+// @Success      200  {object} common.Response{data=models.ResCommentReplies} "回复列表"
+// @Router       /comments/{id}/replies [get]
+func (h *Handler) ListCommentRepliesHandler(c *gin.Context) {
+	commentID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	var p models.ParamCommentTree
+	if err = c.ShouldBindQuery(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.GetCommentReplies(c.Request.Context(), commentID, &p)
+	if err != nil {
+		h.log.Error("logic.GetCommentReplies failed", zap.Int64("comment_id", commentID), zap.Error(err))
+		handleCommentError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// DeleteCommentHandler 删除评论
+// @Summary      删除评论
+// @Description  作者本人、社区创建者 / 版主、拥有 comment:delete 权限的人可以删除；回复会保留
+// @Tags         评论相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "评论 ID"
+// @Success      200  {object} common.Response "删除成功"
+// @Router       /comments/{id} [delete]
+func (h *Handler) DeleteCommentHandler(c *gin.Context) {
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	commentID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err = h.svc.DeleteComment(c.Request.Context(), mc, commentID); err != nil {
+		h.log.Error("logic.DeleteComment failed", zap.Int64("user_id", mc.UserID), zap.Int64("comment_id", commentID), zap.Error(err))
+		handleCommentError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// handleCommentError 评论相关错误统一转成响应码
+func handleCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, dao.ErrorCommentNotFound):
+		common.Error(c, common.CodeCommentNotExist, err)
+	case errors.Is(err, logic.ErrorCommentForbidden):
+		common.Error(c, common.CodeForbidden, err)
+	case errors.Is(err, common.ErrorInvalidCursor):
+		common.Error(c, common.CodeInvalidParam, err)
+	default:
+		handlePostError(c, err)
+	}
+}
@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+)
+
+// CreateCommunityHandler 创建社区
+// @Summary      创建社区
+// @Description  创建一个社区，创建者自动加入并成为社区的 owner
+// @Tags         社区相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamCreateCommunity  true  "社区参数"
+// @Success      200  {object} common.Response{data=models.ResCommunity} "创建成功"
+// @Router       /communities [post]
+func (h *Handler) CreateCommunityHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	var p models.ParamCreateCommunity
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.CreateCommunity(c.Request.Context(), userID, &p)
+	if err != nil {
+		h.log.Error("logic.CreateCommunity failed", zap.Int64("user_id", userID), zap.Error(err))
+		handleCommunityError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// ListCommunitiesHandler 社区列表
+// @Summary      社区列表
+// @Description  分页列出社区，按创建时间倒序
+// @Tags         社区相关接口
+// @Produce      application/json
+// @Param        page query int false "页码，从 1 开始"
+// @Param        size query int false "每页数量，默认 20，最多 100"
+// @Success      200  {object} common.Response{data=models.ResCommunityList} "社区列表"
+// @Router       /communities [get]
+func (h *Handler) ListCommunitiesHandler(c *gin.Context) {
+	var p models.ParamPage
+	if err := c.ShouldBindQuery(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.ListCommunities(c.Request.Context(), &p)
+	if err != nil {
+		h.log.Error("logic.ListCommunities failed", zap.Error(err))
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// GetCommunityHandler 社区详情
+// @Summary      社区详情
+// @Description  社区信息，以及创建者和版主列表
+// @Tags         社区相关接口
+// @Produce      application/json
+// @Param        id path  string  true  "社区 ID"
+// @Success      200  {object} common.Response{data=models.ResCommunityDetail} "社区详情"
+// @Router       /communities/{id} [get]
+func (h *Handler) GetCommunityHandler(c *gin.Context) {
+	communityID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.GetCommunity(c.Request.Context(), communityID)
+	if err != nil {
+		h.log.Error("logic.GetCommunity failed", zap.Int64("community_id", communityID), zap.Error(err))
+		handleCommunityError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// JoinCommunityHandler 加入社区
+// @Summary      加入社区
+// @Description  加入社区，已经是成员时直接返回成功
+// @Tags         社区相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "社区 ID"
+// @Success      200  {object} common.Response "加入成功"
+// @Router       /communities/{id}/join [post]
+func (h *Handler) JoinCommunityHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	communityID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err = h.svc.JoinCommunity(c.Request.Context(), userID, communityID); err != nil {
+		h.log.Error("logic.JoinCommunity failed", zap.Int64("user_id", userID), zap.Int64("community_id", communityID), zap.Error(err))
+		handleCommunityError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// LeaveCommunityHandler 退出社区
+// @Summary      退出社区
+// @Description  退出社区，本来就不是成员时直接返回成功；创建者不能退出
+// @Tags         社区相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "社区 ID"
+// @Success      200  {object} common.Response "退出成功"
+// @Router       /communities/{id}/leave [post]
+func (h *Handler) LeaveCommunityHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	communityID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err = h.svc.LeaveCommunity(c.Request.Context(), userID, communityID); err != nil {
+		h.log.Error("logic.LeaveCommunity failed", zap.Int64("user_id", userID), zap.Int64("community_id", communityID), zap.Error(err))
+		handleCommunityError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// AddCommunityModeratorHandler 任命版主
+// @Summary      任命版主
+// @Description  把社区成员设为版主，只有社区创建者或者拥有 community:manage 权限的人可以操作
+// @Tags         社区相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "社区 ID"
+// @Param        user_id path  string  true  "用户 ID"
+// @Success      200  {object} common.Response "任命成功"
+// @Router       /communities/{id}/moderators/{user_id} [put]
+func (h *Handler) AddCommunityModeratorHandler(c *gin.Context) {
+	h.setCommunityModerator(c, true)
+}
+
+// RemoveCommunityModeratorHandler 撤销版主
+// @Summary      撤销版主
+// @Description  把版主降为普通成员，只有社区创建者或者拥有 community:manage 权限的人可以操作
+// @Tags         社区相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "社区 ID"
+// @Param        user_id path  string  true  "用户 ID"
+// @Success      200  {object} common.Response "撤销成功"
+// @Router       /communities/{id}/moderators/{user_id} [delete]
+func (h *Handler) RemoveCommunityModeratorHandler(c *gin.Context) {
+	h.setCommunityModerator(c, false)
+}
+
+// setCommunityModerator 任免版主共用的处理逻辑
+func (h *Handler) setCommunityModerator(c *gin.Context, moderator bool) {
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	communityID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	userID, err := paramID(c, "user_id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err = h.svc.SetCommunityModerator(c.Request.Context(), mc, communityID, userID, moderator); err != nil {
+		h.log.Error("logic.SetCommunityModerator failed",
+			zap.Int64("community_id", communityID), zap.Int64("user_id", userID), zap.Bool("moderator", moderator), zap.Error(err))
+		handleCommunityError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// handleCommunityError 社区相关错误统一转成响应码
+func handleCommunityError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, dao.ErrorCommunityExist):
+		common.Error(c, common.CodeCommunityExist, err)
+	case errors.Is(err, dao.ErrorCommunityNotFound):
+		common.Error(c, common.CodeCommunityNotExist, err)
+	case errors.Is(err, dao.ErrorNotCommunityMember):
+		common.Error(c, common.CodeNotCommunityMember, err)
+	case errors.Is(err, logic.ErrorCommunityOwnerLeave):
+		common.Error(c, common.CodeCommunityOwnerLeave, err)
+	case errors.Is(err, logic.ErrorCommunityForbidden):
+		common.Error(c, common.CodeForbidden, err)
+	default:
+		common.Error(c, common.CodeServerBusy, err)
+	}
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+)
+
+// VerifyEmailHandler 验证邮箱
+// @Summary      验证邮箱
+// @Description  提交验证邮件链接里的 token，每个链接只能使用一次
+// @Tags         用户相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Param        object body  models.ParamEmailToken  true  "邮件链接里的 token"
+// @Success      200  {object} common.Response "验证成功"
+// @Router       /verify-email [post]
+func (h *Handler) VerifyEmailHandler(c *gin.Context) {
+	var p models.ParamEmailToken
+	if err := c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err := h.svc.VerifyEmail(c.Request.Context(), p.Token); err != nil {
+		h.log.Error("logic.VerifyEmail failed", zap.Error(err))
+		handleEmailError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// ResendVerifyEmailHandler 重新发送验证邮件
+// @Summary      重新发送验证邮件
+// @Description  给当前用户的邮箱重新发送验证邮件，之前发出的链接随之失效
+// @Tags         用户相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Success      200  {object} common.Response "已发送"
+// @Router       /verify-email/resend [post]
+func (h *Handler) ResendVerifyEmailHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	if err = h.svc.SendVerifyEmail(c.Request.Context(), userID); err != nil {
+		h.log.Error("logic.SendVerifyEmail failed", zap.Int64("user_id", userID), zap.Error(err))
+		handleEmailError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// ForgotPasswordHandler 找回密码
+// @Summary      找回密码
+// @Description  给邮箱发送重置密码链接。不论邮箱是否注册过都返回成功
+// @Tags         用户相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Param        object body  models.ParamForgotPassword  true  "注册邮箱"
+// @Success      200  {object} common.Response "已发送"
+// @Router       /forgot-password [post]
+func (h *Handler) ForgotPasswordHandler(c *gin.Context) {
+	var p models.ParamForgotPassword
+	if err := c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err := h.svc.ForgotPassword(c.Request.Context(), p.Email); err != nil {
+		h.log.Error("logic.ForgotPassword failed", zap.Error(err))
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// ResetPasswordHandler 重置密码
+// @Summary      重置密码
+// @Description  使用重置密码链接里的 token 设置新密码，成功后所有设备都需要重新登录
+// @Tags         用户相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Param        object body  models.ParamResetPassword  true  "重置密码参数"
+// @Success      200  {object} common.Response "重置成功"
+// @Router       /reset-password [post]
+func (h *Handler) ResetPasswordHandler(c *gin.Context) {
+	var p models.ParamResetPassword
+	if err := c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err := h.svc.ResetPassword(c.Request.Context(), &p); err != nil {
+		h.log.Error("logic.ResetPassword failed", zap.Error(err))
+		handleEmailError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// handleEmailError 邮件链接相关错误统一转成响应码
+func handleEmailError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrorInvalidEmailToken):
+		common.Error(c, common.CodeInvalidEmailToken, err)
+	case errors.Is(err, logic.ErrorEmailAlreadyVerified):
+		common.Error(c, common.CodeEmailAlreadyVerified, err)
+	default:
+		common.Error(c, common.CodeServerBusy, err)
+	}
+}
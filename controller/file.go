@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+)
+
+// multipartOverhead 请求体除了文件本身，还有 multipart 的分隔符和表单头
+const multipartOverhead = 64 << 10
+
+// UploadFileHandler 上传文件
+// @Summary      上传文件
+// @Description  multipart/form-data 上传，字段名 file。按文件内容识别类型，同样的内容只存一份，返回限时有效的下载地址
+// @Tags         文件相关接口
+// @Accept       multipart/form-data
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        file formData file true "文件"
+// @Success      200  {object} common.Response{data=models.ResFile} "上传成功"
+// @Router       /files [post]
+func (h *Handler) UploadFileHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	f, err := openFormFile(c, h.svc.MaxUploadSize())
+	if err != nil {
+		handleFormFileError(c, err)
+		return
+	}
+	defer f.Close()
+
+	data, err := h.svc.UploadFile(c.Request.Context(), userID, f)
+	if err != nil {
+		h.log.Error("logic.UploadFile failed", zap.Int64("user_id", userID), zap.Error(err))
+		handleFileError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// UploadAvatarHandler 上传头像
+// @Summary      上传头像
+// @Description  multipart/form-data 上传，字段名 file，只支持 jpeg / png / gif / webp，返回修改后的个人资料
+// @Tags         用户相关接口
+// @Accept       multipart/form-data
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        file formData file true "头像图片"
+// @Success      200  {object} common.Response{data=models.ResProfile} "上传成功"
+// @Router       /me/avatar [post]
+func (h *Handler) UploadAvatarHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	f, err := openFormFile(c, h.svc.MaxAvatarSize())
+	if err != nil {
+		handleFormFileError(c, err)
+		return
+	}
+	defer f.Close()
+
+	data, err := h.svc.UploadAvatar(c.Request.Context(), userID, f)
+	if err != nil {
+		h.log.Error("logic.UploadAvatar failed", zap.Int64("user_id", userID), zap.Error(err))
+		handleFileError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// DeleteAvatarHandler 删除头像
+// @Summary      删除头像
+// @Description  恢复成默认头像，返回修改后的个人资料
+// @Tags         用户相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Success      200  {object} common.Response{data=models.ResProfile} "删除成功"
+// @Router       /me/avatar [delete]
+func (h *Handler) DeleteAvatarHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	data, err := h.svc.DeleteAvatar(c.Request.Context(), userID)
+	if err != nil {
+		h.log.Error("logic.DeleteAvatar failed", zap.Int64("user_id", userID), zap.Error(err))
+		handleFileError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// DownloadFileHandler 下载文件 (本地存储)
+// @Summary      下载文件
+// @Description  上传接口和个人资料里返回的限时下载地址，只在 storage.driver 为 local 时可用
+// @Tags         文件相关接口
+// @Produce      application/octet-stream
+// @Param        key path  string  true  "文件位置"
+// @Param        expires query string true "过期时间 (unix 秒)"
+// @Param        sig query string true "签名"
+// @Success      200  {file} file "文件内容"
+// @Router       /files/{key} [get]
+func (h *Handler) DownloadFileHandler(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	rc, contentType, err := h.svc.OpenFile(c.Request.Context(), key, c.Query("expires"), c.Query("sig"))
+	if err != nil {
+		if !errors.Is(err, dao.ErrorFileNotFound) {
+			h.log.Error("logic.OpenFile failed", zap.String("key", key), zap.Error(err))
+		}
+		c.Status(http.StatusNotFound)
+		return
+	}
+	defer rc.Close()
+
+	// 文件按内容哈希命名，内容永远不会变，链接有效期内浏览器可以放心缓存
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	if _, err = io.Copy(c.Writer, rc); err != nil {
+		h.log.Warn("download file interrupted", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// openFormFile 取出表单里的 file 字段，请求体超过 maxSize 直接拒绝，不会整个读进来
+func openFormFile(c *gin.Context, maxSize int64) (multipart.File, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, logic.ErrorFileTooLarge
+		}
+		return nil, err
+	}
+	return fh.Open()
+}
+
+// handleFormFileError 表单里取文件失败：太大，或者不是 multipart / 没有 file 字段
+func handleFormFileError(c *gin.Context, err error) {
+	if errors.Is(err, logic.ErrorFileTooLarge) {
+		common.Error(c, common.CodeFileTooLarge, err)
+		return
+	}
+	common.Error(c, common.CodeInvalidParam, err)
+}
+
+// handleFileError 上传相关错误统一转成响应码
+func handleFileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrorFileTooLarge):
+		common.Error(c, common.CodeFileTooLarge, err)
+	case errors.Is(err, logic.ErrorFileTypeNotAllowed):
+		common.Error(c, common.CodeFileTypeNotAllowed, err)
+	case errors.Is(err, dao.ErrorUserNotFound):
+		common.Error(c, common.CodeUserNotExist, err)
+	default:
+		common.Error(c, common.CodeServerBusy, err)
+	}
+}
@@ -0,0 +1,21 @@
+package controller
+
+import (
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/pkg/jwt"
+	"gin-api-scaffold-v1/pkg/session"
+)
+
+// Handler 所有接口的处理函数都是它的方法，依赖由 app.App 注入
+type Handler struct {
+	svc     *logic.Service
+	log     *zap.Logger
+	jwt     *jwt.Manager
+	cookies *session.Manager
+}
+
+func NewHandler(svc *logic.Service, log *zap.Logger, jwt *jwt.Manager, cookies *session.Manager) *Handler {
+	return &Handler{svc: svc, log: log, jwt: jwt, cookies: cookies}
+}
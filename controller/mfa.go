@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+)
+
+// MFAEnrollHandler 开始绑定两步验证
+// @Summary      绑定两步验证
+// @Description  生成新的 TOTP 密钥并返回 otpauth:// 地址，需要再调用确认接口才会生效；已经开启两步验证时 (换绑) 必须提供当前的验证码或一个恢复码
+// @Tags         两步验证
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamMFAEnroll  false  "当前的验证码 (换绑时必填)"
+// @Success      200  {object} common.Response{data=models.ResMFAEnroll} "密钥信息"
+// @Router       /mfa/enroll [post]
+func (h *Handler) MFAEnrollHandler(c *gin.Context) {
+	// 第一次绑定不需要传任何参数，允许请求体为空
+	var p models.ParamMFAEnroll
+	if err := c.ShouldBindJSON(&p); err != nil && !errors.Is(err, io.EOF) {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	data, err := h.svc.EnrollMFA(c.Request.Context(), mc.UserID, mc.Username, p.Code, clientInfo(c))
+	if err != nil {
+		h.log.Error("logic.EnrollMFA failed", zap.Int64("user_id", mc.UserID), zap.Error(err))
+		if handleLoginLocked(c, err) {
+			return
+		}
+		handleMFAError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// MFAConfirmHandler 确认绑定两步验证
+// @Summary      确认绑定两步验证
+// @Description  提交验证器 App 上的 6 位验证码，成功后启用两步验证并返回恢复码 (只显示这一次)；验证码错误和登录共用失败计数
+// @Tags         两步验证
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamMFACode  true  "验证码"
+// @Success      200  {object} common.Response{data=models.ResRecoveryCodes} "恢复码"
+// @Router       /mfa/confirm [post]
+func (h *Handler) MFAConfirmHandler(c *gin.Context) {
+	var p models.ParamMFACode
+	if err := c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	data, err := h.svc.ConfirmMFA(c.Request.Context(), mc.UserID, mc.Username, p.Code, clientInfo(c))
+	if err != nil {
+		h.log.Error("logic.ConfirmMFA failed", zap.Int64("user_id", mc.UserID), zap.Error(err))
+		if handleLoginLocked(c, err) {
+			return
+		}
+		handleMFAError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// MFARecoveryCodesHandler 重新生成恢复码
+// @Summary      重新生成恢复码
+// @Description  需要当前的 6 位验证码，旧的恢复码全部作废；验证码错误和登录共用失败计数，错太多次会被锁定
+// @Tags         两步验证
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamMFACode  true  "验证码"
+// @Success      200  {object} common.Response{data=models.ResRecoveryCodes} "恢复码"
+// @Router       /mfa/recovery-codes [post]
+func (h *Handler) MFARecoveryCodesHandler(c *gin.Context) {
+	var p models.ParamMFACode
+	if err := c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	data, err := h.svc.RegenerateRecoveryCodes(c.Request.Context(), mc.UserID, mc.Username, p.Code, clientInfo(c))
+	if err != nil {
+		h.log.Error("logic.RegenerateRecoveryCodes failed", zap.Int64("user_id", mc.UserID), zap.Error(err))
+		if handleLoginLocked(c, err) {
+			return
+		}
+		handleMFAError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// LoginMFAHandler 两步登录第二步
+// @Summary      两步验证登录
+// @Description  使用登录接口返回的 mfa_token 加上 6 位验证码 (或恢复码) 换取正式 Token
+// @Tags         用户相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Param        object body  models.ParamLoginMFA  true  "两步验证参数"
+// @Success      200  {object} common.Response{data=models.ResToken} "登录成功"
+// @Router       /login/mfa [post]
+func (h *Handler) LoginMFAHandler(c *gin.Context) {
+	var p models.ParamLoginMFA
+	if err := c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	token, err := h.svc.LoginMFA(c.Request.Context(), &p, clientInfo(c))
+	if err != nil {
+		h.log.Error("logic.LoginMFA failed", zap.Error(err))
+		if handleLoginLocked(c, err) {
+			return
+		}
+		if errors.Is(err, logic.ErrorInvalidMFAToken) {
+			common.Error(c, common.CodeInvalidToken, err)
+			return
+		}
+		handleMFAError(c, err)
+		return
+	}
+	h.respondToken(c, token)
+}
+
+// handleMFAError 两步验证相关错误统一转成响应码
+func handleMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrorInvalidMFACode):
+		common.Error(c, common.CodeInvalidMFACode, err)
+	case errors.Is(err, logic.ErrorMFANotEnabled), errors.Is(err, dao.ErrorMFANotFound):
+		common.Error(c, common.CodeMFANotEnabled, err)
+	default:
+		common.Error(c, common.CodeServerBusy, err)
+	}
+}
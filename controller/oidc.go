@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+)
+
+// OIDCAuthorizeHandler 发起第三方登录
+// @Summary      第三方登录跳转地址
+// @Description  返回身份提供方的登录地址，前端跳转过去；登录完成后身份提供方会带着 code 和 state 跳回配置的 redirect_url
+// @Tags         用户相关接口
+// @Produce      application/json
+// @Param        provider path  string  true  "身份提供方 (oidc.providers 里配置的名字)"
+// @Success      200  {object} common.Response{data=models.ResOIDCAuthURL} "登录地址"
+// @Router       /oauth/{provider}/authorize [get]
+func (h *Handler) OIDCAuthorizeHandler(c *gin.Context) {
+	provider := c.Param("provider")
+	data, err := h.svc.OIDCAuthURL(c.Request.Context(), provider)
+	if err != nil {
+		h.log.Error("logic.OIDCAuthURL failed", zap.String("provider", provider), zap.Error(err))
+		handleOIDCError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// OIDCCallbackHandler 第三方登录回调
+// @Summary      第三方登录
+// @Description  提交回调地址里的 code 和 state，换取本站的 Token。邮箱已验证时会自动绑定到同邮箱的账号
+// @Tags         用户相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Param        provider path  string  true  "身份提供方"
+// @Param        object body  models.ParamOIDCCallback  true  "回调参数"
+// @Success      200  {object} common.Response{data=models.ResToken} "登录成功"
+// @Router       /oauth/{provider}/callback [post]
+func (h *Handler) OIDCCallbackHandler(c *gin.Context) {
+	var p models.ParamOIDCCallback
+	if err := c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	provider := c.Param("provider")
+	token, err := h.svc.OIDCLogin(c.Request.Context(), provider, &p, clientInfo(c))
+	if err != nil {
+		h.log.Error("logic.OIDCLogin failed", zap.String("provider", provider), zap.Error(err))
+		handleOIDCError(c, err)
+		return
+	}
+	h.respondToken(c, token)
+}
+
+func handleOIDCError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, logic.ErrorUnknownOIDCProvider):
+		common.Error(c, common.CodeOIDCProviderNotExist, err)
+	case errors.Is(err, logic.ErrorInvalidOIDCState), errors.Is(err, logic.ErrorOIDCLoginFailed):
+		common.Error(c, common.CodeOIDCLoginFailed, err)
+	case errors.Is(err, logic.ErrorOIDCEmailConflict):
+		common.ErrorWithMsg(c, common.CodeEmailExist, err.Error())
+	default:
+		common.Error(c, common.CodeServerBusy, err)
+	}
+}
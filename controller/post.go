@@ -0,0 +1,240 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+)
+
+// CreatePostHandler 发帖
+// @Summary      发帖
+// @Description  在指定社区发帖
+// @Tags         帖子相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamCreatePost  true  "帖子参数"
+// @Success      200  {object} common.Response{data=models.ResPost} "发帖成功"
+// @Router       /posts [post]
+func (h *Handler) CreatePostHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	var p models.ParamCreatePost
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.CreatePost(c.Request.Context(), userID, &p)
+	if err != nil {
+		h.log.Error("logic.CreatePost failed", zap.Int64("user_id", userID), zap.Error(err))
+		handlePostError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// GetPostHandler 帖子详情
+// @Summary      帖子详情
+// @Description  根据帖子 ID 查询帖子，带作者名和社区名
+// @Tags         帖子相关接口
+// @Produce      application/json
+// @Param        id path  string  true  "帖子 ID"
+// @Success      200  {object} common.Response{data=models.ResPost} "帖子详情"
+// @Router       /posts/{id} [get]
+func (h *Handler) GetPostHandler(c *gin.Context) {
+	postID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.GetPost(c.Request.Context(), postID)
+	if err != nil {
+		h.log.Error("logic.GetPost failed", zap.Int64("post_id", postID), zap.Error(err))
+		handlePostError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// UpdatePostHandler 编辑帖子
+// @Summary      编辑帖子
+// @Description  修改帖子的标题和内容，只有作者本人可以编辑
+// @Tags         帖子相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "帖子 ID"
+// @Param        object body  models.ParamUpdatePost  true  "新的标题和内容"
+// @Success      200  {object} common.Response{data=models.ResPost} "修改后的帖子"
+// @Router       /posts/{id} [put]
+func (h *Handler) UpdatePostHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	postID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	var p models.ParamUpdatePost
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.UpdatePost(c.Request.Context(), userID, postID, &p)
+	if err != nil {
+		h.log.Error("logic.UpdatePost failed", zap.Int64("user_id", userID), zap.Int64("post_id", postID), zap.Error(err))
+		handlePostError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// DeletePostHandler 删除帖子
+// @Summary      删除帖子
+// @Description  作者本人、社区创建者 / 版主、拥有 post:delete 权限的人可以删除
+// @Tags         帖子相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "帖子 ID"
+// @Success      200  {object} common.Response "删除成功"
+// @Router       /posts/{id} [delete]
+func (h *Handler) DeletePostHandler(c *gin.Context) {
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	postID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err = h.svc.DeletePost(c.Request.Context(), mc, postID); err != nil {
+		h.log.Error("logic.DeletePost failed", zap.Int64("user_id", mc.UserID), zap.Int64("post_id", postID), zap.Error(err))
+		handlePostError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// ListCommunityPostsHandler 社区帖子列表
+// @Summary      社区帖子列表
+// @Description  按游标分页列出社区里的帖子，按发帖时间或热度倒序；换排序方式时从第一页重新开始
+// @Tags         帖子相关接口
+// @Produce      application/json
+// @Param        id path  string  true  "社区 ID"
+// @Param        order query string false "排序方式：time (默认) / score"
+// @Param        cursor query string false "上一页返回的 next_cursor，第一页不传"
+// @Param        size query int false "每页数量，默认 20，最多 100"
+// @Success      200  {object} common.Response{data=models.ResPostList} "帖子列表"
+// @Router       /communities/{id}/posts [get]
+func (h *Handler) ListCommunityPostsHandler(c *gin.Context) {
+	communityID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	var p models.ParamPostList
+	if err = c.ShouldBindQuery(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.ListCommunityPosts(c.Request.Context(), communityID, &p)
+	if err != nil {
+		h.log.Error("logic.ListCommunityPosts failed", zap.Int64("community_id", communityID), zap.Error(err))
+		handlePostError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// ListPostsHandler 全站帖子列表
+// @Summary      全站帖子列表
+// @Description  按游标分页列出所有社区的帖子，按发帖时间或热度倒序；换排序方式时从第一页重新开始
+// @Tags         帖子相关接口
+// @Produce      application/json
+// @Param        order query string false "排序方式：time (默认) / score"
+// @Param        cursor query string false "上一页返回的 next_cursor，第一页不传"
+// @Param        size query int false "每页数量，默认 20，最多 100"
+// @Success      200  {object} common.Response{data=models.ResPostList} "帖子列表"
+// @Router       /posts [get]
+func (h *Handler) ListPostsHandler(c *gin.Context) {
+	var p models.ParamPostList
+	if err := c.ShouldBindQuery(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.ListPosts(c.Request.Context(), &p)
+	if err != nil {
+		h.log.Error("logic.ListPosts failed", zap.Error(err))
+		handlePostError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// VotePostHandler 给帖子投票
+// @Summary      给帖子投票
+// @Description  direction: 1 赞成 / -1 反对 / 0 取消，重复投票会覆盖之前的选择；发帖超过投票期后不能再投
+// @Tags         帖子相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "帖子 ID"
+// @Param        object body  models.ParamVote  true  "投票方向"
+// @Success      200  {object} common.Response{data=models.ResVote} "最新的票数"
+// @Router       /posts/{id}/vote [post]
+func (h *Handler) VotePostHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	postID, err := paramID(c, "id")
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	var p models.ParamVote
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.VotePost(c.Request.Context(), userID, postID, *p.Direction)
+	if err != nil {
+		h.log.Error("logic.VotePost failed", zap.Int64("user_id", userID), zap.Int64("post_id", postID), zap.Error(err))
+		handlePostError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// handlePostError 帖子相关错误统一转成响应码
+func handlePostError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, dao.ErrorPostNotFound):
+		common.Error(c, common.CodePostNotExist, err)
+	case errors.Is(err, dao.ErrorCommunityNotFound):
+		common.Error(c, common.CodeCommunityNotExist, err)
+	case errors.Is(err, logic.ErrorPostForbidden):
+		common.Error(c, common.CodeForbidden, err)
+	case errors.Is(err, logic.ErrorVoteTimeExpired):
+		common.Error(c, common.CodeVoteTimeExpired, err)
+	case errors.Is(err, common.ErrorInvalidCursor):
+		common.Error(c, common.CodeInvalidParam, err)
+	default:
+		common.Error(c, common.CodeServerBusy, err)
+	}
+}
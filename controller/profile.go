@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+)
+
+// GetMeHandler 查询个人资料
+// @Summary      个人资料
+// @Description  查询当前登录用户的资料 (从数据库读取)
+// @Tags         用户相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Success      200  {object} common.Response{data=models.ResProfile} "个人资料"
+// @Router       /me [get]
+func (h *Handler) GetMeHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	data, err := h.svc.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		h.log.Error("logic.GetProfile failed", zap.Int64("user_id", userID), zap.Error(err))
+		handleProfileError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// UpdateMeHandler 修改个人资料
+// @Summary      修改个人资料
+// @Description  只修改请求里传了的字段，返回修改后的资料
+// @Tags         用户相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamUpdateProfile  true  "要修改的字段"
+// @Success      200  {object} common.Response{data=models.ResProfile} "修改后的资料"
+// @Router       /me [patch]
+func (h *Handler) UpdateMeHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	var p models.ParamUpdateProfile
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.UpdateProfile(c.Request.Context(), userID, &p)
+	if err != nil {
+		h.log.Error("logic.UpdateProfile failed", zap.Int64("user_id", userID), zap.Error(err))
+		handleProfileError(c, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// ChangePasswordHandler 修改密码
+// @Summary      修改密码
+// @Description  校验旧密码后设置新密码，其他设备全部退出登录，当前设备返回一对新 Token
+// @Tags         用户相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamChangePassword  true  "旧密码和新密码"
+// @Success      200  {object} common.Response{data=models.ResToken} "修改成功"
+// @Router       /me/password [post]
+func (h *Handler) ChangePasswordHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	var p models.ParamChangePassword
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	token, err := h.svc.ChangePassword(c.Request.Context(), userID, &p, clientInfo(c))
+	if err != nil {
+		h.log.Error("logic.ChangePassword failed", zap.Int64("user_id", userID), zap.Error(err))
+		handleProfileError(c, err)
+		return
+	}
+	h.respondToken(c, token)
+}
+
+// ChangeEmailHandler 修改邮箱
+// @Summary      修改邮箱
+// @Description  校验密码后修改邮箱，新邮箱需要点击验证邮件里的链接重新验证
+// @Tags         用户相关接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        object body  models.ParamChangeEmail  true  "新邮箱和当前密码"
+// @Success      200  {object} common.Response "修改成功"
+// @Router       /me/email [post]
+func (h *Handler) ChangeEmailHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	var p models.ParamChangeEmail
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err = h.svc.ChangeEmail(c.Request.Context(), userID, &p, c.ClientIP()); err != nil {
+		h.log.Error("logic.ChangeEmail failed", zap.Int64("user_id", userID), zap.Error(err))
+		handleProfileError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// handleProfileError 个人资料相关错误统一转成响应码
+func handleProfileError(c *gin.Context, err error) {
+	if handleLoginLocked(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, dao.ErrorUserNotFound):
+		common.Error(c, common.CodeUserNotExist, err)
+	case errors.Is(err, logic.ErrorWrongPassword):
+		common.Error(c, common.CodeInvalidPassword, err)
+	case errors.Is(err, dao.ErrorEmailExist):
+		common.Error(c, common.CodeEmailExist, err)
+	default:
+		common.Error(c, common.CodeServerBusy, err)
+	}
+}
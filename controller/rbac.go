@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/models"
+)
+
+// AssignRoleHandler 给用户分配角色
+// @Summary      分配角色
+// @Description  给指定用户分配角色，用户下次刷新 Token 时生效
+// @Tags         管理接口
+// @Accept       application/json
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        user_id path  int  true  "用户 ID"
+// @Param        object body  models.ParamAssignRole  true  "角色"
+// @Success      200  {object} common.Response "分配成功"
+// @Router       /admin/users/{user_id}/roles [post]
+func (h *Handler) AssignRoleHandler(c *gin.Context) {
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	var p models.ParamAssignRole
+	if err = c.ShouldBindJSON(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	if err = h.svc.AssignRole(c.Request.Context(), mc, userID, p.Role); err != nil {
+		h.log.Error("logic.AssignRole failed", zap.Int64("user_id", userID), zap.String("role", p.Role), zap.Error(err))
+		handleRoleError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// RevokeRoleHandler 收回用户的角色
+// @Summary      收回角色
+// @Description  收回指定用户的角色，该用户所有设备需要重新登录
+// @Tags         管理接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        user_id path  int     true  "用户 ID"
+// @Param        role    path  string  true  "角色名"
+// @Success      200  {object} common.Response "收回成功"
+// @Router       /admin/users/{user_id}/roles/{role} [delete]
+func (h *Handler) RevokeRoleHandler(c *gin.Context) {
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	role := c.Param("role")
+	if err = h.svc.RevokeRole(c.Request.Context(), mc, userID, role); err != nil {
+		h.log.Error("logic.RevokeRole failed", zap.Int64("user_id", userID), zap.String("role", role), zap.Error(err))
+		handleRoleError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+func handleRoleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, dao.ErrorRoleNotFound):
+		common.Error(c, common.CodeRoleNotExist, err)
+	case errors.Is(err, dao.ErrorUserNotFound):
+		common.Error(c, common.CodeUserNotExist, err)
+	case errors.Is(err, logic.ErrorAssignAdminForbidden):
+		common.Error(c, common.CodeForbidden, err)
+	default:
+		common.Error(c, common.CodeServerBusy, err)
+	}
+}
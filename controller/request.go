@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+// ErrorUserNotLogin 上下文里取不到登录信息 (通常是路由忘了挂 JWTAuthMiddleware)
+var ErrorUserNotLogin = errors.New("用户未登录")
+
+// getCurrentUserID 获取当前登录用户的 ID (由 middleware.JWTAuthMiddleware 写入)
+func getCurrentUserID(c *gin.Context) (userID int64, err error) {
+	uid, ok := c.Get("userID")
+	if !ok {
+		return 0, ErrorUserNotLogin
+	}
+	userID, ok = uid.(int64)
+	if !ok {
+		return 0, ErrorUserNotLogin
+	}
+	return userID, nil
+}
+
+// getCurrentClaims 获取当前请求携带的完整 Token 声明 (jti、过期时间、family 等)
+func getCurrentClaims(c *gin.Context) (*jwt.MyClaims, error) {
+	v, ok := c.Get("claims")
+	if !ok {
+		return nil, ErrorUserNotLogin
+	}
+	mc, ok := v.(*jwt.MyClaims)
+	if !ok {
+		return nil, ErrorUserNotLogin
+	}
+	return mc, nil
+}
+
+// clientInfo 当前请求的客户端信息 (登录时记录到会话里)
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// paramID 解析路径参数里的雪花 ID (例如 /communities/:id)
+func paramID(c *gin.Context, name string) (int64, error) {
+	return strconv.ParseInt(c.Param(name), 10, 64)
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/models"
+)
+
+// SearchHandler 搜索
+// @Summary      搜索帖子 / 用户
+// @Description  按相关度排序，命中的部分在 highlight_* 字段里用 <em> 标出；中文按相邻两个字匹配
+// @Tags         搜索相关接口
+// @Produce      application/json
+// @Param        q query string true "关键词"
+// @Param        type query string false "搜索类型：post (默认) / user"
+// @Param        community_id query string false "只搜某个社区的帖子"
+// @Param        start_date query string false "创建时间不早于这一天，格式 2006-01-02"
+// @Param        end_date query string false "创建时间不晚于这一天，格式 2006-01-02"
+// @Param        page query int false "页码，从 1 开始"
+// @Param        size query int false "每页数量，默认 20，最多 100"
+// @Success      200  {object} common.Response{data=models.ResSearch} "搜索结果"
+// @Router       /search [get]
+func (h *Handler) SearchHandler(c *gin.Context) {
+	var p models.ParamSearch
+	if err := c.ShouldBindQuery(&p); err != nil {
+		common.Error(c, common.CodeInvalidParam, err)
+		return
+	}
+	data, err := h.svc.Search(c.Request.Context(), &p)
+	if err != nil {
+		h.log.Error("logic.Search failed", zap.String("q", p.Q), zap.String("type", p.Type), zap.Error(err))
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	common.Success(c, data)
+}
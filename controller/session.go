@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+)
+
+// ListSessionsHandler 登录设备列表
+// @Summary      登录设备列表
+// @Description  列出当前用户所有登录着的设备 (IP、User-Agent、登录时间、最后活跃时间)，current 表示当前设备
+// @Tags         认证相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Success      200  {object} common.Response{data=[]models.Session} "设备列表"
+// @Router       /sessions [get]
+func (h *Handler) ListSessionsHandler(c *gin.Context) {
+	mc, err := getCurrentClaims(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	data, err := h.svc.ListSessions(c.Request.Context(), mc)
+	if err != nil {
+		h.log.Error("logic.ListSessions failed", zap.Int64("user_id", mc.UserID), zap.Error(err))
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	common.Success(c, data)
+}
+
+// RevokeSessionHandler 踢掉某台设备
+// @Summary      踢掉设备
+// @Description  让指定设备退出登录，立即生效
+// @Tags         认证相关接口
+// @Produce      application/json
+// @Security     ApiKeyAuth
+// @Param        id path  string  true  "会话 ID"
+// @Success      200  {object} common.Response "成功"
+// @Router       /sessions/{id} [delete]
+func (h *Handler) RevokeSessionHandler(c *gin.Context) {
+	userID, err := getCurrentUserID(c)
+	if err != nil {
+		common.Error(c, common.CodeNeedLogin, nil)
+		return
+	}
+	sessionID := c.Param("id")
+	if err = h.svc.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		h.log.Error("logic.RevokeSession failed", zap.Int64("user_id", userID), zap.String("session_id", sessionID), zap.Error(err))
+		if errors.Is(err, dao.ErrorSessionNotFound) {
+			common.Error(c, common.CodeSessionNotExist, err)
+			return
+		}
+		common.Error(c, common.CodeServerBusy, err)
+		return
+	}
+	common.Success(c, nil)
+}
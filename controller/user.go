@@ -9,35 +9,38 @@ import (
 
 	"gin-api-scaffold-v1/common"
 	"gin-api-scaffold-v1/dao"
-	"gin-api-scaffold-v1/logic"
 	"gin-api-scaffold-v1/models"
 )
 
 // SignUpHandler 处理注册请求
 // @Summary      用户注册
-// @Description  处理用户注册请求，需要提供用户名、密码和确认密码
+// @Description  处理用户注册请求，需要提供用户名、密码、确认密码和邮箱，注册成功后会给邮箱发送验证邮件
 // @Tags         用户相关接口
 // @Accept       application/json
 // @Produce      application/json
 // @Param        object body  models.ParamSignUp  true  "注册参数"
 // @Success      200  {object} common.Response "注册成功"
 // @Router       /signup [post]
-func SignUpHandler(c *gin.Context) {
+func (h *Handler) SignUpHandler(c *gin.Context) {
 	// 1. 获取参数和参数校验
 	var p models.ParamSignUp
 	if err := c.ShouldBindJSON(&p); err != nil {
-		zap.L().Error("SignUp with invalid param", zap.Error(err))
+		h.log.Error("SignUp with invalid param", zap.Error(err))
 		common.Error(c, common.CodeInvalidParam, err)
 		return
 	}
 
 	// 2. 业务处理
-	if err := logic.SignUp(&p); err != nil {
-		zap.L().Error("logic.SignUp failed", zap.Error(err))
+	if err := h.svc.SignUp(c.Request.Context(), &p); err != nil {
+		h.log.Error("logic.SignUp failed", zap.Error(err))
 		if errors.Is(err, dao.ErrorUserExist) {
 			common.Error(c, common.CodeUserExist, err)
 			return
 		}
+		if errors.Is(err, dao.ErrorEmailExist) {
+			common.Error(c, common.CodeEmailExist, err)
+			return
+		}
 		common.Error(c, common.CodeServerBusy, err)
 		return
 	}
@@ -48,26 +51,29 @@ func SignUpHandler(c *gin.Context) {
 
 // LoginHandler 处理登录请求
 // @Summary      用户登录
-// @Description  处理用户登录请求，返回JWT Token
+// @Description  处理用户登录请求，返回短期 Access Token 和用于续期的 Refresh Token
 // @Tags         用户相关接口
 // @Accept       application/json
 // @Produce      application/json
 // @Param        object body  models.ParamLogin  true  "登录参数"
-// @Success      200  {object} common.Response{data=models.ParamLogin} "登录成功"
+// @Success      200  {object} common.Response{data=models.ResToken} "登录成功"
 // @Router       /login [post]
-func LoginHandler(c *gin.Context) {
+func (h *Handler) LoginHandler(c *gin.Context) {
 	// 1. 获取参数
 	var p models.ParamLogin
 	if err := c.ShouldBindJSON(&p); err != nil {
-		zap.L().Error("Login with invalid param", zap.Error(err))
+		h.log.Error("Login with invalid param", zap.Error(err))
 		common.Error(c, common.CodeInvalidParam, err)
 		return
 	}
 
 	// 2. 业务处理
-	token, err := logic.Login(&p)
+	token, err := h.svc.Login(c.Request.Context(), &p, clientInfo(c))
 	if err != nil {
-		zap.L().Error("logic.Login failed", zap.String("username", p.Username), zap.Error(err))
+		h.log.Error("logic.Login failed", zap.String("username", p.Username), zap.Error(err))
+		if handleLoginLocked(c, err) {
+			return
+		}
 		if err.Error() == "用户不存在" {
 			common.Error(c, common.CodeUserNotExist, err)
 		} else {
@@ -77,12 +83,9 @@ func LoginHandler(c *gin.Context) {
 	}
 
 	// 3. 返回响应
-	// ⚡️ 这里我们返回 Token 和用户名
+	// ⚡️ 这里我们返回 Token 对和用户名
 	// 前端拿到 Token 后，会自动解码出 UserID，所以这里不传 UserID 也可以
-	common.Success(c, gin.H{
-		"token":    token,
-		"username": p.Username,
-	})
+	h.respondToken(c, token)
 }
 
 // GetProfileHandler 获取用户个人信息 (测试 JWT 用)
@@ -95,12 +98,12 @@ func LoginHandler(c *gin.Context) {
 // @Success      200  {object} common.Response "成功返回用户信息"
 // @Router       /home [get]
 // ⚡️ 这是新增的，用来替代 routers.go 里那个匿名函数
-func GetProfileHandler(c *gin.Context) {
+func (h *Handler) GetProfileHandler(c *gin.Context) {
 	// 1. 从上下文中取出 userID (这是中间件 middleware.JWTAuthMiddleware 塞进去的)
 	// 如果取不到，说明中间件没生效（或者没配置好），属于系统级错误
 	userID, exists := c.Get("userID")
 	if !exists {
-		zap.L().Error("GetProfileHandler: userID not found in context")
+		h.log.Error("GetProfileHandler: userID not found in context")
 		common.Error(c, common.CodeNeedLogin, nil)
 		return
 	}
@@ -108,10 +111,7 @@ func GetProfileHandler(c *gin.Context) {
 	// 2. 取出 username
 	username, _ := c.Get("username")
 
-	// 3. (可选) 这里通常会拿 userID 去数据库查更详细的信息
-	// user, err := logic.GetUserByID(userID.(int64))
-
-	// 4. 返回数据
+	// 3. 返回数据 (这里只回显 Token 里的信息，完整的个人资料见 GET /me)
 	common.Success(c, gin.H{
 		"user_id":  userID,
 		"username": username,
@@ -7,89 +7,124 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 
-	// 引入必要的包以初始化环境
+	"gin-api-scaffold-v1/common"
 	"gin-api-scaffold-v1/dao"
-	"gin-api-scaffold-v1/logger"
+	"gin-api-scaffold-v1/logic"
 	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+	"gin-api-scaffold-v1/pkg/mailer"
+	"gin-api-scaffold-v1/pkg/oidc"
+	"gin-api-scaffold-v1/pkg/search"
+	"gin-api-scaffold-v1/pkg/session"
 	"gin-api-scaffold-v1/pkg/snowflake"
-	"gin-api-scaffold-v1/settings"
+	"gin-api-scaffold-v1/pkg/storage"
+	"gin-api-scaffold-v1/pkg/validator"
 )
 
-// initEnv 初始化测试环境 (数据库、配置等)
-// ⚠️ 注意：这需要连接真实的数据库
-func initEnv() {
-	// 1. 加载配置 (注意路径，测试文件在 controller 目录下，可能需要调整相对路径)
-	// 如果报错找不到配置文件，建议把 config.yaml 复制一份到 controller 目录或者写死路径
-	if err := settings.InitConfig(); err != nil {
-		panic(err)
-	}
-
-	// 2. 初始化日志
-	logger.InitLogger()
-
-	// 3. 初始化数据库 (GORM)
-	if err := dao.InitMySQL(); err != nil {
-		panic(err)
-	}
-
-	// 4. 初始化 Redis (如果 Login 用到了的话)
-	dao.InitRedis()
-
-	// 5. 初始化雪花算法
-	snowflake.Init("2026-01-01", 1)
-
-	// 6. Mock JWT 配置 (防止配置文件里没写)
-	viper.Set("auth.jwt_secret", "test_secret")
-	viper.Set("auth.jwt_expire", 24)
+// newTestHandler 创建测试用的 Handler
+// 用户、角色、两步验证数据用内存实现，Redis 用 miniredis，不需要真实的数据库；
+// 注册时发的验证邮件只打日志，用户名写进内存搜索索引，JWT 配置不读配置文件
+func newTestHandler(t *testing.T) *Handler {
+	cfg := viper.New()
+	cfg.Set("auth.jwt_secret", "test_secret")
+	cfg.Set("auth.access_expire", 15)
+	cfg.Set("auth.refresh_expire", 24)
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	ids, err := snowflake.NewNode("2026-01-01", 1)
+	assert.NoError(t, err)
+	// 注册 username 等自定义校验规则 (正式环境由 app.New 注册)
+	_, err = validator.NewTranslator("zh")
+	assert.NoError(t, err)
+	jm, err := jwt.NewManager(cfg)
+	assert.NoError(t, err)
+
+	log := zap.NewNop()
+	svc := logic.NewService(logic.Deps{
+		Config:  cfg,
+		Logger:  log,
+		Store:   dao.NewStore(nil, rdb),
+		Repos:   logic.NewMemoryRepositories(),
+		IDs:     ids,
+		JWT:     jm,
+		Mailer:  &mailer.FileMailer{},
+		Storage: &storage.LocalStorage{Dir: t.TempDir(), Secret: []byte("test_secret")},
+		Search:  search.NewMemoryEngine(),
+		OIDC:    oidc.NewRegistry(cfg),
+		Cursor:  common.NewCursorCodec("", "test_secret"),
+	})
+	return NewHandler(svc, log, jm, session.New(cfg))
 }
 
-func TestLoginHandler(t *testing.T) {
-	// 初始化环境 (连接数据库等)
-	// 如果你只想测路由结构而不连库，可以注释掉这一行，但业务逻辑会报错
-	initEnv()
-
-	// 1. 设置 Gin 为测试模式 (不打印多余日志)
-	gin.SetMode(gin.TestMode)
-
-	// 2. 定义路由
-	r := gin.Default()
-	r.POST("/login", LoginHandler)
-
-	// 3. 构造请求参数 (假设数据库里已经有 admin/123456 这个用户)
-	// 如果没有，请先在数据库里手动插入一条，或者先调用 SignUp 接口
-	params := models.ParamLogin{
-		Username: "admin",
-		Password: "123456",
-	}
-	jsonBytes, _ := json.Marshal(params)
-
-	// 4. 构造 HTTP 请求
-	// 方法: POST, 路径: /login, Body: JSON数据
-	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(jsonBytes))
+// postJSON 发一个 JSON 请求，返回解析后的响应
+func postJSON(t *testing.T, r *gin.Engine, path string, body interface{}) (code common.ResCode, data json.RawMessage) {
+	jsonBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(jsonBytes))
 	req.Header.Set("Content-Type", "application/json") // 必加！
-
-	// 5. 创建记录仪 (假浏览器)
 	w := httptest.NewRecorder()
-
-	// 6. 执行请求
 	r.ServeHTTP(w, req)
+	t.Logf("%s 响应结果: %s", path, w.Body.String())
 
-	// 7. 断言结果
-	// 拿到响应结果
-	respBody := w.Body.String()
-	t.Logf("响应结果: %s", respBody)
-
-	// 判断状态码
-	// 注意：如果数据库连不上，这里可能是 200 (CommonError也是200返回) 但是 code 是非 1000
 	assert.Equal(t, http.StatusOK, w.Code)
-
-	// 判断是否包含 token
-	if w.Code == 200 {
-		// 只有登录成功才会有 token
-		// assert.Contains(t, respBody, "token")
+	var resp struct {
+		Code common.ResCode  `json:"code"`
+		Data json.RawMessage `json:"data"`
 	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Code, resp.Data
+}
+
+func TestLoginHandler(t *testing.T) {
+	h := newTestHandler(t)
+
+	// 设置 Gin 为测试模式 (不打印多余日志)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/signup", h.SignUpHandler)
+	r.POST("/login", h.LoginHandler)
+
+	// 1. 先注册
+	code, _ := postJSON(t, r, "/signup", models.ParamSignUp{
+		Username:   "admin",
+		Password:   "123456",
+		RePassword: "123456",
+		Email:      "admin@example.com",
+	})
+	assert.Equal(t, common.CodeSuccess, code)
+
+	// 2. 同名用户不能重复注册
+	code, _ = postJSON(t, r, "/signup", models.ParamSignUp{
+		Username:   "admin",
+		Password:   "123456",
+		RePassword: "123456",
+		Email:      "other@example.com",
+	})
+	assert.Equal(t, common.CodeUserExist, code)
+
+	// 3. 密码错误
+	code, _ = postJSON(t, r, "/login", models.ParamLogin{Username: "admin", Password: "654321"})
+	assert.Equal(t, common.CodeInvalidPassword, code)
+
+	// 4. 用户不存在
+	code, _ = postJSON(t, r, "/login", models.ParamLogin{Username: "nobody", Password: "123456"})
+	assert.Equal(t, common.CodeUserNotExist, code)
+
+	// 5. 登录成功，拿到 Access Token + Refresh Token
+	code, data := postJSON(t, r, "/login", models.ParamLogin{Username: "admin", Password: "123456"})
+	assert.Equal(t, common.CodeSuccess, code)
+	var token models.ResToken
+	assert.NoError(t, json.Unmarshal(data, &token))
+	assert.NotEmpty(t, token.AccessToken)
+	assert.NotEmpty(t, token.RefreshToken)
+	assert.Equal(t, "admin", token.Username)
 }
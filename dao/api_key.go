@@ -0,0 +1,63 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"gin-api-scaffold-v1/models"
+)
+
+var ErrorAPIKeyNotFound = errors.New("API Key 不存在")
+
+// InsertAPIKey 保存新的 API Key
+func (s *Store) InsertAPIKey(ctx context.Context, key *models.APIKey) error {
+	return s.DB.WithContext(ctx).Create(key).Error
+}
+
+// CountActiveAPIKeys 统计用户还没吊销的 API Key 数量
+func (s *Store) CountActiveAPIKeys(ctx context.Context, userID int64) (count int64, err error) {
+	err = s.DB.WithContext(ctx).Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", userID).Count(&count).Error
+	return
+}
+
+// ListAPIKeys 列出用户还没吊销的 API Key
+func (s *Store) ListAPIKeys(ctx context.Context, userID int64) (keys []models.APIKey, err error) {
+	err = s.DB.WithContext(ctx).Where("user_id = ? AND revoked_at IS NULL", userID).Order("id DESC").Find(&keys).Error
+	return
+}
+
+// GetAPIKeyByHash 按哈希查询 API Key (鉴权用)，已吊销的视为不存在
+func (s *Store) GetAPIKeyByHash(ctx context.Context, hash string) (key *models.APIKey, err error) {
+	key = new(models.APIKey)
+	err = s.DB.WithContext(ctx).Where("key_hash = ? AND revoked_at IS NULL", hash).First(key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorAPIKeyNotFound
+	}
+	return
+}
+
+// RevokeAPIKey 吊销 API Key，只能吊销自己的
+func (s *Store) RevokeAPIKey(ctx context.Context, userID, keyID int64) error {
+	res := s.DB.WithContext(ctx).Model(&models.APIKey{}).
+		Where("key_id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrorAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey 更新最后使用时间
+// 每个请求都写库太浪费，距离上次更新不到 interval 就跳过 (条件写在 WHERE 里，不需要先查)
+func (s *Store) TouchAPIKey(ctx context.Context, id int64, interval time.Duration) error {
+	now := time.Now()
+	return s.DB.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		Update("last_used_at", now).Error
+}
@@ -0,0 +1,191 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/models"
+)
+
+var ErrorCommentNotFound = errors.New("评论不存在")
+
+// incrCommentCountScript 只在计数已经存在时才加减
+// 计数不存在 (Redis 数据丢失 / 被清理) 时不能从 0 开始加，留给读取的时候从 MySQL 重新统计
+// KEYS: 1 评论数 hash  ARGV: 1 post_id 2 增量
+var incrCommentCountScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 then
+	return redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+end
+return false
+`)
+
+// commentCountTTL 评论数缓存的过期时间
+// 统计和写回之间发表的评论加不上去 (计数还不存在)，靠过期后重新统计纠正，最多偏差这么久
+const commentCountTTL = time.Hour
+
+// setCommentCountsScript 把从 MySQL 统计出来的评论数写回缓存，已经有值的不覆盖，返回缓存里最终的值
+// 两个请求同时统计时，后写的不能把先写的、已经被加减过的计数冲掉；hash 没有过期时间时补上
+// KEYS: 1 评论数 hash  ARGV: 1 过期时间(秒) 之后每两个一组: post_id 评论数
+var setCommentCountsScript = redis.NewScript(`
+local res = {}
+for i = 2, #ARGV, 2 do
+	redis.call('HSETNX', KEYS[1], ARGV[i], ARGV[i + 1])
+	res[#res + 1] = redis.call('HGET', KEYS[1], ARGV[i])
+end
+if redis.call('TTL', KEYS[1]) == -1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[1])
+end
+return res
+`)
+
+// InsertComment 保存新评论
+func (s *Store) InsertComment(ctx context.Context, comment *models.Comment) error {
+	return s.DB.WithContext(ctx).Create(comment).Error
+}
+
+// GetCommentByID 根据 comment_id 查评论 (包括已删除的)
+func (s *Store) GetCommentByID(ctx context.Context, commentID int64) (comment *models.Comment, err error) {
+	comment = new(models.Comment)
+	err = s.DB.WithContext(ctx).Where("comment_id = ?", commentID).First(comment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorCommentNotFound
+	}
+	return
+}
+
+// SoftDeleteComment 软删除评论：清空内容，保留这一行，回复仍然挂在下面
+// 已经删除过的返回 ErrorCommentNotFound，保证评论数只减一次
+func (s *Store) SoftDeleteComment(ctx context.Context, commentID int64) error {
+	res := s.DB.WithContext(ctx).Model(&models.Comment{}).Where("comment_id = ? AND deleted = ?", commentID, false).
+		Updates(map[string]interface{}{"content": "", "deleted": true})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrorCommentNotFound
+	}
+	return nil
+}
+
+// ListTopComments 帖子的一级评论，按 comment_id (也就是发表时间) 正序，从 cursor 之后开始取 limit + 1 条
+func (s *Store) ListTopComments(ctx context.Context, postID int64, cursor *common.Cursor, limit int) (comments []models.Comment, err error) {
+	q := CursorQuery{IDColumn: "comment_id", Cursor: cursor, Limit: limit}
+	err = s.DB.WithContext(ctx).Where("post_id = ? AND parent_id = 0", postID).Scopes(q.Scope).Find(&comments).Error
+	return
+}
+
+// ListCommentReplies 一批评论的直接回复，每条评论最多取前 limit 条 (按 comment_id 正序)
+// 热门评论下面可能有成千上万条回复，不能一次全部查出来，剩下的用 ListReplies 分页
+func (s *Store) ListCommentReplies(ctx context.Context, parentIDs []int64, limit int) (comments []models.Comment, err error) {
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+	ranked := s.DB.WithContext(ctx).Model(&models.Comment{}).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY comment_id) AS rn").
+		Where("parent_id IN ?", parentIDs)
+	err = s.DB.WithContext(ctx).Table("(?) AS c", ranked).Where("rn <= ?", limit).Order("comment_id").Find(&comments).Error
+	return
+}
+
+// ListReplies 某条评论的直接回复，按 comment_id 正序，从 cursor 之后开始取 limit + 1 条
+func (s *Store) ListReplies(ctx context.Context, parentID int64, cursor *common.Cursor, limit int) (comments []models.Comment, err error) {
+	q := CursorQuery{IDColumn: "comment_id", Cursor: cursor, Limit: limit}
+	err = s.DB.WithContext(ctx).Where("parent_id = ?", parentID).Scopes(q.Scope).Find(&comments).Error
+	return
+}
+
+// CountCommentReplies 一批评论各自有多少条直接回复 (包括已删除的，它们在树里仍然占一个位置)
+func (s *Store) CountCommentReplies(ctx context.Context, parentIDs []int64) (map[int64]int64, error) {
+	return s.countCommentsBy(ctx, "parent_id", parentIDs, false)
+}
+
+// IncrPostCommentCount 发表 / 删除评论后更新 Redis 里的评论数
+func (s *Store) IncrPostCommentCount(ctx context.Context, postID, delta int64) error {
+	err := incrCommentCountScript.Run(ctx, s.RDB,
+		[]string{getRedisKey(KeyPostCommentCountHash)}, postID, delta).Err()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// GetPostCommentCounts 批量查帖子的评论数 (不含已删除的)
+// 优先读 Redis，Redis 里没有的从 MySQL 统计后写回 (不覆盖别人已经写回的值)
+func (s *Store) GetPostCommentCounts(ctx context.Context, postIDs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(postIDs))
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+	key := getRedisKey(KeyPostCommentCountHash)
+	fields := make([]string, len(postIDs))
+	for i, id := range postIDs {
+		fields[i] = strconv.FormatInt(id, 10)
+	}
+	vals, err := s.RDB.HMGet(ctx, key, fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+	missing := make([]int64, 0)
+	for i, v := range vals {
+		str, ok := v.(string)
+		if !ok {
+			missing = append(missing, postIDs[i])
+			continue
+		}
+		if counts[postIDs[i]], err = strconv.ParseInt(str, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if len(missing) == 0 {
+		return counts, nil
+	}
+
+	loaded, err := s.countCommentsBy(ctx, "post_id", missing, true)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]interface{}, 0, len(missing)*2+1)
+	args = append(args, int64(commentCountTTL/time.Second))
+	for _, id := range missing {
+		args = append(args, id, loaded[id])
+	}
+	cached, err := setCommentCountsScript.Run(ctx, s.RDB, []string{key}, args...).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range missing {
+		if counts[id], err = strconv.ParseInt(cached[i], 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// countCommentsBy 按 column 分组统计评论数，skipDeleted 为 true 时不算已删除的
+func (s *Store) countCommentsBy(ctx context.Context, column string, ids []int64, skipDeleted bool) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ID    int64
+		Count int64
+	}
+	query := s.DB.WithContext(ctx).Model(&models.Comment{}).Select(column+" AS id, COUNT(*) AS count").Where(column+" IN ?", ids)
+	if skipDeleted {
+		query = query.Where("deleted = ?", false)
+	}
+	if err := query.Group(column).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		counts[r.ID] = r.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,73 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// TestIncrPostCommentCount 计数存在时才加减，不存在时保持不存在，等读取时从 MySQL 重新统计
+func TestIncrPostCommentCount(t *testing.T) {
+	ctx := context.Background()
+	s, mr := setupMiniRedis(t)
+	key := getRedisKey(KeyPostCommentCountHash)
+
+	// 1. 没有缓存：不会从 0 开始加
+	assert.NoError(t, s.IncrPostCommentCount(ctx, 100, 1))
+	assert.False(t, mr.Exists(key))
+
+	// 2. 有缓存：正常加减
+	mr.HSet(key, "100", "5")
+	assert.NoError(t, s.IncrPostCommentCount(ctx, 100, 1))
+	assert.NoError(t, s.IncrPostCommentCount(ctx, 100, 1))
+	assert.NoError(t, s.IncrPostCommentCount(ctx, 100, -1))
+	assert.Equal(t, "6", mr.HGet(key, "100"))
+
+	// 3. 有缓存的帖子直接从 Redis 读，不查 MySQL
+	counts, err := s.GetPostCommentCounts(ctx, []int64{100})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{100: 6}, counts)
+}
+
+// TestSetCommentCounts 写回统计结果时不覆盖已有的计数，并给 hash 加上过期时间
+func TestSetCommentCounts(t *testing.T) {
+	ctx := context.Background()
+	s, mr := setupMiniRedis(t)
+	key := getRedisKey(KeyPostCommentCountHash)
+
+	// 别的请求先写回了 5，之后又加了一条评论
+	mr.HSet(key, "100", "6")
+	cached, err := setCommentCountsScript.Run(ctx, s.RDB, []string{key}, 3600, 100, 5, 200, 3).StringSlice()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"6", "3"}, cached)
+	assert.Equal(t, "6", mr.HGet(key, "100"))
+	assert.Equal(t, time.Hour, mr.TTL(key))
+
+	// 已经有过期时间的不会被续期
+	mr.SetTTL(key, time.Minute)
+	_, err = setCommentCountsScript.Run(ctx, s.RDB, []string{key}, 3600, 300, 1).StringSlice()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, mr.TTL(key))
+}
+
+// TestListCommentRepliesLimit 每条评论只取前 limit 条回复，只生成 SQL，不连数据库
+func TestListCommentRepliesLimit(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+	var sql string
+	var vars []interface{}
+	assert.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		sql, vars = tx.Statement.SQL.String(), tx.Statement.Vars
+	}))
+	s := NewStore(db, nil)
+
+	_, err = s.ListCommentReplies(context.Background(), []int64{1, 2}, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY comment_id) AS rn FROM `comment` WHERE parent_id IN (?,?)) AS c WHERE rn <= ? ORDER BY comment_id", sql)
+	assert.Equal(t, []interface{}{int64(1), int64(2), 5}, vars)
+}
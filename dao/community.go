@@ -0,0 +1,139 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gin-api-scaffold-v1/models"
+)
+
+var (
+	ErrorCommunityExist     = errors.New("社区已存在")
+	ErrorCommunityNotFound  = errors.New("社区不存在")
+	ErrorNotCommunityMember = errors.New("不是社区成员")
+)
+
+// CheckCommunityExist 检查社区名是否已被占用
+func (s *Store) CheckCommunityExist(ctx context.Context, name string) error {
+	var count int64
+	if err := s.DB.WithContext(ctx).Model(&models.Community{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrorCommunityExist
+	}
+	return nil
+}
+
+// InsertCommunity 创建社区，创建者同时成为社区的 owner
+func (s *Store) InsertCommunity(ctx context.Context, community *models.Community) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		community.MemberCount = 1
+		if err := tx.Create(community).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CommunityMember{
+			CommunityID: community.CommunityID,
+			UserID:      community.CreatorID,
+			Role:        models.CommunityRoleOwner,
+		}).Error
+	})
+}
+
+// GetCommunityByID 根据 community_id 查社区
+func (s *Store) GetCommunityByID(ctx context.Context, communityID int64) (community *models.Community, err error) {
+	community = new(models.Community)
+	err = s.DB.WithContext(ctx).Where("community_id = ?", communityID).First(community).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorCommunityNotFound
+	}
+	return
+}
+
+// ListCommunities 分页列出社区 (按创建时间倒序)
+func (s *Store) ListCommunities(ctx context.Context, offset, limit int) (communities []models.Community, total int64, err error) {
+	if err = s.DB.WithContext(ctx).Model(&models.Community{}).Count(&total).Error; err != nil {
+		return
+	}
+	err = s.DB.WithContext(ctx).Order("id DESC").Offset(offset).Limit(limit).Find(&communities).Error
+	return
+}
+
+// GetCommunityMember 查用户在社区里的身份，不是成员返回 ErrorNotCommunityMember
+func (s *Store) GetCommunityMember(ctx context.Context, communityID, userID int64) (member *models.CommunityMember, err error) {
+	member = new(models.CommunityMember)
+	err = s.DB.WithContext(ctx).Where("community_id = ? AND user_id = ?", communityID, userID).First(member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorNotCommunityMember
+	}
+	return
+}
+
+// AddCommunityMember 加入社区，已经是成员就忽略
+func (s *Store) AddCommunityMember(ctx context.Context, communityID, userID int64) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.CommunityMember{
+			CommunityID: communityID,
+			UserID:      userID,
+			Role:        models.CommunityRoleMember,
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&models.Community{}).Where("community_id = ?", communityID).
+			Update("member_count", gorm.Expr("member_count + 1")).Error
+	})
+}
+
+// RemoveCommunityMember 退出社区，本来就不是成员就忽略
+func (s *Store) RemoveCommunityMember(ctx context.Context, communityID, userID int64) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("community_id = ? AND user_id = ?", communityID, userID).Delete(&models.CommunityMember{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&models.Community{}).Where("community_id = ? AND member_count > 0", communityID).
+			Update("member_count", gorm.Expr("member_count - 1")).Error
+	})
+}
+
+// SetCommunityMemberRole 修改成员在社区里的身份
+func (s *Store) SetCommunityMemberRole(ctx context.Context, communityID, userID int64, role string) error {
+	res := s.DB.WithContext(ctx).Model(&models.CommunityMember{}).
+		Where("community_id = ? AND user_id = ?", communityID, userID).
+		Update("role", role)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		// 身份本来就是 role 时 MySQL 也返回 0，再查一次区分是不是成员
+		if _, err := s.GetCommunityMember(ctx, communityID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListCommunityModerators 列出社区的创建者和版主
+func (s *Store) ListCommunityModerators(ctx context.Context, communityID int64) (moderators []*models.ResCommunityMember, err error) {
+	err = s.DB.WithContext(ctx).Table("community_member AS m").
+		Select("m.user_id, u.username, m.role").
+		Joins("JOIN `user` AS u ON u.user_id = m.user_id").
+		Where("m.community_id = ? AND m.role IN ?", communityID,
+			[]string{models.CommunityRoleOwner, models.CommunityRoleModerator}).
+		Order("m.id").
+		Scan(&moderators).Error
+	return
+}
+
+// GetCommunitiesByIDs 批量查社区 (列表接口里补社区名)
+func (s *Store) GetCommunitiesByIDs(ctx context.Context, communityIDs []int64) (communities []models.Community, err error) {
+	if len(communityIDs) == 0 {
+		return nil, nil
+	}
+	err = s.DB.WithContext(ctx).Where("community_id IN ?", communityIDs).Find(&communities).Error
+	return
+}
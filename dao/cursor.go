@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+
+	"gin-api-scaffold-v1/common"
+)
+
+// CursorQuery 游标分页的查询条件
+// 按 IDColumn (雪花 ID，唯一且随时间递增) 排序；设置了 KeyColumn 时先按 KeyColumn 排序，ID 用来打破并列
+type CursorQuery struct {
+	IDColumn  string
+	KeyColumn string
+	Desc      bool
+	Cursor    *common.Cursor // nil 表示第一页
+	Limit     int            // 每页数量，实际会多取一条，交给 common.NewCursorPage 判断有没有下一页
+}
+
+// Scope 用法：DB.Where(...).Scopes(q.Scope).Find(&list)
+func (q CursorQuery) Scope(db *gorm.DB) *gorm.DB {
+	op, dir := ">", " ASC"
+	if q.Desc {
+		op, dir = "<", " DESC"
+	}
+	if q.Cursor != nil {
+		if q.KeyColumn == "" {
+			db = db.Where(q.IDColumn+" "+op+" ?", q.Cursor.ID)
+		} else {
+			db = db.Where("("+q.KeyColumn+", "+q.IDColumn+") "+op+" (?, ?)", q.Cursor.Key, q.Cursor.ID)
+		}
+	}
+	if q.KeyColumn != "" {
+		db = db.Order(q.KeyColumn + dir)
+	}
+	return db.Order(q.IDColumn + dir).Limit(q.Limit + 1)
+}
@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/models"
+)
+
+// TestCursorQuery 只生成 SQL，不连数据库
+func TestCursorQuery(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+
+	sqlOf := func(q CursorQuery) string {
+		var comments []models.Comment
+		return db.Where("post_id = ?", 1).Scopes(q.Scope).Find(&comments).Statement.SQL.String()
+	}
+
+	// 第一页：没有游标条件
+	assert.Equal(t, "SELECT * FROM `comment` WHERE post_id = ? ORDER BY comment_id ASC LIMIT ?",
+		sqlOf(CursorQuery{IDColumn: "comment_id", Limit: 20}))
+
+	// 按 ID 倒序翻页
+	assert.Equal(t, "SELECT * FROM `comment` WHERE post_id = ? AND comment_id < ? ORDER BY comment_id DESC LIMIT ?",
+		sqlOf(CursorQuery{IDColumn: "comment_id", Desc: true, Cursor: &common.Cursor{ID: 9}, Limit: 20}))
+
+	// 按其他列排序，ID 打破并列
+	assert.Equal(t, "SELECT * FROM `comment` WHERE post_id = ? AND (create_time, comment_id) > (?, ?) ORDER BY create_time ASC,comment_id ASC LIMIT ?",
+		sqlOf(CursorQuery{IDColumn: "comment_id", KeyColumn: "create_time", Cursor: &common.Cursor{Key: "2026-03-01", ID: 9}, Limit: 20}))
+}
@@ -0,0 +1,45 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// consumeEmailTokenScript 比对并删除，保证同一个链接只能用一次
+// KEYS[1]: email token key  ARGV[1]: 链接里的 jti
+// 返回值: 1 核销成功 / 0 不存在或者已经被新链接替换
+var consumeEmailTokenScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	return 1
+end
+return 0
+`)
+
+// SaveEmailToken 记录用户当前有效的邮件链接 jti，之前发过的同类链接随之作废
+func (s *Store) SaveEmailToken(ctx context.Context, tokenType string, userID int64, jti string, expiration time.Duration) error {
+	return s.RDB.Set(ctx, getEmailTokenKey(tokenType, userID), jti, expiration).Err()
+}
+
+// ConsumeEmailToken 核销邮件链接，返回 false 说明链接已经用过、过期或者不是最新的一封
+func (s *Store) ConsumeEmailToken(ctx context.Context, tokenType string, userID int64, jti string) (bool, error) {
+	n, err := consumeEmailTokenScript.Run(ctx, s.RDB,
+		[]string{getEmailTokenKey(tokenType, userID)}, jti).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// AllowMailSend 同一邮箱同一类邮件在 interval 内只发一封，返回 false 说明还在冷却
+func (s *Store) AllowMailSend(ctx context.Context, tokenType, email string, interval time.Duration) (bool, error) {
+	key := getRedisKey(KeyMailThrottlePrefix + tokenType + ":" + email)
+	return s.RDB.SetNX(ctx, key, 1, interval).Result()
+}
+
+func getEmailTokenKey(tokenType string, userID int64) string {
+	return getRedisKey(fmt.Sprintf("%s%s:%d", KeyEmailTokenPrefix, tokenType, userID))
+}
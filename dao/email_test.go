@@ -0,0 +1,51 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConsumeEmailToken 链接只能用一次，重新发送后旧链接作废
+func TestConsumeEmailToken(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupMiniRedis(t)
+
+	assert.NoError(t, s.SaveEmailToken(ctx, "verify_email", 1, "jti-1", time.Hour))
+	assert.NoError(t, s.SaveEmailToken(ctx, "verify_email", 1, "jti-2", time.Hour))
+
+	ok, err := s.ConsumeEmailToken(ctx, "verify_email", 1, "jti-1")
+	assert.NoError(t, err)
+	assert.False(t, ok, "旧链接已被新链接替换")
+
+	ok, err = s.ConsumeEmailToken(ctx, "reset_password", 1, "jti-2")
+	assert.NoError(t, err)
+	assert.False(t, ok, "类型不同不能混用")
+
+	ok, err = s.ConsumeEmailToken(ctx, "verify_email", 1, "jti-2")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = s.ConsumeEmailToken(ctx, "verify_email", 1, "jti-2")
+	assert.NoError(t, err)
+	assert.False(t, ok, "第二次使用失败")
+}
+
+// TestAllowMailSend 冷却期内不重复发信
+func TestAllowMailSend(t *testing.T) {
+	ctx := context.Background()
+	s, mr := setupMiniRedis(t)
+
+	ok, _ := s.AllowMailSend(ctx, "reset_password", "a@example.com", time.Minute)
+	assert.True(t, ok)
+	ok, _ = s.AllowMailSend(ctx, "reset_password", "a@example.com", time.Minute)
+	assert.False(t, ok)
+	ok, _ = s.AllowMailSend(ctx, "verify_email", "a@example.com", time.Minute)
+	assert.True(t, ok, "不同类型的邮件分开计算")
+
+	mr.FastForward(time.Minute)
+	ok, _ = s.AllowMailSend(ctx, "reset_password", "a@example.com", time.Minute)
+	assert.True(t, ok)
+}
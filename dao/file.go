@@ -0,0 +1,28 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gin-api-scaffold-v1/models"
+)
+
+var ErrorFileNotFound = errors.New("文件不存在")
+
+// GetFileByHash 按内容哈希查文件 (上传去重)
+func (s *Store) GetFileByHash(ctx context.Context, hash string) (file *models.File, err error) {
+	file = new(models.File)
+	err = s.DB.WithContext(ctx).Where("hash = ?", hash).First(file).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorFileNotFound
+	}
+	return
+}
+
+// InsertFile 记录上传的文件，同样内容的文件已经有了就忽略 (两个人同时上传同一个文件)
+func (s *Store) InsertFile(ctx context.Context, file *models.File) error {
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(file).Error
+}
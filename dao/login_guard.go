@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LockoutPolicy 登录失败锁定策略
+type LockoutPolicy struct {
+	MaxAttempts int64         // 计数窗口内失败多少次开始锁定
+	Window      time.Duration // 失败计数窗口
+	LockBase    time.Duration // 首次锁定时长，之后每多失败一次翻倍
+	LockMax     time.Duration // 最长锁定时长
+}
+
+// 登录限制的两个维度：按用户名 (防止针对某个账号爆破) 和按 IP (防止撞库)
+const (
+	LoginSubjectUser = "user:"
+	LoginSubjectIP   = "ip:"
+)
+
+// recordLoginFailureScript 失败次数 +1，超过阈值后按指数退避设置锁
+// KEYS[1]: 失败计数 key  KEYS[2]: 锁 key
+// ARGV[1]: 阈值  ARGV[2]: 计数窗口(毫秒)  ARGV[3]: 首次锁定(毫秒)  ARGV[4]: 最长锁定(毫秒)
+// 返回值: {当前失败次数, 锁定时长(毫秒)，没锁定为 0}
+var recordLoginFailureScript = redis.NewScript(`
+local fails = redis.call('INCR', KEYS[1])
+if fails == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+local threshold = tonumber(ARGV[1])
+if fails < threshold then
+	return {fails, 0}
+end
+local lock = math.floor(tonumber(ARGV[3]) * math.pow(2, fails - threshold))
+local max = tonumber(ARGV[4])
+if lock > max then
+	lock = max
+end
+-- PX 不接受 0，锁定时长至少 1 毫秒
+if lock < 1 then
+	lock = 1
+end
+redis.call('SET', KEYS[2], fails, 'PX', lock)
+-- 锁定期间计数不能先过期，否则解锁后又从 1 开始算，退避就失效了
+if redis.call('PTTL', KEYS[1]) < lock then
+	redis.call('PEXPIRE', KEYS[1], lock + tonumber(ARGV[2]))
+end
+return {fails, lock}
+`)
+
+// RecordLoginFailure 记录一次登录失败，返回当前失败次数和本次触发的锁定时长 (没锁定为 0)
+func (s *Store) RecordLoginFailure(ctx context.Context, subject, id string, policy LockoutPolicy) (fails int64, lock time.Duration, err error) {
+	keys := []string{
+		getRedisKey(KeyLoginFailPrefix + subject + id),
+		getRedisKey(KeyLoginLockPrefix + subject + id),
+	}
+	res, err := recordLoginFailureScript.Run(ctx, s.RDB, keys,
+		policy.MaxAttempts, policy.Window.Milliseconds(), policy.LockBase.Milliseconds(), policy.LockMax.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return res[0], time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// GetLoginLock 查询剩余锁定时长，没被锁定返回 0
+func (s *Store) GetLoginLock(ctx context.Context, subject, id string) (time.Duration, error) {
+	ttl, err := s.RDB.PTTL(ctx, getRedisKey(KeyLoginLockPrefix+subject+id)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// key 不存在返回 -2，没设置过期时间返回 -1，都当作没锁定
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// ClearLoginFailures 清除失败计数和锁 (登录成功或管理员解锁)
+func (s *Store) ClearLoginFailures(ctx context.Context, subject, id string) error {
+	return s.RDB.Del(ctx,
+		getRedisKey(KeyLoginFailPrefix+subject+id),
+		getRedisKey(KeyLoginLockPrefix+subject+id),
+	).Err()
+}
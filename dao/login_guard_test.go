@@ -0,0 +1,64 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecordLoginFailure 测试失败计数、指数退避以及最长锁定时长
+func TestRecordLoginFailure(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupMiniRedis(t)
+	policy := LockoutPolicy{
+		MaxAttempts: 3,
+		Window:      15 * time.Minute,
+		LockBase:    time.Minute,
+		LockMax:     5 * time.Minute,
+	}
+
+	// 1. 前两次失败不锁定
+	for i := 1; i <= 2; i++ {
+		fails, lock, err := s.RecordLoginFailure(ctx, LoginSubjectUser, "alice", policy)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(i), fails)
+		assert.Zero(t, lock)
+	}
+	ttl, err := s.GetLoginLock(ctx, LoginSubjectUser, "alice")
+	assert.NoError(t, err)
+	assert.Zero(t, ttl)
+
+	// 2. 第 3 次开始锁定：1 分钟、2 分钟、4 分钟，然后封顶 5 分钟
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute} {
+		_, lock, err := s.RecordLoginFailure(ctx, LoginSubjectUser, "alice", policy)
+		assert.NoError(t, err)
+		assert.Equal(t, want, lock)
+	}
+	ttl, err = s.GetLoginLock(ctx, LoginSubjectUser, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, ttl)
+
+	// 3. 其他维度互不影响
+	ttl, _ = s.GetLoginLock(ctx, LoginSubjectIP, "alice")
+	assert.Zero(t, ttl)
+
+	// 4. 解锁后计数从头开始
+	assert.NoError(t, s.ClearLoginFailures(ctx, LoginSubjectUser, "alice"))
+	ttl, _ = s.GetLoginLock(ctx, LoginSubjectUser, "alice")
+	assert.Zero(t, ttl)
+	fails, _, _ := s.RecordLoginFailure(ctx, LoginSubjectUser, "alice", policy)
+	assert.Equal(t, int64(1), fails)
+}
+
+// TestRecordLoginFailureZeroLock 锁定时长算出来是 0 时至少锁 1 毫秒，不能让 SET PX 0 报错
+func TestRecordLoginFailureZeroLock(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupMiniRedis(t)
+	policy := LockoutPolicy{MaxAttempts: 1, Window: time.Minute}
+
+	_, lock, err := s.RecordLoginFailure(ctx, LoginSubjectUser, "alice", policy)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Millisecond, lock)
+}
@@ -0,0 +1,109 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"gin-api-scaffold-v1/models"
+)
+
+var ErrorMFANotFound = errors.New("未绑定两步验证")
+
+// MFARepository 两步验证设置和恢复码的读写 (user_mfa / user_recovery_code 表)
+// 防重放的一次性标记存在 Redis 里，不在这个接口里 (见 MarkTOTPStepUsed / MarkTokenUsed)
+type MFARepository interface {
+	// Get 查询用户的两步验证设置，没有记录时返回 ErrorMFANotFound
+	Get(ctx context.Context, userID int64) (*models.UserMFA, error)
+	// SavePendingSecret 保存待确认的密钥，没有记录就新建
+	SavePendingSecret(ctx context.Context, userID int64, secret string) error
+	// Enable 确认绑定：待确认密钥转正，同时替换恢复码
+	Enable(ctx context.Context, userID int64, secret string, recoveryCodeHashes []string) error
+	// ReplaceRecoveryCodes 作废旧的恢复码，换成新的一批
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error
+	// ConsumeRecoveryCode 使用一个恢复码，不存在或已用过返回 false；并发下同一个恢复码只能成功一次
+	ConsumeRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error)
+}
+
+// GormMFARepository 基于 GORM 的 MFARepository
+type GormMFARepository struct {
+	DB *gorm.DB
+}
+
+// Get 查询用户的两步验证设置
+func (r GormMFARepository) Get(ctx context.Context, userID int64) (mfa *models.UserMFA, err error) {
+	mfa = new(models.UserMFA)
+	err = r.DB.WithContext(ctx).Where("user_id = ?", userID).First(mfa).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorMFANotFound
+	}
+	return
+}
+
+// SavePendingSecret 保存待确认的密钥，没有记录就新建
+func (r GormMFARepository) SavePendingSecret(ctx context.Context, userID int64, secret string) error {
+	mfa, err := r.Get(ctx, userID)
+	if errors.Is(err, ErrorMFANotFound) {
+		return r.DB.WithContext(ctx).Create(&models.UserMFA{UserID: userID, PendingSecret: secret}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return r.DB.WithContext(ctx).Model(mfa).Update("pending_secret", secret).Error
+}
+
+// Enable 确认绑定：待确认密钥转正，同时替换恢复码 (同一个事务)
+func (r GormMFARepository) Enable(ctx context.Context, userID int64, secret string, recoveryCodeHashes []string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserMFA{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"secret":         secret,
+			"pending_secret": "",
+			"enabled":        true,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, recoveryCodeHashes)
+	})
+}
+
+// ReplaceRecoveryCodes 作废旧的恢复码，换成新的一批
+func (r GormMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, hashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID int64, hashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]models.UserRecoveryCode, 0, len(hashes))
+	for _, h := range hashes {
+		codes = append(codes, models.UserRecoveryCode{UserID: userID, CodeHash: h})
+	}
+	return tx.Create(&codes).Error
+}
+
+// ConsumeRecoveryCode 使用一个恢复码，返回是否成功 (不存在或已用过返回 false)
+// 用条件更新保证并发下同一个恢复码只能成功一次
+func (r GormMFARepository) ConsumeRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error) {
+	res := r.DB.WithContext(ctx).Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+// MarkTOTPStepUsed 记录某个时间片的验证码已经用过，返回 false 说明是重放
+func (s *Store) MarkTOTPStepUsed(ctx context.Context, userID, step int64, expiration time.Duration) (bool, error) {
+	key := getRedisKey(fmt.Sprintf("%s%d:%d", KeyMFAUsedStepPrefix, userID, step))
+	return s.RDB.SetNX(ctx, key, 1, expiration).Result()
+}
+
+// MarkTokenUsed 一次性 Token 标记为已使用，返回 false 说明已经被用过了
+func (s *Store) MarkTokenUsed(ctx context.Context, jti string, expiration time.Duration) (bool, error) {
+	return s.RDB.SetNX(ctx, getRedisKey(KeyTokenUsedPrefix+jti), 1, expiration).Result()
+}
@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gin-api-scaffold-v1/models"
+)
+
+// MemoryMFARepository 存在内存里的 MFARepository，不持久化，用于单元测试和没有 MySQL 的本地开发
+type MemoryMFARepository struct {
+	mu    sync.Mutex
+	mfas  map[int64]models.UserMFA
+	codes map[int64]map[string]bool // user_id -> 恢复码哈希 -> 是否已使用
+}
+
+func NewMemoryMFARepository() *MemoryMFARepository {
+	return &MemoryMFARepository{
+		mfas:  make(map[int64]models.UserMFA),
+		codes: make(map[int64]map[string]bool),
+	}
+}
+
+func (r *MemoryMFARepository) Get(_ context.Context, userID int64) (*models.UserMFA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mfa, ok := r.mfas[userID]
+	if !ok {
+		return nil, ErrorMFANotFound
+	}
+	return &mfa, nil
+}
+
+func (r *MemoryMFARepository) SavePendingSecret(_ context.Context, userID int64, secret string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	mfa, ok := r.mfas[userID]
+	if !ok {
+		mfa = models.UserMFA{ID: int64(len(r.mfas) + 1), UserID: userID, CreateTime: now}
+	}
+	mfa.PendingSecret, mfa.UpdateTime = secret, now
+	r.mfas[userID] = mfa
+	return nil
+}
+
+// Enable 和 GORM 实现一样，没有先保存过待确认密钥时不会新建记录
+func (r *MemoryMFARepository) Enable(_ context.Context, userID int64, secret string, recoveryCodeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if mfa, ok := r.mfas[userID]; ok {
+		mfa.Secret, mfa.PendingSecret, mfa.Enabled, mfa.UpdateTime = secret, "", true, time.Now()
+		r.mfas[userID] = mfa
+	}
+	r.replaceRecoveryCodes(userID, recoveryCodeHashes)
+	return nil
+}
+
+func (r *MemoryMFARepository) ReplaceRecoveryCodes(_ context.Context, userID int64, hashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replaceRecoveryCodes(userID, hashes)
+	return nil
+}
+
+func (r *MemoryMFARepository) replaceRecoveryCodes(userID int64, hashes []string) {
+	codes := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		codes[h] = false
+	}
+	r.codes[userID] = codes
+}
+
+func (r *MemoryMFARepository) ConsumeRecoveryCode(_ context.Context, userID int64, hash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	used, ok := r.codes[userID][hash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[userID][hash] = true
+	return true, nil
+}
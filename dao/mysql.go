@@ -1,24 +1,65 @@
 package dao
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 
 	"github.com/spf13/viper" // 👈 引入 viper
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-)
+	"gorm.io/gorm/logger"
 
-var DB *gorm.DB
+	"gin-api-scaffold-v1/migrations"
+	"gin-api-scaffold-v1/pkg/migrate"
+)
 
-func InitMySQL() (err error) {
+// OpenMySQL 按 mysql.* 配置建立连接
+func OpenMySQL(cfg *viper.Viper) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		viper.GetString("mysql.user"),
-		viper.GetString("mysql.password"),
-		viper.GetString("mysql.host"),
-		viper.GetInt("mysql.port"),
-		viper.GetString("mysql.dbname"),
+		cfg.GetString("mysql.user"),
+		cfg.GetString("mysql.password"),
+		cfg.GetString("mysql.host"),
+		cfg.GetInt("mysql.port"),
+		cfg.GetString("mysql.dbname"),
 	)
 
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	return err // ✅ 直接返回连接结果，不要去建表
+	return gorm.Open(mysql.Open(dsn), &gorm.Config{}) // ✅ 直接返回连接结果，不要去建表 (表结构由 migrations 目录里的迁移脚本管理)
+}
+
+// ErrorNoMySQL 没有连 MySQL (app.WithDB(nil))，又用到了只有 MySQL 实现的功能
+var ErrorNoMySQL = errors.New("未连接 MySQL")
+
+// NoMySQL 不连 MySQL 时代替 nil 的连接，每条 SQL 都返回 ErrorNoMySQL
+// 内存实现目前只有用户、角色、两步验证 (logic.NewMemoryRepositories)，
+// 社区、帖子、评论、API Key、第三方登录、文件上传、search.driver=mysql 的搜索还是直接用 GORM，
+// 没有 MySQL 时这些接口返回错误，而不是空指针 panic
+func NoMySQL() *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sql.OpenDB(noMySQLConnector{}),
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		// 不会发生：不查版本、不 ping，Open 时不会碰到连接
+		panic(err)
+	}
+	return db
+}
+
+// noMySQLConnector 建立连接时直接返回 ErrorNoMySQL
+type noMySQLConnector struct{}
+
+func (noMySQLConnector) Connect(context.Context) (driver.Conn, error) { return nil, ErrorNoMySQL }
+func (c noMySQLConnector) Driver() driver.Driver                      { return c }
+func (noMySQLConnector) Open(string) (driver.Conn, error)             { return nil, ErrorNoMySQL }
+
+// NewMigrator 在 db 这个连接上执行 migrations 目录里的迁移脚本
+func NewMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, migrations.FS)
 }
@@ -0,0 +1,69 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"gin-api-scaffold-v1/models"
+)
+
+var (
+	ErrorIdentityNotFound  = errors.New("第三方身份未绑定")
+	ErrorOIDCStateNotFound = errors.New("第三方登录状态不存在或已过期")
+)
+
+// GetUserIdentity 按身份提供方 + sub 查绑定关系
+func (s *Store) GetUserIdentity(ctx context.Context, provider, subject string) (identity *models.UserIdentity, err error) {
+	identity = new(models.UserIdentity)
+	err = s.DB.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorIdentityNotFound
+	}
+	return
+}
+
+// InsertUserIdentity 给已有用户绑定第三方身份
+func (s *Store) InsertUserIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	return s.DB.WithContext(ctx).Create(identity).Error
+}
+
+// InsertUserWithIdentity 第三方首次登录：创建用户并绑定身份 (同一个事务)
+func (s *Store) InsertUserWithIdentity(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.UserID
+		return tx.Create(identity).Error
+	})
+}
+
+// SaveOIDCState 保存第三方登录状态
+func (s *Store) SaveOIDCState(ctx context.Context, state string, v *models.OIDCState, expiration time.Duration) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.RDB.Set(ctx, getRedisKey(KeyOIDCStatePrefix+state), b, expiration).Err()
+}
+
+// TakeOIDCState 取出并删除第三方登录状态，同一个 state 只能回调一次
+func (s *Store) TakeOIDCState(ctx context.Context, state string) (*models.OIDCState, error) {
+	b, err := s.RDB.GetDel(ctx, getRedisKey(KeyOIDCStatePrefix+state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrorOIDCStateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	v := new(models.OIDCState)
+	if err = json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
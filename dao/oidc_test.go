@@ -0,0 +1,27 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/models"
+)
+
+// TestTakeOIDCState state 只能取一次
+func TestTakeOIDCState(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupMiniRedis(t)
+
+	st := &models.OIDCState{Provider: "google", Verifier: "v", Nonce: "n"}
+	assert.NoError(t, s.SaveOIDCState(ctx, "s1", st, time.Minute))
+
+	got, err := s.TakeOIDCState(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, st, got)
+
+	_, err = s.TakeOIDCState(ctx, "s1")
+	assert.ErrorIs(t, err, ErrorOIDCStateNotFound)
+}
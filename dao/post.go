@@ -0,0 +1,93 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gin-api-scaffold-v1/models"
+)
+
+var ErrorPostNotFound = errors.New("帖子不存在")
+
+// InsertPost 保存新帖子
+func (s *Store) InsertPost(ctx context.Context, post *models.Post) error {
+	return s.DB.WithContext(ctx).Create(post).Error
+}
+
+// GetPostByID 根据 post_id 查帖子
+func (s *Store) GetPostByID(ctx context.Context, postID int64) (post *models.Post, err error) {
+	post = new(models.Post)
+	err = s.DB.WithContext(ctx).Where("post_id = ?", postID).First(post).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorPostNotFound
+	}
+	return
+}
+
+// UpdatePost 修改帖子的标题和内容
+func (s *Store) UpdatePost(ctx context.Context, postID int64, title, content string) error {
+	res := s.DB.WithContext(ctx).Model(&models.Post{}).Where("post_id = ?", postID).
+		Updates(map[string]interface{}{"title": title, "content": content})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		// 内容没变时 MySQL 也返回 0，再查一次确认帖子还在
+		_, err := s.GetPostByID(ctx, postID)
+		return err
+	}
+	return nil
+}
+
+// DeletePost 删除帖子，帖子下面的评论一起删除
+func (s *Store) DeletePost(ctx context.Context, postID int64) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("post_id = ?", postID).Delete(&models.Post{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrorPostNotFound
+		}
+		return tx.Where("post_id = ?", postID).Delete(&models.Comment{}).Error
+	})
+}
+
+// GetPostsByIDs 批量查帖子 (列表接口先从 Redis 取 ID，再到这里补全内容)，返回顺序不保证
+func (s *Store) GetPostsByIDs(ctx context.Context, postIDs []int64) (posts []models.Post, err error) {
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+	err = s.DB.WithContext(ctx).Where("post_id IN ?", postIDs).Find(&posts).Error
+	return
+}
+
+// ArchivePostVotes 投票期结束，把投票记录和票数写进 MySQL
+func (s *Store) ArchivePostVotes(ctx context.Context, postID int64, votes []models.PostVote) error {
+	var ups, downs int64
+	for _, v := range votes {
+		if v.Direction > 0 {
+			ups++
+		} else {
+			downs++
+		}
+	}
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(votes) > 0 {
+			// 重试时可能已经写过一部分，按主键覆盖
+			err := tx.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"direction"})}).
+				CreateInBatches(votes, 500).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Post{}).Where("post_id = ?", postID).Updates(map[string]interface{}{
+			"up_votes":    ups,
+			"down_votes":  downs,
+			"vote_closed": true,
+		}).Error
+	})
+}
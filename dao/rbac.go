@@ -0,0 +1,104 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gin-api-scaffold-v1/models"
+)
+
+var ErrorRoleNotFound = errors.New("角色不存在")
+
+// RoleRepository 角色、权限和用户角色的读写 (role / permission / role_permission / user_role 表)
+// 角色权限的 Redis 缓存不在这个接口里 (见 GetCachedRolePermissions / CacheRolePermissions)
+type RoleRepository interface {
+	// GetRoleByName 根据名字查角色，不存在时返回 ErrorRoleNotFound
+	GetRoleByName(ctx context.Context, name string) (*models.Role, error)
+	// GetUserRoles 查询用户拥有的角色名，按名字排序
+	GetUserRoles(ctx context.Context, userID int64) ([]string, error)
+	// GetRolePermissions 查询角色拥有的权限点
+	GetRolePermissions(ctx context.Context, roleName string) ([]string, error)
+	// AddUserRole 给用户添加角色，已经有了就忽略
+	AddUserRole(ctx context.Context, userID, roleID int64) error
+	// RemoveUserRole 移除用户的角色，本来就没有就忽略
+	RemoveUserRole(ctx context.Context, userID, roleID int64) error
+}
+
+// GormRoleRepository 基于 GORM 的 RoleRepository
+type GormRoleRepository struct {
+	DB *gorm.DB
+}
+
+// GetRoleByName 根据名字查角色
+func (r GormRoleRepository) GetRoleByName(ctx context.Context, name string) (role *models.Role, err error) {
+	role = new(models.Role)
+	err = r.DB.WithContext(ctx).Where("name = ?", name).First(role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrorRoleNotFound
+	}
+	return
+}
+
+// GetUserRoles 查询用户拥有的角色名
+func (r GormRoleRepository) GetUserRoles(ctx context.Context, userID int64) (roles []string, err error) {
+	err = r.DB.WithContext(ctx).Model(&models.Role{}).
+		Joins("JOIN user_role ON user_role.role_id = role.id").
+		Where("user_role.user_id = ?", userID).
+		Order("role.name").
+		Pluck("role.name", &roles).Error
+	return
+}
+
+// GetRolePermissions 查询角色拥有的权限点 (直接查库，业务上请走带缓存的 logic 层)
+func (r GormRoleRepository) GetRolePermissions(ctx context.Context, roleName string) (permissions []string, err error) {
+	err = r.DB.WithContext(ctx).Model(&models.Permission{}).
+		Joins("JOIN role_permission ON role_permission.permission_id = permission.id").
+		Joins("JOIN role ON role.id = role_permission.role_id").
+		Where("role.name = ?", roleName).
+		Pluck("permission.code", &permissions).Error
+	return
+}
+
+// AddUserRole 给用户添加角色，已经有了就忽略
+func (r GormRoleRepository) AddUserRole(ctx context.Context, userID, roleID int64) error {
+	return r.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserRole{UserID: userID, RoleID: roleID}).Error
+}
+
+// RemoveUserRole 移除用户的角色
+func (r GormRoleRepository) RemoveUserRole(ctx context.Context, userID, roleID int64) error {
+	return r.DB.WithContext(ctx).Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{}).Error
+}
+
+// GetCachedRolePermissions 从 Redis 读角色权限缓存，hit 为 false 表示没有缓存
+func (s *Store) GetCachedRolePermissions(ctx context.Context, roleName string) (permissions []string, hit bool, err error) {
+	b, err := s.RDB.Get(ctx, getRedisKey(KeyRolePermissionsPrefix+roleName)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if err = json.Unmarshal(b, &permissions); err != nil {
+		return nil, false, err
+	}
+	return permissions, true, nil
+}
+
+// CacheRolePermissions 缓存角色权限 (没有任何权限的角色也缓存，避免每次都查库)
+func (s *Store) CacheRolePermissions(ctx context.Context, roleName string, permissions []string, expiration time.Duration) error {
+	if permissions == nil {
+		permissions = []string{}
+	}
+	b, err := json.Marshal(permissions)
+	if err != nil {
+		return err
+	}
+	return s.RDB.Set(ctx, getRedisKey(KeyRolePermissionsPrefix+roleName), b, expiration).Err()
+}
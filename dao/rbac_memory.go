@@ -0,0 +1,80 @@
+package dao
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"gin-api-scaffold-v1/models"
+)
+
+// MemoryRoleRepository 存在内存里的 RoleRepository，不持久化，用于单元测试和没有 MySQL 的本地开发
+// 新建时带上和迁移脚本一样的内置角色和权限 (见 migrations/0005_create_rbac.up.sql、0009_create_community.up.sql)
+type MemoryRoleRepository struct {
+	mu          sync.RWMutex
+	roles       []models.Role
+	permissions map[string][]string      // 角色名 -> 权限点
+	userRoles   map[int64]map[int64]bool // user_id -> role_id 集合
+}
+
+func NewMemoryRoleRepository() *MemoryRoleRepository {
+	now := time.Now()
+	return &MemoryRoleRepository{
+		roles: []models.Role{
+			{ID: 1, Name: "admin", Description: "超级管理员", CreateTime: now},
+			{ID: 2, Name: "moderator", Description: "版主", CreateTime: now},
+		},
+		permissions: map[string][]string{
+			"moderator": {"post:delete", "comment:delete", "user:unlock", "community:manage"},
+		},
+		userRoles: make(map[int64]map[int64]bool),
+	}
+}
+
+func (r *MemoryRoleRepository) GetRoleByName(_ context.Context, name string) (*models.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, role := range r.roles {
+		if role.Name == name {
+			return &role, nil
+		}
+	}
+	return nil, ErrorRoleNotFound
+}
+
+func (r *MemoryRoleRepository) GetUserRoles(_ context.Context, userID int64) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var roles []string
+	for _, role := range r.roles {
+		if r.userRoles[userID][role.ID] {
+			roles = append(roles, role.Name)
+		}
+	}
+	slices.Sort(roles)
+	return roles, nil
+}
+
+func (r *MemoryRoleRepository) GetRolePermissions(_ context.Context, roleName string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.permissions[roleName]), nil
+}
+
+func (r *MemoryRoleRepository) AddUserRole(_ context.Context, userID, roleID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.userRoles[userID] == nil {
+		r.userRoles[userID] = make(map[int64]bool)
+	}
+	r.userRoles[userID][roleID] = true
+	return nil
+}
+
+func (r *MemoryRoleRepository) RemoveUserRole(_ context.Context, userID, roleID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.userRoles[userID], roleID)
+	return nil
+}
@@ -0,0 +1,87 @@
+package dao
+
+// Redis Key 统一在这里管理，避免散落在各处的字符串拼写不一致
+// 命名规范：项目前缀 + 业务模块 + 具体含义，用冒号分隔，方便在 redis-cli 里按前缀查看
+const (
+	KeyPrefix = "bluebell:"
+
+	// KeyRefreshFamilyPrefix string 类型，记录某个 Refresh Token family 当前唯一有效的 jti
+	// 完整 key: bluebell:auth:refresh:family:<family_id>
+	KeyRefreshFamilyPrefix = "auth:refresh:family:"
+
+	// KeyTokenDenylistPrefix string 类型，已注销的 Access Token，过期时间与 Token 剩余有效期一致
+	// 完整 key: bluebell:auth:denylist:<jti>
+	KeyTokenDenylistPrefix = "auth:denylist:"
+
+	// KeyTokenGenerationPrefix string 类型，用户当前的 Token 代数，签发时写进 Token，校验时比对
+	// 完整 key: bluebell:auth:gen:<user_id>
+	KeyTokenGenerationPrefix = "auth:gen:"
+
+	// KeyLoginFailPrefix string 类型，登录失败次数，在计数窗口内有效
+	// 完整 key: bluebell:auth:login:fail:user:<username> / bluebell:auth:login:fail:ip:<ip>
+	KeyLoginFailPrefix = "auth:login:fail:"
+
+	// KeyLoginLockPrefix string 类型，存在即表示被锁定，过期时间就是剩余锁定时长
+	// 完整 key: bluebell:auth:login:lock:user:<username> / bluebell:auth:login:lock:ip:<ip>
+	KeyLoginLockPrefix = "auth:login:lock:"
+
+	// KeyMFAUsedStepPrefix string 类型，已经用过的 TOTP 时间片，防止同一个验证码被重放
+	// 完整 key: bluebell:auth:mfa:step:<user_id>:<step>
+	KeyMFAUsedStepPrefix = "auth:mfa:step:"
+
+	// KeyTokenUsedPrefix string 类型，一次性 Token (比如两步验证临时 Token) 已被使用
+	// 完整 key: bluebell:auth:used:<jti>
+	KeyTokenUsedPrefix = "auth:used:"
+
+	// KeyEmailTokenPrefix string 类型，用户当前唯一有效的邮件链接 Token 的 jti，重新发送会覆盖旧的
+	// 完整 key: bluebell:auth:email:<token_type>:<user_id>
+	KeyEmailTokenPrefix = "auth:email:"
+
+	// KeyMailThrottlePrefix string 类型，存在即表示冷却中，防止被人拿来对某个邮箱狂发邮件
+	// 完整 key: bluebell:mail:throttle:<token_type>:<email>
+	KeyMailThrottlePrefix = "mail:throttle:"
+
+	// KeyRolePermissionsPrefix string 类型，角色拥有的权限列表 (JSON 数组) 缓存，修改角色权限时删除
+	// 完整 key: bluebell:rbac:role:<role_name>
+	KeyRolePermissionsPrefix = "rbac:role:"
+
+	// KeyOIDCStatePrefix string 类型，第三方登录进行中的状态 (JSON)，回调时取出并删除
+	// 完整 key: bluebell:auth:oidc:state:<state>
+	KeyOIDCStatePrefix = "auth:oidc:state:"
+
+	// KeySessionPrefix hash 类型，一次登录 (设备) 的信息，过期时间与 Refresh Token 一致
+	// 完整 key: bluebell:auth:session:<family_id>
+	KeySessionPrefix = "auth:session:"
+
+	// KeyUserSessionsPrefix zset 类型，用户的所有会话，member 是 family_id，score 是登录时间
+	// 完整 key: bluebell:auth:sessions:<user_id>
+	KeyUserSessionsPrefix = "auth:sessions:"
+
+	// KeyPostTimeZSet zset 类型，帖子按发帖时间排序，member 是 post_id，score 是发帖时间 (unix 秒)
+	// 完整 key: bluebell:post:time (全站) / bluebell:post:time:<community_id> (单个社区)
+	KeyPostTimeZSet = "post:time"
+
+	// KeyPostScoreZSet zset 类型，帖子按热度排序，member 是 post_id，score 是热度 (见 HotScore)
+	// 完整 key: bluebell:post:score (全站) / bluebell:post:score:<community_id> (单个社区)
+	KeyPostScoreZSet = "post:score"
+
+	// KeyPostVotedPrefix zset 类型，帖子的投票记录，member 是 user_id，score 是 1 (赞成) / -1 (反对)
+	// 投票期结束后写入 MySQL 并删除
+	// 完整 key: bluebell:post:voted:<post_id>
+	KeyPostVotedPrefix = "post:voted:"
+
+	// KeyPostVotingZSet zset 类型，还在投票期内的帖子，member 是 post_id，score 是发帖时间
+	// 投票期结束的帖子由后台任务移出并归档
+	// 完整 key: bluebell:post:voting
+	KeyPostVotingZSet = "post:voting"
+
+	// KeyPostCommentCountHash hash 类型，帖子的评论数 (不含已删除的)，field 是 post_id
+	// 以 MySQL 为准，没有缓存的帖子读取时重新统计；整个 hash 带过期时间，计数有偏差时过期后自动纠正
+	// 完整 key: bluebell:post:comment_count
+	KeyPostCommentCountHash = "post:comment_count"
+)
+
+// getRedisKey 给 key 加上项目前缀
+func getRedisKey(key string) string {
+	return KeyPrefix + key
+}
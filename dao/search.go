@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/search"
+)
+
+// MySQLSearchEngine 基于 MySQL FULLTEXT 索引 (ngram parser) 的搜索，索引见 migrations/0013_add_fulltext_index.up.sql
+// 索引随着表的增删改自动更新，所以 Index / Remove 什么都不做
+type MySQLSearchEngine struct {
+	DB *gorm.DB
+}
+
+func (MySQLSearchEngine) Index(context.Context, search.Document) error { return nil }
+
+func (MySQLSearchEngine) Remove(context.Context, string, int64) error { return nil }
+
+// Search 自然语言模式，结果按相关度排序，相关度相同的新的在前
+func (e MySQLSearchEngine) Search(ctx context.Context, q search.Query) (*search.Result, error) {
+	var (
+		model   interface{}
+		idCol   string
+		against string
+	)
+	switch q.Type {
+	case search.TypePost:
+		model, idCol, against = &models.Post{}, "post_id", "MATCH (title, content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	case search.TypeUser:
+		model, idCol, against = &models.User{}, "user_id", "MATCH (username) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	default:
+		return nil, fmt.Errorf("unknown search type %q", q.Type)
+	}
+
+	// 查总数和查这一页用同样的条件
+	where := func(db *gorm.DB) *gorm.DB {
+		db = db.Model(model).Where(against, q.Text)
+		if q.CommunityID != 0 && q.Type == search.TypePost {
+			db = db.Where("community_id = ?", q.CommunityID)
+		}
+		if !q.Start.IsZero() {
+			db = db.Where("create_time >= ?", q.Start)
+		}
+		if !q.End.IsZero() {
+			db = db.Where("create_time < ?", q.End)
+		}
+		return db
+	}
+
+	res := new(search.Result)
+	if err := e.DB.WithContext(ctx).Scopes(where).Count(&res.Total).Error; err != nil {
+		return nil, err
+	}
+	if res.Total == 0 {
+		return res, nil
+	}
+	err := e.DB.WithContext(ctx).Scopes(where).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  against + " DESC, " + idCol + " DESC",
+			Vars: []interface{}{q.Text},
+		}}).
+		Offset(q.Offset).Limit(q.Limit).
+		Pluck(idCol, &res.IDs).Error
+	return res, err
+}
@@ -0,0 +1,146 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"gin-api-scaffold-v1/models"
+)
+
+var ErrorSessionNotFound = errors.New("会话不存在")
+
+// touchSessionScript 会话存在才更新最后活跃时间，不存在返回 0 (已被踢下线)
+// 直接 HSET 会把已经删除的会话又建出来
+var touchSessionScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'last_seen', ARGV[1])
+return 1
+`)
+
+// SaveSession 保存一次登录，同时加到用户的会话索引里
+func (s *Store) SaveSession(ctx context.Context, sess *models.Session, expiration time.Duration) error {
+	key := getSessionKey(sess.ID)
+	userKey := getUserSessionsKey(sess.UserID)
+	pipe := s.RDB.TxPipeline()
+	pipe.HSet(ctx, key,
+		"user_id", sess.UserID,
+		"device", sess.Device,
+		"user_agent", sess.UserAgent,
+		"ip", sess.IP,
+		"created_at", sess.CreatedAt.Unix(),
+		"last_seen", sess.LastSeenAt.Unix(),
+	)
+	pipe.Expire(ctx, key, expiration)
+	pipe.ZAdd(ctx, userKey, redis.Z{Score: float64(sess.CreatedAt.Unix()), Member: sess.ID})
+	// 索引跟着最新的会话续期，最后一个会话过期后索引也会过期
+	pipe.Expire(ctx, userKey, expiration)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// TouchSession 更新最后活跃时间，返回 false 说明会话已经不存在
+func (s *Store) TouchSession(ctx context.Context, sessionID string, lastSeen time.Time) (bool, error) {
+	n, err := touchSessionScript.Run(ctx, s.RDB,
+		[]string{getSessionKey(sessionID)}, lastSeen.Unix()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// GetSession 查询单个会话
+func (s *Store) GetSession(ctx context.Context, sessionID string) (*models.Session, error) {
+	m, err := s.RDB.HGetAll(ctx, getSessionKey(sessionID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(m) == 0 {
+		return nil, ErrorSessionNotFound
+	}
+	return parseSession(sessionID, m), nil
+}
+
+// ListSessions 列出用户的所有会话 (按登录时间倒序)，顺手清理索引里已经过期的
+func (s *Store) ListSessions(ctx context.Context, userID int64) ([]*models.Session, error) {
+	userKey := getUserSessionsKey(userID)
+	ids, err := s.RDB.ZRevRange(ctx, userKey, 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	pipe := s.RDB.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HGetAll(ctx, getSessionKey(id))
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*models.Session, 0, len(ids))
+	var expired []interface{}
+	for i, cmd := range cmds {
+		m := cmd.Val()
+		if len(m) == 0 {
+			expired = append(expired, ids[i])
+			continue
+		}
+		sessions = append(sessions, parseSession(ids[i], m))
+	}
+	if len(expired) > 0 {
+		s.RDB.ZRem(ctx, userKey, expired...)
+	}
+	return sessions, nil
+}
+
+// DeleteSession 删除一个会话
+func (s *Store) DeleteSession(ctx context.Context, userID int64, sessionID string) error {
+	pipe := s.RDB.TxPipeline()
+	pipe.Del(ctx, getSessionKey(sessionID))
+	pipe.ZRem(ctx, getUserSessionsKey(userID), sessionID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// DeleteUserSessions 删除用户的所有会话，返回被删除的会话 ID
+func (s *Store) DeleteUserSessions(ctx context.Context, userID int64) ([]string, error) {
+	userKey := getUserSessionsKey(userID)
+	ids, err := s.RDB.ZRange(ctx, userKey, 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	keys := []string{userKey}
+	for _, id := range ids {
+		keys = append(keys, getSessionKey(id))
+	}
+	return ids, s.RDB.Del(ctx, keys...).Err()
+}
+
+func parseSession(id string, m map[string]string) *models.Session {
+	userID, _ := strconv.ParseInt(m["user_id"], 10, 64)
+	created, _ := strconv.ParseInt(m["created_at"], 10, 64)
+	lastSeen, _ := strconv.ParseInt(m["last_seen"], 10, 64)
+	return &models.Session{
+		ID:         id,
+		UserID:     userID,
+		Device:     m["device"],
+		UserAgent:  m["user_agent"],
+		IP:         m["ip"],
+		CreatedAt:  time.Unix(created, 0),
+		LastSeenAt: time.Unix(lastSeen, 0),
+	}
+}
+
+func getSessionKey(sessionID string) string {
+	return getRedisKey(KeySessionPrefix + sessionID)
+}
+
+func getUserSessionsKey(userID int64) string {
+	return getRedisKey(KeyUserSessionsPrefix + strconv.FormatInt(userID, 10))
+}
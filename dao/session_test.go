@@ -0,0 +1,43 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/models"
+)
+
+func newSession(id string, userID int64, created time.Time) *models.Session {
+	return &models.Session{ID: id, UserID: userID, Device: "Chrome on Windows", IP: "1.2.3.4", CreatedAt: created, LastSeenAt: created}
+}
+
+// TestSessions 保存 / 列表 / 删除，过期的会话从列表里消失
+func TestSessions(t *testing.T) {
+	ctx := context.Background()
+	s, mr := setupMiniRedis(t)
+	now := time.Unix(1700000000, 0)
+
+	assert.NoError(t, s.SaveSession(ctx, newSession("f1", 1, now), time.Hour))
+	assert.NoError(t, s.SaveSession(ctx, newSession("f2", 1, now.Add(time.Minute)), 2*time.Hour))
+
+	list, err := s.ListSessions(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "f2", list[0].ID, "最近登录的排在前面")
+	assert.Equal(t, "1.2.3.4", list[0].IP)
+
+	mr.FastForward(90 * time.Minute)
+	list, _ = s.ListSessions(ctx, 1)
+	assert.Len(t, list, 1)
+
+	ok, err := s.TouchSession(ctx, "f1", now)
+	assert.NoError(t, err)
+	assert.False(t, ok, "过期的会话不会被重新创建")
+
+	assert.NoError(t, s.DeleteSession(ctx, 1, "f2"))
+	_, err = s.GetSession(ctx, "f2")
+	assert.ErrorIs(t, err, ErrorSessionNotFound)
+}
@@ -0,0 +1,21 @@
+package dao
+
+import (
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Store 持有 MySQL 和 Redis 连接，dao 层的读写都是它的方法
+// 没有包级别的全局连接，同一个进程里可以同时存在多个互不影响的 Store (比如每个测试用自己的 miniredis)
+type Store struct {
+	DB  *gorm.DB
+	RDB *redis.Client
+}
+
+// NewStore 只用到 Redis 的场景 (比如单元测试) db 可以传 nil，换成 NoMySQL，查库时返回 ErrorNoMySQL
+func NewStore(db *gorm.DB, rdb *redis.Client) *Store {
+	if db == nil {
+		db = NoMySQL()
+	}
+	return &Store{DB: db, RDB: rdb}
+}
@@ -0,0 +1,110 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrorRefreshTokenNotFound = errors.New("刷新令牌不存在或已失效")
+	ErrorRefreshTokenReused   = errors.New("刷新令牌已被使用")
+)
+
+// rotateRefreshScript 原子地完成 Refresh Token 的轮换
+// KEYS[1]: family key
+// ARGV[1]: 客户端出示的 jti  ARGV[2]: 新 jti  ARGV[3]: 过期时间 (毫秒)
+// 返回值: 1 轮换成功 / 0 出示的是旧 jti (重放)，整个 family 已被吊销 / -1 family 不存在
+// ⚡️ 必须用 Lua：GET 和 SET 之间如果被并发请求插队，同一张 Refresh Token 就能换出两张新 Token
+var rotateRefreshScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if not cur then
+	return -1
+end
+if cur ~= ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// SaveRefreshFamily 登录时创建一个新的 Refresh Token family
+func (s *Store) SaveRefreshFamily(ctx context.Context, familyID, jti string, expiration time.Duration) error {
+	return s.RDB.Set(ctx, getRedisKey(KeyRefreshFamilyPrefix+familyID), jti, expiration).Err()
+}
+
+// RotateRefreshFamily 把 family 当前有效的 jti 从 oldJTI 换成 newJTI
+// 如果 oldJTI 已经不是当前值，说明这张 Refresh Token 被用过了 (很可能被盗)，直接吊销整个 family
+func (s *Store) RotateRefreshFamily(ctx context.Context, familyID, oldJTI, newJTI string, expiration time.Duration) error {
+	key := getRedisKey(KeyRefreshFamilyPrefix + familyID)
+	res, err := rotateRefreshScript.Run(ctx, s.RDB, []string{key},
+		oldJTI, newJTI, expiration.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case 1:
+		return nil
+	case 0:
+		return ErrorRefreshTokenReused
+	default:
+		return ErrorRefreshTokenNotFound
+	}
+}
+
+// RevokeRefreshFamily 吊销整个 family (该 family 下所有 Refresh Token 立即失效)
+func (s *Store) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	return s.RDB.Del(ctx, getRedisKey(KeyRefreshFamilyPrefix+familyID)).Err()
+}
+
+// DenyToken 把 Access Token 加入黑名单，expiration 传 Token 的剩余有效期即可
+// Token 过期后本来就不能用了，黑名单记录跟着一起过期，不会无限膨胀
+// 以前签发的 Token 没有 jti，不能写进黑名单 (空 jti 的 key 会把所有没有 jti 的 Token 一起拉黑)
+func (s *Store) DenyToken(ctx context.Context, jti string, expiration time.Duration) error {
+	if jti == "" || expiration <= 0 {
+		return nil
+	}
+	return s.RDB.Set(ctx, getRedisKey(KeyTokenDenylistPrefix+jti), 1, expiration).Err()
+}
+
+// GetTokenGeneration 获取用户当前的 Token 代数，从未 "退出所有设备" 过的用户为 0
+func (s *Store) GetTokenGeneration(ctx context.Context, userID int64) (int64, error) {
+	gen, err := s.RDB.Get(ctx, getTokenGenerationKey(userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return gen, err
+}
+
+// BumpTokenGeneration 用户的 Token 代数 +1，之前签发的所有 Token 全部失效
+func (s *Store) BumpTokenGeneration(ctx context.Context, userID int64) (int64, error) {
+	return s.RDB.Incr(ctx, getTokenGenerationKey(userID)).Result()
+}
+
+// CheckTokenState 一次往返同时查出 jti 是否在黑名单里、以及用户当前的 Token 代数
+// 鉴权中间件每个请求都要调用，所以用 Pipeline 合并两条命令
+func (s *Store) CheckTokenState(ctx context.Context, jti string, userID int64) (denied bool, generation int64, err error) {
+	pipe := s.RDB.Pipeline()
+	// 没有 jti 的老 Token 只能靠代数吊销，不查黑名单
+	var existsCmd *redis.IntCmd
+	if jti != "" {
+		existsCmd = pipe.Exists(ctx, getRedisKey(KeyTokenDenylistPrefix+jti))
+	}
+	genCmd := pipe.Get(ctx, getTokenGenerationKey(userID))
+	if _, err = pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return false, 0, err
+	}
+	generation, err = genCmd.Int64()
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	return existsCmd != nil && existsCmd.Val() > 0, generation, err
+}
+
+func getTokenGenerationKey(userID int64) string {
+	return getRedisKey(KeyTokenGenerationPrefix + strconv.FormatInt(userID, 10))
+}
@@ -0,0 +1,101 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupMiniRedis 创建一个连着内存版 Redis 的 Store，测试不需要真实的 Redis
+func setupMiniRedis(t *testing.T) (*Store, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewStore(nil, rdb), mr
+}
+
+// TestRotateRefreshFamily 测试 Refresh Token 轮换与重放检测
+func TestRotateRefreshFamily(t *testing.T) {
+	ctx := context.Background()
+	s, mr := setupMiniRedis(t)
+
+	// 1. 登录：family 当前 jti 为 jti-1
+	assert.NoError(t, s.SaveRefreshFamily(ctx, "f1", "jti-1", time.Hour))
+
+	// 2. 正常轮换：jti-1 -> jti-2
+	assert.NoError(t, s.RotateRefreshFamily(ctx, "f1", "jti-1", "jti-2", time.Hour))
+	cur, _ := mr.Get(getRedisKey(KeyRefreshFamilyPrefix + "f1"))
+	assert.Equal(t, "jti-2", cur)
+
+	// 3. 重放已经用过的 jti-1：返回重放错误，并吊销整个 family
+	assert.ErrorIs(t, s.RotateRefreshFamily(ctx, "f1", "jti-1", "jti-3", time.Hour), ErrorRefreshTokenReused)
+	assert.False(t, mr.Exists(getRedisKey(KeyRefreshFamilyPrefix+"f1")))
+
+	// 4. family 被吊销后，连原本有效的 jti-2 也不能再用了
+	assert.ErrorIs(t, s.RotateRefreshFamily(ctx, "f1", "jti-2", "jti-4", time.Hour), ErrorRefreshTokenNotFound)
+}
+
+// TestCheckTokenState 测试黑名单与 Token 代数
+func TestCheckTokenState(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupMiniRedis(t)
+
+	// 1. 什么都没发生过：不在黑名单，代数为 0
+	denied, gen, err := s.CheckTokenState(ctx, "jti-a", 42)
+	assert.NoError(t, err)
+	assert.False(t, denied)
+	assert.Equal(t, int64(0), gen)
+
+	// 2. 注销单个 Token
+	assert.NoError(t, s.DenyToken(ctx, "jti-a", time.Minute))
+	denied, _, err = s.CheckTokenState(ctx, "jti-a", 42)
+	assert.NoError(t, err)
+	assert.True(t, denied)
+
+	// 3. 退出所有设备：代数 +1
+	newGen, err := s.BumpTokenGeneration(ctx, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), newGen)
+	denied, gen, err = s.CheckTokenState(ctx, "jti-b", 42)
+	assert.NoError(t, err)
+	assert.False(t, denied)
+	assert.Equal(t, int64(1), gen)
+}
+
+// TestCheckTokenStateWithoutJTI 以前签发的 Token 没有 jti，一个人退出不能把其他没有 jti 的 Token 都拉黑
+func TestCheckTokenStateWithoutJTI(t *testing.T) {
+	ctx := context.Background()
+	s, mr := setupMiniRedis(t)
+
+	assert.NoError(t, s.DenyToken(ctx, "", time.Minute))
+	assert.False(t, mr.Exists(getRedisKey(KeyTokenDenylistPrefix)), "空 jti 不写黑名单")
+
+	// 即使之前已经写进去了空 jti 的 key，也不算被拉黑
+	assert.NoError(t, mr.Set(getRedisKey(KeyTokenDenylistPrefix), "1"))
+	denied, _, err := s.CheckTokenState(ctx, "", 42)
+	assert.NoError(t, err)
+	assert.False(t, denied)
+
+	// 没有 jti 的 Token 仍然可以通过代数吊销
+	_, err = s.BumpTokenGeneration(ctx, 42)
+	assert.NoError(t, err)
+	_, gen, err := s.CheckTokenState(ctx, "", 42)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), gen)
+}
+
+// TestContextDeadline 请求的期限已经过了，Redis 命令不会再执行
+func TestContextDeadline(t *testing.T) {
+	s, mr := setupMiniRedis(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := s.SaveRefreshFamily(ctx, "f1", "jti-1", time.Hour)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, mr.Exists(getRedisKey(KeyRefreshFamilyPrefix+"f1")))
+}
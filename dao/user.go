@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"errors"
 	"gin-api-scaffold-v1/models"
 
@@ -13,12 +14,45 @@ import (
 var (
 	ErrorUserExist    = errors.New("用户已存在")
 	ErrorUserNotFound = errors.New("用户不存在")
+	ErrorEmailExist   = errors.New("邮箱已被注册")
 )
 
+// UserRepository 用户表的读写，logic 层只通过这个接口访问用户数据
+// GormUserRepository 读写 MySQL；MemoryUserRepository 存在内存里，单元测试和本地开发不需要数据库
+// 查不到用户时统一返回 ErrorUserNotFound
+type UserRepository interface {
+	// CheckUserExist 用户名已被占用时返回 ErrorUserExist
+	CheckUserExist(ctx context.Context, username string) error
+	// CheckEmailExist 邮箱已被注册时返回 ErrorEmailExist
+	CheckEmailExist(ctx context.Context, email string) error
+	Insert(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, userID int64) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// ListByIDs 批量查用户 (列表接口里补作者信息)，不存在的 ID 直接忽略
+	ListByIDs(ctx context.Context, userIDs []int64) ([]models.User, error)
+	UpdatePassword(ctx context.Context, userID int64, password string) error
+	// SwapPassword 密码哈希还是 old 时才改成 password，返回有没有改 (期间改过密码的不动)
+	SwapPassword(ctx context.Context, userID int64, old, password string) (bool, error)
+	// ListLegacyPasswords 密码哈希不是 "$" 开头 (PHC / modular crypt 格式) 的用户，也就是老的 MD5 格式，
+	// 按 user_id 正序，从 afterUserID 之后取 limit 条
+	ListLegacyPasswords(ctx context.Context, afterUserID int64, limit int) ([]models.User, error)
+	// UpdateProfile 更新个人资料，updates 里只放需要修改的列 (gender / avatar)
+	UpdateProfile(ctx context.Context, userID int64, updates map[string]interface{}) error
+	// UpdateEmail 修改邮箱，新邮箱需要重新验证
+	UpdateEmail(ctx context.Context, userID int64, email string) error
+	SetEmailVerified(ctx context.Context, userID int64) error
+}
+
+// GormUserRepository 基于 GORM 的 UserRepository
+type GormUserRepository struct {
+	DB *gorm.DB
+}
+
 // CheckUserExist 检查用户是否存在
-func CheckUserExist(username string) (err error) {
+func (r GormUserRepository) CheckUserExist(ctx context.Context, username string) (err error) {
 	var count int64
-	err = DB.Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+	err = r.DB.WithContext(ctx).Model(&models.User{}).Where("username = ?", username).Count(&count).Error
 	if err != nil {
 		return err
 	}
@@ -29,16 +63,29 @@ func CheckUserExist(username string) (err error) {
 	return nil
 }
 
-// InsertUser 插入新用户
-func InsertUser(user *models.User) (err error) {
-	err = DB.Create(user).Error
+// CheckEmailExist 检查邮箱是否已被注册
+func (r GormUserRepository) CheckEmailExist(ctx context.Context, email string) (err error) {
+	var count int64
+	err = r.DB.WithContext(ctx).Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrorEmailExist
+	}
+	return nil
+}
+
+// Insert 插入新用户
+func (r GormUserRepository) Insert(ctx context.Context, user *models.User) (err error) {
+	err = r.DB.WithContext(ctx).Create(user).Error
 	return
 }
 
-// GetUserByUsername 根据用户名查用户 (用于登录)
-func GetUserByUsername(username string) (user *models.User, err error) {
+// GetByUsername 根据用户名查用户 (用于登录)
+func (r GormUserRepository) GetByUsername(ctx context.Context, username string) (user *models.User, err error) {
 	user = new(models.User)
-	err = DB.Where("username = ?", username).First(user).Error
+	err = r.DB.WithContext(ctx).Where("username = ?", username).First(user).Error
 
 	if err == gorm.ErrRecordNotFound {
 		// ⚡️ 3. 这里也返回全局变量
@@ -46,3 +93,70 @@ func GetUserByUsername(username string) (user *models.User, err error) {
 	}
 	return
 }
+
+// UpdatePassword 更新用户的密码哈希
+func (r GormUserRepository) UpdatePassword(ctx context.Context, userID int64, password string) (err error) {
+	err = r.DB.WithContext(ctx).Model(&models.User{}).Where("user_id = ?", userID).Update("password", password).Error
+	return
+}
+
+// SwapPassword 密码哈希还是 old 时才更新
+func (r GormUserRepository) SwapPassword(ctx context.Context, userID int64, old, password string) (bool, error) {
+	res := r.DB.WithContext(ctx).Model(&models.User{}).Where("user_id = ? AND password = ?", userID, old).Update("password", password)
+	return res.RowsAffected > 0, res.Error
+}
+
+// ListLegacyPasswords 密码哈希不是 "$" 开头的用户
+func (r GormUserRepository) ListLegacyPasswords(ctx context.Context, afterUserID int64, limit int) (users []models.User, err error) {
+	err = r.DB.WithContext(ctx).Where("user_id > ? AND password NOT LIKE ?", afterUserID, "$%").
+		Order("user_id").Limit(limit).Find(&users).Error
+	return
+}
+
+// GetByID 根据 user_id 查用户
+func (r GormUserRepository) GetByID(ctx context.Context, userID int64) (user *models.User, err error) {
+	user = new(models.User)
+	err = r.DB.WithContext(ctx).Where("user_id = ?", userID).First(user).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrorUserNotFound
+	}
+	return
+}
+
+// GetByEmail 根据邮箱查用户 (用于找回密码)
+func (r GormUserRepository) GetByEmail(ctx context.Context, email string) (user *models.User, err error) {
+	user = new(models.User)
+	err = r.DB.WithContext(ctx).Where("email = ?", email).First(user).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrorUserNotFound
+	}
+	return
+}
+
+// SetEmailVerified 标记邮箱已验证
+func (r GormUserRepository) SetEmailVerified(ctx context.Context, userID int64) (err error) {
+	err = r.DB.WithContext(ctx).Model(&models.User{}).Where("user_id = ?", userID).Update("email_verified", true).Error
+	return
+}
+
+// UpdateProfile 更新个人资料，updates 里只放需要修改的列
+func (r GormUserRepository) UpdateProfile(ctx context.Context, userID int64, updates map[string]interface{}) (err error) {
+	err = r.DB.WithContext(ctx).Model(&models.User{}).Where("user_id = ?", userID).Updates(updates).Error
+	return
+}
+
+// UpdateEmail 修改邮箱，新邮箱需要重新验证
+func (r GormUserRepository) UpdateEmail(ctx context.Context, userID int64, email string) (err error) {
+	err = r.DB.WithContext(ctx).Model(&models.User{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"email": email, "email_verified": false}).Error
+	return
+}
+
+// ListByIDs 批量查用户 (列表接口里补作者信息)
+func (r GormUserRepository) ListByIDs(ctx context.Context, userIDs []int64) (users []models.User, err error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	err = r.DB.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&users).Error
+	return
+}
@@ -0,0 +1,196 @@
+package dao
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gin-api-scaffold-v1/models"
+)
+
+// MemoryUserRepository 存在内存里的 UserRepository，行为和 GormUserRepository 一致
+// (用户名、邮箱唯一，自增 ID，创建 / 更新时间)，不持久化，用于单元测试和没有 MySQL 的本地开发
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	users  map[int64]*models.User // user_id -> 用户
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{users: make(map[int64]*models.User)}
+}
+
+func (r *MemoryUserRepository) CheckUserExist(_ context.Context, username string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.find(func(u *models.User) bool { return u.Username == username }) != nil {
+		return ErrorUserExist
+	}
+	return nil
+}
+
+func (r *MemoryUserRepository) CheckEmailExist(_ context.Context, email string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.find(func(u *models.User) bool { return u.Email == email }) != nil {
+		return ErrorEmailExist
+	}
+	return nil
+}
+
+// Insert 和数据库的唯一索引一样，用户名 / 邮箱 / user_id 重复时插入失败
+func (r *MemoryUserRepository) Insert(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.UserID]; ok {
+		return fmt.Errorf("duplicate user_id %d", user.UserID)
+	}
+	if r.find(func(u *models.User) bool { return u.Username == user.Username }) != nil {
+		return ErrorUserExist
+	}
+	if user.Email != "" && r.find(func(u *models.User) bool { return u.Email == user.Email }) != nil {
+		return ErrorEmailExist
+	}
+	r.nextID++
+	now := time.Now()
+	user.ID, user.CreateTime, user.UpdateTime = r.nextID, now, now
+	u := *user
+	r.users[u.UserID] = &u
+	return nil
+}
+
+func (r *MemoryUserRepository) GetByID(_ context.Context, userID int64) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.get(r.users[userID])
+}
+
+func (r *MemoryUserRepository) GetByUsername(_ context.Context, username string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.get(r.find(func(u *models.User) bool { return u.Username == username }))
+}
+
+func (r *MemoryUserRepository) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.get(r.find(func(u *models.User) bool { return u.Email == email }))
+}
+
+func (r *MemoryUserRepository) ListByIDs(_ context.Context, userIDs []int64) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var users []models.User
+	for _, id := range userIDs {
+		if u, ok := r.users[id]; ok {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+func (r *MemoryUserRepository) UpdatePassword(_ context.Context, userID int64, password string) error {
+	return r.update(userID, func(u *models.User) error {
+		u.Password = password
+		return nil
+	})
+}
+
+func (r *MemoryUserRepository) SwapPassword(_ context.Context, userID int64, old, password string) (bool, error) {
+	swapped := false
+	err := r.update(userID, func(u *models.User) error {
+		if u.Password == old {
+			u.Password, swapped = password, true
+		}
+		return nil
+	})
+	return swapped, err
+}
+
+func (r *MemoryUserRepository) ListLegacyPasswords(_ context.Context, afterUserID int64, limit int) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var users []models.User
+	for _, u := range r.users {
+		if u.UserID > afterUserID && !strings.HasPrefix(u.Password, "$") {
+			users = append(users, *u)
+		}
+	}
+	slices.SortFunc(users, func(a, b models.User) int { return cmp.Compare(a.UserID, b.UserID) })
+	return users[:min(len(users), limit)], nil
+}
+
+// UpdateProfile 只认识 logic 层会改的列，传了别的列说明调用方写错了，直接报错
+func (r *MemoryUserRepository) UpdateProfile(_ context.Context, userID int64, updates map[string]interface{}) error {
+	return r.update(userID, func(u *models.User) error {
+		for col, v := range updates {
+			var ok bool
+			switch col {
+			case "gender":
+				u.Gender, ok = v.(int8)
+			case "avatar":
+				u.Avatar, ok = v.(string)
+			default:
+				return fmt.Errorf("unknown user column %q", col)
+			}
+			if !ok {
+				return fmt.Errorf("invalid value %v for user column %q", v, col)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *MemoryUserRepository) UpdateEmail(_ context.Context, userID int64, email string) error {
+	return r.update(userID, func(u *models.User) error {
+		u.Email, u.EmailVerified = email, false
+		return nil
+	})
+}
+
+func (r *MemoryUserRepository) SetEmailVerified(_ context.Context, userID int64) error {
+	return r.update(userID, func(u *models.User) error {
+		u.EmailVerified = true
+		return nil
+	})
+}
+
+// find 返回第一个满足条件的用户，调用方负责加锁
+func (r *MemoryUserRepository) find(match func(*models.User) bool) *models.User {
+	for _, u := range r.users {
+		if match(u) {
+			return u
+		}
+	}
+	return nil
+}
+
+// get 返回副本，调用方修改返回值不会影响存储的数据 (和从数据库查出来一样)
+func (r *MemoryUserRepository) get(u *models.User) (*models.User, error) {
+	if u == nil {
+		return nil, ErrorUserNotFound
+	}
+	c := *u
+	return &c, nil
+}
+
+// update 和 GORM 的 Where(...).Update(...) 一样，用户不存在时什么都不做也不报错
+func (r *MemoryUserRepository) update(userID int64, fn func(*models.User) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok {
+		return nil
+	}
+	c := *u
+	if err := fn(&c); err != nil {
+		return err
+	}
+	c.UpdateTime = time.Now()
+	r.users[userID] = &c
+	return nil
+}
@@ -0,0 +1,43 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/models"
+)
+
+// TestMemoryUserRepository 唯一约束、查不到时的错误、返回副本
+func TestMemoryUserRepository(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryUserRepository()
+	assert.NoError(t, r.Insert(ctx, &models.User{UserID: 1, Username: "alice", Email: "alice@example.com"}))
+	assert.ErrorIs(t, r.Insert(ctx, &models.User{UserID: 2, Username: "alice", Email: "a2@example.com"}), ErrorUserExist)
+	assert.ErrorIs(t, r.Insert(ctx, &models.User{UserID: 2, Username: "bob", Email: "alice@example.com"}), ErrorEmailExist)
+	assert.ErrorIs(t, r.CheckUserExist(ctx, "alice"), ErrorUserExist)
+	assert.NoError(t, r.CheckEmailExist(ctx, "bob@example.com"))
+
+	_, err := r.GetByUsername(ctx, "bob")
+	assert.ErrorIs(t, err, ErrorUserNotFound)
+
+	u, err := r.GetByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+	u.Username = "changed"
+	u, _ = r.GetByID(ctx, 1)
+	assert.Equal(t, "alice", u.Username, "修改返回值不影响存储的数据")
+
+	assert.NoError(t, r.UpdateProfile(ctx, 1, map[string]interface{}{"gender": models.ParseGender("female"), "avatar": "a.png"}))
+	assert.Error(t, r.UpdateProfile(ctx, 1, map[string]interface{}{"password": "x"}))
+	assert.NoError(t, r.SetEmailVerified(ctx, 1))
+	assert.NoError(t, r.UpdateEmail(ctx, 1, "new@example.com"))
+	u, _ = r.GetByID(ctx, 1)
+	assert.Equal(t, "a.png", u.Avatar)
+	assert.Equal(t, "new@example.com", u.Email)
+	assert.False(t, u.EmailVerified, "换了邮箱需要重新验证")
+
+	users, err := r.ListByIDs(ctx, []int64{1, 99})
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+}
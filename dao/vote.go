@@ -0,0 +1,267 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/models"
+)
+
+// ErrorVoteClosed 帖子已经不在投票期内 (已归档)
+var ErrorVoteClosed = errors.New("投票已关闭")
+
+// 热度算法 (Reddit hot)：票数差取对数，再加上发帖时间
+// 每 45000 秒 (12.5 小时) 的新鲜度抵得上 10 倍的票数差，新帖子不用很多票就能排到老帖子前面
+// 热度只和发帖时间、票数有关，不会随着当前时间变化，所以可以直接存在 ZSET 里排序
+const (
+	hotEpoch    = 1767225600 // 2026-01-01 00:00:00 UTC
+	hotInterval = 45000
+)
+
+// HotScore 计算帖子热度，必须和 votePostScript 里的算法保持一致
+func HotScore(ups, downs int64, createdAt time.Time) float64 {
+	s := ups - downs
+	order := math.Log10(math.Max(math.Abs(float64(s)), 1))
+	var sign float64
+	if s > 0 {
+		sign = 1
+	} else if s < 0 {
+		sign = -1
+	}
+	return sign*order + float64(createdAt.Unix()-hotEpoch)/hotInterval
+}
+
+// votePostScript 投票并重新计算热度，整个过程是原子的
+// KEYS: 1 投票记录 2 全站热度 3 社区热度 4 投票期内的帖子
+// ARGV: 1 user_id 2 方向 (1 / 0 / -1) 3 post_id 4 发帖时间
+// 返回 {赞成票, 反对票}；帖子已经不在投票期内返回 -1
+var votePostScript = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[4], ARGV[3]) == false then
+	return -1
+end
+local direction = tonumber(ARGV[2])
+if direction == 0 then
+	redis.call('ZREM', KEYS[1], ARGV[1])
+else
+	redis.call('ZADD', KEYS[1], direction, ARGV[1])
+end
+local ups = redis.call('ZCOUNT', KEYS[1], 1, 1)
+local downs = redis.call('ZCOUNT', KEYS[1], -1, -1)
+local s = ups - downs
+local sign = 0
+if s > 0 then sign = 1 elseif s < 0 then sign = -1 end
+local score = sign * math.log10(math.max(math.abs(s), 1)) + (tonumber(ARGV[4]) - ` + strconv.Itoa(hotEpoch) + `) / ` + strconv.Itoa(hotInterval) + `
+redis.call('ZADD', KEYS[2], score, ARGV[3])
+redis.call('ZADD', KEYS[3], score, ARGV[3])
+return {ups, downs}
+`)
+
+// CreatePostIndex 新帖子加到时间 / 热度排行里，并开放投票
+func (s *Store) CreatePostIndex(ctx context.Context, postID, communityID int64, createdAt time.Time) error {
+	member := strconv.FormatInt(postID, 10)
+	byTime := redis.Z{Score: float64(createdAt.Unix()), Member: member}
+	byScore := redis.Z{Score: HotScore(0, 0, createdAt), Member: member}
+	pipe := s.RDB.TxPipeline()
+	pipe.ZAdd(ctx, getPostTimeKey(0), byTime)
+	pipe.ZAdd(ctx, getPostTimeKey(communityID), byTime)
+	pipe.ZAdd(ctx, getPostScoreKey(0), byScore)
+	pipe.ZAdd(ctx, getPostScoreKey(communityID), byScore)
+	pipe.ZAdd(ctx, getRedisKey(KeyPostVotingZSet), byTime)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RemovePostIndex 删除帖子时从排行里移除，投票记录和评论数一起删掉
+func (s *Store) RemovePostIndex(ctx context.Context, postID, communityID int64) error {
+	member := strconv.FormatInt(postID, 10)
+	pipe := s.RDB.TxPipeline()
+	pipe.ZRem(ctx, getPostTimeKey(0), member)
+	pipe.ZRem(ctx, getPostTimeKey(communityID), member)
+	pipe.ZRem(ctx, getPostScoreKey(0), member)
+	pipe.ZRem(ctx, getPostScoreKey(communityID), member)
+	pipe.ZRem(ctx, getRedisKey(KeyPostVotingZSet), member)
+	pipe.Del(ctx, getPostVotedKey(postID))
+	pipe.HDel(ctx, getRedisKey(KeyPostCommentCountHash), member)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// VotePost 投票 (direction 为 0 表示取消)，返回最新的票数
+func (s *Store) VotePost(ctx context.Context, postID, communityID, userID int64, direction int8, createdAt time.Time) (*models.ResVote, error) {
+	keys := []string{
+		getPostVotedKey(postID),
+		getPostScoreKey(0),
+		getPostScoreKey(communityID),
+		getRedisKey(KeyPostVotingZSet),
+	}
+	res, err := votePostScript.Run(ctx, s.RDB, keys,
+		userID, direction, postID, createdAt.Unix()).Result()
+	if err != nil {
+		return nil, err
+	}
+	counts, ok := res.([]interface{})
+	if !ok || len(counts) != 2 {
+		return nil, ErrorVoteClosed
+	}
+	return &models.ResVote{UpVotes: counts[0].(int64), DownVotes: counts[1].(int64)}, nil
+}
+
+// GetPostVoteCounts 批量查询投票期内帖子的票数
+func (s *Store) GetPostVoteCounts(ctx context.Context, postIDs []int64) (map[int64]models.ResVote, error) {
+	pipe := s.RDB.Pipeline()
+	ups := make([]*redis.IntCmd, len(postIDs))
+	downs := make([]*redis.IntCmd, len(postIDs))
+	for i, id := range postIDs {
+		ups[i] = pipe.ZCount(ctx, getPostVotedKey(id), "1", "1")
+		downs[i] = pipe.ZCount(ctx, getPostVotedKey(id), "-1", "-1")
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	counts := make(map[int64]models.ResVote, len(postIDs))
+	for i, id := range postIDs {
+		counts[id] = models.ResVote{UpVotes: ups[i].Val(), DownVotes: downs[i].Val()}
+	}
+	return counts, nil
+}
+
+// PostRank 帖子在排行里的位置，Score 是时间排行里的发帖时间 (秒) 或热度排行里的热度
+type PostRank struct {
+	PostID int64
+	Score  float64
+}
+
+// ListPostIDs 按时间或热度倒序取帖子 ID，communityID 为 0 表示全站
+// 游标是上一页最后一条的 (分数, 帖子 ID)，多取一条交给 common.NewCursorPage 判断有没有下一页；
+// total 是排行里的帖子总数
+// 排行是 Redis 的 ZSET，不能用 CursorQuery：先取和游标同分、排在它后面的帖子，再取分数更低的
+// 同分的成员 Redis 按字符串倒序排，所以并列时按字符串比较帖子 ID，和第一页的顺序保持一致
+func (s *Store) ListPostIDs(ctx context.Context, communityID int64, order string, cursor *common.Cursor, limit int) (ranks []PostRank, total int64, err error) {
+	key := getPostTimeKey(communityID)
+	if order == models.OrderScore {
+		key = getPostScoreKey(communityID)
+	}
+	pipe := s.RDB.Pipeline()
+	var ties, rest *redis.ZSliceCmd
+	if cursor == nil {
+		rest = pipe.ZRevRangeWithScores(ctx, key, 0, int64(limit))
+	} else {
+		ties = pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: cursor.Key, Max: cursor.Key})
+		rest = pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+			Min: "-inf", Max: "(" + cursor.Key, Count: int64(limit + 1),
+		})
+	}
+	card := pipe.ZCard(ctx, key)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	var zs []redis.Z
+	if ties != nil {
+		after := strconv.FormatInt(cursor.ID, 10)
+		for _, z := range ties.Val() {
+			if z.Member.(string) < after {
+				zs = append(zs, z)
+			}
+		}
+	}
+	zs = append(zs, rest.Val()...)
+	if len(zs) > limit+1 {
+		zs = zs[:limit+1]
+	}
+	ranks = make([]PostRank, 0, len(zs))
+	for _, z := range zs {
+		id, err := strconv.ParseInt(z.Member.(string), 10, 64)
+		if err != nil {
+			return nil, 0, err
+		}
+		ranks = append(ranks, PostRank{PostID: id, Score: z.Score})
+	}
+	return ranks, card.Val(), nil
+}
+
+// ListExpiredVotingPosts 投票期已经结束、还没归档的帖子
+func (s *Store) ListExpiredVotingPosts(ctx context.Context, before time.Time, limit int64) ([]int64, error) {
+	members, err := s.RDB.ZRangeByScore(ctx, getRedisKey(KeyPostVotingZSet), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(before.Unix(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return parseIDs(members)
+}
+
+// CloseVoting 关闭投票，返回 false 说明已经被别的实例关闭了
+func (s *Store) CloseVoting(ctx context.Context, postID int64) (bool, error) {
+	n, err := s.RDB.ZRem(ctx, getRedisKey(KeyPostVotingZSet), postID).Result()
+	return n == 1, err
+}
+
+// ReopenVoting 归档失败时重新放回投票期列表，at 早于投票期截止时间，下一轮会重试
+func (s *Store) ReopenVoting(ctx context.Context, postID int64, at time.Time) error {
+	return s.RDB.ZAdd(ctx, getRedisKey(KeyPostVotingZSet),
+		redis.Z{Score: float64(at.Unix()), Member: postID}).Err()
+}
+
+// GetPostVotes 取出帖子的全部投票记录 (归档用)
+func (s *Store) GetPostVotes(ctx context.Context, postID int64) ([]models.PostVote, error) {
+	zs, err := s.RDB.ZRangeWithScores(ctx, getPostVotedKey(postID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	votes := make([]models.PostVote, 0, len(zs))
+	for _, z := range zs {
+		userID, err := strconv.ParseInt(z.Member.(string), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, models.PostVote{PostID: postID, UserID: userID, Direction: int8(z.Score)})
+	}
+	return votes, nil
+}
+
+// DeletePostVotes 归档完成后删除 Redis 里的投票记录
+func (s *Store) DeletePostVotes(ctx context.Context, postID int64) error {
+	return s.RDB.Del(ctx, getPostVotedKey(postID)).Err()
+}
+
+// parseIDs ZSET 的 member 转成 ID
+func parseIDs(members []string) ([]int64, error) {
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseInt(m, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// getPostTimeKey communityID 为 0 表示全站
+func getPostTimeKey(communityID int64) string {
+	if communityID == 0 {
+		return getRedisKey(KeyPostTimeZSet)
+	}
+	return getRedisKey(KeyPostTimeZSet + ":" + strconv.FormatInt(communityID, 10))
+}
+
+// getPostScoreKey communityID 为 0 表示全站
+func getPostScoreKey(communityID int64) string {
+	if communityID == 0 {
+		return getRedisKey(KeyPostScoreZSet)
+	}
+	return getRedisKey(KeyPostScoreZSet + ":" + strconv.FormatInt(communityID, 10))
+}
+
+func getPostVotedKey(postID int64) string {
+	return getRedisKey(KeyPostVotedPrefix + strconv.FormatInt(postID, 10))
+}
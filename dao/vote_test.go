@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/models"
+)
+
+// TestVotePost 投票、改票、取消，热度和 Go 里的 HotScore 算出来的一致
+func TestVotePost(t *testing.T) {
+	ctx := context.Background()
+	s, mr := setupMiniRedis(t)
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, s.CreatePostIndex(ctx, 100, 9, createdAt))
+
+	scoreOf := func(key string) float64 {
+		s, err := mr.ZScore(key, "100")
+		assert.NoError(t, err)
+		return s
+	}
+	assert.InDelta(t, HotScore(0, 0, createdAt), scoreOf("bluebell:post:score:9"), 1e-9)
+
+	for userID := int64(1); userID <= 12; userID++ {
+		_, err := s.VotePost(ctx, 100, 9, userID, 1, createdAt)
+		assert.NoError(t, err)
+	}
+	res, err := s.VotePost(ctx, 100, 9, 13, -1, createdAt)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ResVote{UpVotes: 12, DownVotes: 1}, res)
+	assert.InDelta(t, HotScore(12, 1, createdAt), scoreOf("bluebell:post:score"), 1e-9)
+
+	// 改票 / 取消都是覆盖同一个用户的记录
+	res, _ = s.VotePost(ctx, 100, 9, 1, -1, createdAt)
+	assert.Equal(t, &models.ResVote{UpVotes: 11, DownVotes: 2}, res)
+	res, _ = s.VotePost(ctx, 100, 9, 1, 0, createdAt)
+	assert.Equal(t, &models.ResVote{UpVotes: 11, DownVotes: 1}, res)
+	assert.InDelta(t, HotScore(11, 1, createdAt), scoreOf("bluebell:post:score:9"), 1e-9)
+
+	counts, err := s.GetPostVoteCounts(ctx, []int64{100, 200})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ResVote{UpVotes: 11, DownVotes: 1}, counts[100])
+	assert.Equal(t, models.ResVote{}, counts[200])
+
+	// 关闭投票之后不能再投，只有一个实例能关闭成功
+	ok, err := s.CloseVoting(ctx, 100)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = s.CloseVoting(ctx, 100)
+	assert.False(t, ok)
+	_, err = s.VotePost(ctx, 100, 9, 14, 1, createdAt)
+	assert.ErrorIs(t, err, ErrorVoteClosed)
+
+	votes, err := s.GetPostVotes(ctx, 100)
+	assert.NoError(t, err)
+	assert.Len(t, votes, 12)
+}
+
+// TestListPostIDs 全站 / 社区、按时间 / 热度分页
+func TestListPostIDs(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupMiniRedis(t)
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	// 帖子 1..5 依次晚一小时发，奇数在社区 1，偶数在社区 2
+	for id := int64(1); id <= 5; id++ {
+		assert.NoError(t, s.CreatePostIndex(ctx, id, 2-id%2, base.Add(time.Duration(id)*time.Hour)))
+	}
+	// 给最早的帖子投很多票，让它在热度榜上排第一
+	for userID := int64(1); userID <= 1000; userID++ {
+		_, err := s.VotePost(ctx, 1, 1, userID, 1, base.Add(time.Hour))
+		assert.NoError(t, err)
+	}
+
+	ranks, total, err := s.ListPostIDs(ctx, 0, models.OrderTime, nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 4, 3}, rankIDs(ranks), "多取一条")
+	assert.EqualValues(t, 5, total)
+
+	ranks, _, _ = s.ListPostIDs(ctx, 0, models.OrderScore, nil, 2)
+	assert.Equal(t, []int64{1, 5, 4}, rankIDs(ranks))
+
+	// 游标指向帖子 3 时从它后面接着取
+	ranks, total, _ = s.ListPostIDs(ctx, 1, models.OrderTime, nil, 1)
+	assert.Equal(t, []int64{5, 3}, rankIDs(ranks))
+	assert.EqualValues(t, 3, total)
+	ranks, _, _ = s.ListPostIDs(ctx, 1, models.OrderTime, rankCursor(ranks[1]), 10)
+	assert.Equal(t, []int64{1}, rankIDs(ranks))
+
+	// 投票期结束：发帖时间早于 before 的帖子
+	expired, err := s.ListExpiredVotingPosts(ctx, base.Add(2*time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, expired)
+
+	assert.NoError(t, s.RemovePostIndex(ctx, 3, 1))
+	ranks, _, _ = s.ListPostIDs(ctx, 1, models.OrderTime, nil, 10)
+	assert.Equal(t, []int64{5, 1}, rankIDs(ranks))
+}
+
+// TestListPostIDsTies 同一秒发的帖子分数相同，翻页时既不重复也不遗漏
+func TestListPostIDsTies(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupMiniRedis(t)
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for id := int64(11); id <= 15; id++ {
+		assert.NoError(t, s.CreatePostIndex(ctx, id, 1, at))
+	}
+	assert.NoError(t, s.CreatePostIndex(ctx, 9, 1, at.Add(-time.Hour)))
+
+	var got []int64
+	var cursor *common.Cursor
+	for range 10 {
+		ranks, _, err := s.ListPostIDs(ctx, 0, models.OrderTime, cursor, 2)
+		assert.NoError(t, err)
+		if len(ranks) <= 2 {
+			got = append(got, rankIDs(ranks)...)
+			break
+		}
+		got = append(got, rankIDs(ranks[:2])...)
+		cursor = rankCursor(ranks[1])
+	}
+	assert.Equal(t, []int64{15, 14, 13, 12, 11, 9}, got)
+}
+
+func rankIDs(ranks []PostRank) []int64 {
+	ids := make([]int64, 0, len(ranks))
+	for _, r := range ranks {
+		ids = append(ids, r.PostID)
+	}
+	return ids
+}
+
+// rankCursor 和 logic 里生成游标的方式一样
+func rankCursor(r PostRank) *common.Cursor {
+	return &common.Cursor{Key: strconv.FormatFloat(r.Score, 'f', -1, 64), ID: r.PostID}
+}
@@ -9,22 +9,20 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-// 定义一个全局变量，方便内部调用（虽然有了 zap.L()，但保留这个是个好习惯）
-var Logger *zap.Logger
-
-// InitLogger 初始化 Logger (企业级完整版)
+// New 创建 Logger (企业级完整版)
 // 负责把日志系统跑起来，配置好“写到哪里”、“怎么写”、“记哪些级别”
-func InitLogger() {
+// 返回的 Logger 由 app.App 持有并传给各层，不会替换 zap 的全局 Logger
+func New(cfg *viper.Viper) *zap.Logger {
 	// =================================================================
 	// 1. 获取日志写入器 (Writer)
 	// =================================================================
 	// 我们不直接用 os.OpenFile，因为文件会越来越大。
 	// 这里用 lumberjack 库，它能自动“切割”日志文件（比如达到 10MB 就换个新文件）。
 	writeSyncer := getLogWriter(
-		viper.GetString("log.filename"), // 从配置读取文件名 (如: ./logs/bluebell.log)
-		viper.GetInt("log.max_size"),    // 单个文件最大尺寸 (MB)
-		viper.GetInt("log.max_backups"), // 最多保留几个旧文件
-		viper.GetInt("log.max_age"),     // 旧文件最多保留几天
+		cfg.GetString("log.filename"), // 从配置读取文件名 (如: ./logs/bluebell.log)
+		cfg.GetInt("log.max_size"),    // 单个文件最大尺寸 (MB)
+		cfg.GetInt("log.max_backups"), // 最多保留几个旧文件
+		cfg.GetInt("log.max_age"),     // 旧文件最多保留几天
 	)
 
 	// =================================================================
@@ -40,7 +38,7 @@ func InitLogger() {
 	// 从配置文件读 log.level (比如 "debug", "info", "error")
 	// 只有大于等于这个级别的日志才会被记录。
 	var l = new(zapcore.Level)
-	if err := l.UnmarshalText([]byte(viper.GetString("log.level"))); err != nil {
+	if err := l.UnmarshalText([]byte(cfg.GetString("log.level"))); err != nil {
 		// 如果配置文件填错了，默认给个 Debug 级别，保证能打出日志
 		*l = zapcore.DebugLevel
 	}
@@ -69,16 +67,7 @@ func InitLogger() {
 	// zap.AddCaller(): 非常重要！
 	// 加上它，日志里就会显示是哪个文件、哪一行打印的 (例如: main.go:15)，
 	// 否则你出了 Bug 根本找不到在哪里。
-	Logger = zap.New(core, zap.AddCaller())
-
-	// =================================================================
-	// 6. ⚡️⚡️⚡️ 替换全局 Logger ⚡️⚡️⚡️
-	// =================================================================
-	// 这一步是关键！
-	// Zap 默认有一个全局 Logger，但那个是空的，不干活。
-	// 我们把自己辛苦配置好的 Logger 替换上去。
-	// 以后你在任何地方调用 zap.L().Info()，实际上用的就是我们配置好的这个 Logger。
-	zap.ReplaceGlobals(Logger)
+	return zap.New(core, zap.AddCaller())
 }
 
 // ---------------------------------------------------------------------
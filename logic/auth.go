@@ -0,0 +1,189 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+var (
+	// ErrorInvalidRefreshToken Refresh Token 签名错误、过期或者类型不对
+	ErrorInvalidRefreshToken = errors.New("无效的刷新令牌")
+	// ErrorTokenRevoked Token 已注销 (单独退出或退出所有设备)
+	ErrorTokenRevoked = errors.New("令牌已注销")
+)
+
+// issueTokens 为一次新的登录签发 Access Token + Refresh Token
+// 每次登录都会开启一个新的 Refresh Token family，同时记录为一个会话 (设备)
+func (s *Service) issueTokens(ctx context.Context, userID int64, username string, client models.ClientInfo) (*models.ResToken, error) {
+	gen, err := s.store.GetTokenGeneration(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.userRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	mc := jwt.MyClaims{
+		UserID:     userID,
+		Username:   username,
+		FamilyID:   strconv.FormatInt(s.ids.GenID(), 10),
+		Generation: gen,
+		Roles:      roles,
+	}
+	refreshToken, jti, err := s.jwt.GenRefreshToken(mc)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.store.SaveRefreshFamily(ctx, mc.FamilyID, jti, s.jwt.RefreshExpire()); err != nil {
+		return nil, err
+	}
+	if err = s.createSession(ctx, mc.FamilyID, userID, client); err != nil {
+		return nil, err
+	}
+	return s.buildResToken(mc, refreshToken)
+}
+
+// RefreshToken 用 Refresh Token 换一对新的 Token (轮换)
+// 旧的 Refresh Token 用过一次就作废；如果它再次出现，说明可能被盗用，整个 family 都会被吊销
+func (s *Service) RefreshToken(ctx context.Context, p *models.ParamRefreshToken, client models.ClientInfo) (*models.ResToken, error) {
+	// 1. 校验 Refresh Token 本身 (签名、过期、类型)
+	mc, err := s.jwt.ParseRefreshToken(p.RefreshToken)
+	if err != nil {
+		return nil, ErrorInvalidRefreshToken
+	}
+
+	// 2. 用户 "退出所有设备" 之后，之前的 Refresh Token 也不能再换新 Token
+	gen, err := s.store.GetTokenGeneration(ctx, mc.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if mc.Generation < gen {
+		_ = s.store.RevokeRefreshFamily(ctx, mc.FamilyID)
+		return nil, ErrorTokenRevoked
+	}
+
+	// 3. 重新查一次角色 (分配的新角色在这里生效)，再签发新的 Refresh Token，拿到新 jti
+	roles, err := s.userRoles(ctx, mc.UserID)
+	if err != nil {
+		return nil, err
+	}
+	next := jwt.MyClaims{
+		UserID:     mc.UserID,
+		Username:   mc.Username,
+		FamilyID:   mc.FamilyID,
+		Generation: mc.Generation,
+		Roles:      roles,
+	}
+	refreshToken, jti, err := s.jwt.GenRefreshToken(next)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. 在 Redis 里原子地把 family 的当前 jti 换成新的
+	if err = s.store.RotateRefreshFamily(ctx, mc.FamilyID, mc.ID, jti, s.jwt.RefreshExpire()); err != nil {
+		if errors.Is(err, dao.ErrorRefreshTokenReused) {
+			s.log.Warn("refresh token reuse detected, family revoked",
+				zap.Int64("user_id", mc.UserID),
+				zap.String("family_id", mc.FamilyID))
+		}
+		return nil, err
+	}
+
+	// 5. 更新会话的最后活跃时间
+	if err = s.refreshSession(ctx, mc.FamilyID, mc.UserID, client); err != nil {
+		return nil, err
+	}
+
+	// 6. 返回新的 Token 对
+	return s.buildResToken(next, refreshToken)
+}
+
+// CheckTokenRevoked 检查一张已经通过签名校验的 Access Token 是否被注销了
+// 给鉴权中间件用，每个私有接口的请求都会走到这里
+func (s *Service) CheckTokenRevoked(ctx context.Context, mc *jwt.MyClaims) error {
+	denied, gen, err := s.store.CheckTokenState(ctx, mc.ID, mc.UserID)
+	if err != nil {
+		return err
+	}
+	if denied || mc.Generation < gen {
+		return ErrorTokenRevoked
+	}
+	// 所在的会话被踢掉了 (API Key 没有会话)
+	if mc.FamilyID != "" {
+		return s.checkSession(ctx, mc)
+	}
+	return nil
+}
+
+// Logout 退出当前设备：当前 Access Token 进黑名单，同一次登录的 Refresh Token 一起吊销
+// 以前签发的 Token 没有 jti，进不了黑名单，只能等它自然过期 (或者退出所有设备)
+func (s *Service) Logout(ctx context.Context, mc *jwt.MyClaims) error {
+	if mc.ExpiresAt != nil && mc.ID != "" {
+		if err := s.store.DenyToken(ctx, mc.ID, time.Until(mc.ExpiresAt.Time)); err != nil {
+			return err
+		}
+	}
+	if mc.FamilyID != "" {
+		if err := s.store.RevokeRefreshFamily(ctx, mc.FamilyID); err != nil {
+			return err
+		}
+		return s.store.DeleteSession(ctx, mc.UserID, mc.FamilyID)
+	}
+	return nil
+}
+
+// LogoutAll 退出所有设备：用户的 Token 代数 +1，之前签发的 Access / Refresh Token 全部失效
+func (s *Service) LogoutAll(ctx context.Context, userID int64) error {
+	if _, err := s.store.BumpTokenGeneration(ctx, userID); err != nil {
+		return err
+	}
+	// 会话列表也清空；各个 Refresh Token family 在刷新时会因为代数不对被拒绝，这里不用逐个吊销
+	_, err := s.store.DeleteUserSessions(ctx, userID)
+	return err
+}
+
+// buildResToken 签发 Access Token 并组装返回结构
+func (s *Service) buildResToken(mc jwt.MyClaims, refreshToken string) (*models.ResToken, error) {
+	accessToken, err := s.jwt.GenAccessToken(mc)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ResToken{
+		Token:        accessToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.jwt.AccessExpire().Seconds()),
+		Username:     mc.Username,
+	}, nil
+}
+
+// IssueAccessToken 不经过登录，直接给用户签发一张 Access Token (命令行 gen-token 调试接口用)
+// 不创建会话也不签 Refresh Token，过期后只能重新生成；"退出所有设备" 同样会让它失效
+func (s *Service) IssueAccessToken(ctx context.Context, userID int64) (*models.ResToken, error) {
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := s.store.GetTokenGeneration(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.userRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildResToken(jwt.MyClaims{
+		UserID:     userID,
+		Username:   user.Username,
+		Generation: gen,
+		Roles:      roles,
+	}, "")
+}
@@ -0,0 +1,241 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+// ErrorCommentForbidden 不能删除别人的评论
+var ErrorCommentForbidden = errors.New("没有操作该评论的权限")
+
+// PermissionCommentDelete 删除任意评论的全局权限
+const PermissionCommentDelete = "comment:delete"
+
+const (
+	// commentRepliesPerParent 评论树里每条评论最多展开几条回复，更多的回复用 GetCommentReplies 分页
+	commentRepliesPerParent = 5
+	// maxCommentTreeNodes 一次返回的评论树最多包含多少条评论，层数多的时候不至于成倍膨胀
+	maxCommentTreeNodes = 500
+)
+
+// CreateComment 发表评论，parent_id 不为 0 时是回复某条评论
+func (s *Service) CreateComment(ctx context.Context, userID, postID int64, p *models.ParamCreateComment) (*models.ResComment, error) {
+	if _, err := s.store.GetPostByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	// 被回复的评论必须在同一个帖子下面，而且没有被删除
+	if p.ParentID != 0 {
+		parent, err := s.store.GetCommentByID(ctx, p.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.PostID != postID || parent.Deleted {
+			return nil, dao.ErrorCommentNotFound
+		}
+	}
+	comment := &models.Comment{
+		CommentID: s.ids.GenID(),
+		PostID:    postID,
+		ParentID:  p.ParentID,
+		AuthorID:  userID,
+		Content:   p.Content,
+	}
+	if err := s.store.InsertComment(ctx, comment); err != nil {
+		return nil, err
+	}
+	if err := s.store.IncrPostCommentCount(ctx, postID, 1); err != nil {
+		s.log.Error("dao.IncrPostCommentCount failed", zap.Int64("post_id", postID), zap.Error(err))
+	}
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	res := buildComment(comment, user.Username)
+	res.Replies = []*models.ResComment{}
+	return res, nil
+}
+
+// DeleteComment 删除评论 (软删除，回复保留)
+// 作者本人、社区的创建者 / 版主、拥有 comment:delete 权限的人可以删除
+func (s *Service) DeleteComment(ctx context.Context, mc *jwt.MyClaims, commentID int64) error {
+	comment, err := s.store.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.Deleted {
+		return dao.ErrorCommentNotFound
+	}
+	if comment.AuthorID != mc.UserID {
+		post, err := s.store.GetPostByID(ctx, comment.PostID)
+		if err != nil {
+			return err
+		}
+		ok, err := s.canDeleteContent(ctx, mc, post.CommunityID, PermissionCommentDelete)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrorCommentForbidden
+		}
+	}
+	if err = s.store.SoftDeleteComment(ctx, commentID); err != nil {
+		return err
+	}
+	if err = s.store.IncrPostCommentCount(ctx, comment.PostID, -1); err != nil {
+		s.log.Error("dao.IncrPostCommentCount failed", zap.Int64("post_id", comment.PostID), zap.Error(err))
+	}
+	return nil
+}
+
+// GetCommentTree 帖子的评论树
+// 一级评论按游标分页，每一层的回复一次查出来，一共查 depth 次
+func (s *Service) GetCommentTree(ctx context.Context, postID int64, p *models.ParamCommentTree) (*models.ResCommentTree, error) {
+	if _, err := s.store.GetPostByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	p.Normalize()
+
+	cursor, err := s.cursor.Decode(p.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 一级评论，多取一条用来判断还有没有下一页
+	top, err := s.store.ListTopComments(ctx, postID, cursor, p.Size)
+	if err != nil {
+		return nil, err
+	}
+	top, page := common.NewCursorPage(s.cursor, top, p.Size, func(c models.Comment) common.Cursor {
+		return common.Cursor{ID: c.CommentID}
+	})
+
+	// 2. 展开回复
+	list, err := s.expandComments(ctx, top, p.Depth)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.store.GetPostCommentCounts(ctx, []int64{postID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ResCommentTree{
+		List:       list,
+		Total:      total[postID],
+		CursorPage: page,
+	}, nil
+}
+
+// GetCommentReplies 某条评论的直接回复，游标分页，用来加载评论树里没有展开的回复
+func (s *Service) GetCommentReplies(ctx context.Context, commentID int64, p *models.ParamCommentTree) (*models.ResCommentReplies, error) {
+	if _, err := s.store.GetCommentByID(ctx, commentID); err != nil {
+		return nil, err
+	}
+	p.Normalize()
+
+	cursor, err := s.cursor.Decode(p.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := s.store.ListReplies(ctx, commentID, cursor, p.Size)
+	if err != nil {
+		return nil, err
+	}
+	replies, page := common.NewCursorPage(s.cursor, replies, p.Size, func(c models.Comment) common.Cursor {
+		return common.Cursor{ID: c.CommentID}
+	})
+	list, err := s.expandComments(ctx, replies, p.Depth)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ResCommentReplies{List: list, CursorPage: page}, nil
+}
+
+// expandComments 从 roots 开始一层一层往下展开回复 (roots 算第一层)，每一层的回复一次查出来
+// 每条评论只展开前 commentRepliesPerParent 条回复，整棵树最多 maxCommentTreeNodes 条，
+// 没展开的回复靠 ReplyCount 告诉前端
+func (s *Service) expandComments(ctx context.Context, roots []models.Comment, depth int) ([]*models.ResComment, error) {
+	comments := roots
+	level := roots
+	var err error
+	for d := 1; d < depth && len(level) > 0 && len(comments) < maxCommentTreeNodes; d++ {
+		if level, err = s.store.ListCommentReplies(ctx, commentIDs(level), commentRepliesPerParent); err != nil {
+			return nil, err
+		}
+		level = level[:min(len(level), maxCommentTreeNodes-len(comments))]
+		comments = append(comments, level...)
+	}
+
+	// 补上回复数和作者名
+	replyCounts, err := s.store.CountCommentReplies(ctx, commentIDs(comments))
+	if err != nil {
+		return nil, err
+	}
+	authorIDs := make([]int64, 0, len(comments))
+	for _, c := range comments {
+		authorIDs = append(authorIDs, c.AuthorID)
+	}
+	users, err := s.repos.Users.ListByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[int64]string, len(users))
+	for _, u := range users {
+		usernames[u.UserID] = u.Username
+	}
+	return buildCommentTree(comments, replyCounts, usernames), nil
+}
+
+// buildCommentTree 把平铺的评论挂成树，comments 里父评论必须排在回复前面
+// parent_id 不在 comments 里的评论是这一页的根
+func buildCommentTree(comments []models.Comment, replyCounts map[int64]int64, usernames map[int64]string) []*models.ResComment {
+	nodes := make(map[int64]*models.ResComment, len(comments))
+	roots := make([]*models.ResComment, 0)
+	for i := range comments {
+		node := buildComment(&comments[i], usernames[comments[i].AuthorID])
+		node.ReplyCount = replyCounts[node.ID]
+		node.Replies = []*models.ResComment{}
+		nodes[node.ID] = node
+		if parent, ok := nodes[node.ParentID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// buildComment 已删除的评论隐藏内容和作者，只保留在树里的位置
+func buildComment(c *models.Comment, authorName string) *models.ResComment {
+	res := &models.ResComment{
+		ID:         c.CommentID,
+		PostID:     c.PostID,
+		ParentID:   c.ParentID,
+		AuthorID:   c.AuthorID,
+		AuthorName: authorName,
+		Content:    c.Content,
+		Deleted:    c.Deleted,
+		CreateTime: c.CreateTime,
+	}
+	if c.Deleted {
+		res.AuthorID = 0
+		res.AuthorName = ""
+		res.Content = models.DeletedCommentText
+	}
+	return res
+}
+
+func commentIDs(comments []models.Comment) []int64 {
+	ids := make([]int64, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.CommentID)
+	}
+	return ids
+}
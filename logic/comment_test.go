@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/models"
+)
+
+// TestBuildCommentTree 平铺的评论挂成树，已删除的评论保留位置但隐藏内容和作者
+func TestBuildCommentTree(t *testing.T) {
+	comments := []models.Comment{
+		{CommentID: 1, PostID: 9, AuthorID: 10, Content: "first"},
+		{CommentID: 2, PostID: 9, AuthorID: 11, Content: "second", Deleted: true},
+		{CommentID: 3, PostID: 9, ParentID: 1, AuthorID: 11, Content: "reply to first"},
+		{CommentID: 4, PostID: 9, ParentID: 2, AuthorID: 10, Content: "reply to deleted"},
+		{CommentID: 5, PostID: 9, ParentID: 3, AuthorID: 10, Content: "nested"},
+	}
+	replyCounts := map[int64]int64{1: 1, 2: 1, 3: 1, 5: 2}
+	usernames := map[int64]string{10: "alice", 11: "bob"}
+
+	roots := buildCommentTree(comments, replyCounts, usernames)
+	assert.Len(t, roots, 2)
+
+	first := roots[0]
+	assert.Equal(t, "alice", first.AuthorName)
+	assert.Len(t, first.Replies, 1)
+	assert.Equal(t, int64(3), first.Replies[0].ID)
+	assert.Equal(t, int64(5), first.Replies[0].Replies[0].ID)
+
+	// 超过展开层数的评论：Replies 为空，ReplyCount 告诉前端还有回复
+	nested := first.Replies[0].Replies[0]
+	assert.Empty(t, nested.Replies)
+	assert.Equal(t, int64(2), nested.ReplyCount)
+
+	deleted := roots[1]
+	assert.True(t, deleted.Deleted)
+	assert.Equal(t, models.DeletedCommentText, deleted.Content)
+	assert.Equal(t, int64(0), deleted.AuthorID)
+	assert.Empty(t, deleted.AuthorName)
+	assert.Equal(t, "reply to deleted", deleted.Replies[0].Content)
+}
@@ -0,0 +1,148 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+var (
+	// ErrorCommunityOwnerLeave 创建者不能退出自己的社区
+	ErrorCommunityOwnerLeave = errors.New("创建者不能退出社区")
+	// ErrorCommunityForbidden 没有管理这个社区的权限
+	ErrorCommunityForbidden = errors.New("没有管理该社区的权限")
+)
+
+// PermissionCommunityManage 管理任意社区 (任免版主、管理帖子评论) 的全局权限
+const PermissionCommunityManage = "community:manage"
+
+// CreateCommunity 创建社区，创建者自动成为社区的 owner
+func (s *Service) CreateCommunity(ctx context.Context, userID int64, p *models.ParamCreateCommunity) (*models.ResCommunity, error) {
+	if err := s.store.CheckCommunityExist(ctx, p.Name); err != nil {
+		return nil, err
+	}
+	community := &models.Community{
+		CommunityID:  s.ids.GenID(),
+		Name:         p.Name,
+		Introduction: p.Introduction,
+		CreatorID:    userID,
+	}
+	if err := s.store.InsertCommunity(ctx, community); err != nil {
+		return nil, err
+	}
+	return buildCommunity(community), nil
+}
+
+// ListCommunities 分页列出社区
+func (s *Service) ListCommunities(ctx context.Context, p *models.ParamPage) (*models.ResCommunityList, error) {
+	p.Normalize()
+	communities, total, err := s.store.ListCommunities(ctx, p.Offset(), p.Size)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*models.ResCommunity, 0, len(communities))
+	for i := range communities {
+		list = append(list, buildCommunity(&communities[i]))
+	}
+	return &models.ResCommunityList{List: list, Total: total, Page: p.Page, Size: p.Size}, nil
+}
+
+// GetCommunity 社区详情
+func (s *Service) GetCommunity(ctx context.Context, communityID int64) (*models.ResCommunityDetail, error) {
+	community, err := s.store.GetCommunityByID(ctx, communityID)
+	if err != nil {
+		return nil, err
+	}
+	moderators, err := s.store.ListCommunityModerators(ctx, communityID)
+	if err != nil {
+		return nil, err
+	}
+	if moderators == nil {
+		moderators = []*models.ResCommunityMember{}
+	}
+	return &models.ResCommunityDetail{ResCommunity: *buildCommunity(community), Moderators: moderators}, nil
+}
+
+// JoinCommunity 加入社区
+func (s *Service) JoinCommunity(ctx context.Context, userID, communityID int64) error {
+	if _, err := s.store.GetCommunityByID(ctx, communityID); err != nil {
+		return err
+	}
+	return s.store.AddCommunityMember(ctx, communityID, userID)
+}
+
+// LeaveCommunity 退出社区
+func (s *Service) LeaveCommunity(ctx context.Context, userID, communityID int64) error {
+	member, err := s.store.GetCommunityMember(ctx, communityID, userID)
+	if errors.Is(err, dao.ErrorNotCommunityMember) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if member.Role == models.CommunityRoleOwner {
+		return ErrorCommunityOwnerLeave
+	}
+	return s.store.RemoveCommunityMember(ctx, communityID, userID)
+}
+
+// SetCommunityModerator 任免版主 (moderator 为 false 表示撤销)
+// 只有社区创建者或者拥有 community:manage 权限的人可以操作，被任命的人必须已经是社区成员
+func (s *Service) SetCommunityModerator(ctx context.Context, mc *jwt.MyClaims, communityID, userID int64, moderator bool) error {
+	if _, err := s.store.GetCommunityByID(ctx, communityID); err != nil {
+		return err
+	}
+	ok, err := s.hasCommunityRole(ctx, mc, communityID, models.CommunityRoleOwner)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrorCommunityForbidden
+	}
+
+	target, err := s.store.GetCommunityMember(ctx, communityID, userID)
+	if err != nil {
+		return err
+	}
+	// 创建者的身份不能被改掉
+	if target.Role == models.CommunityRoleOwner {
+		return ErrorCommunityForbidden
+	}
+	role := models.CommunityRoleMember
+	if moderator {
+		role = models.CommunityRoleModerator
+	}
+	return s.store.SetCommunityMemberRole(ctx, communityID, userID, role)
+}
+
+// CanModerateCommunity 是否可以管理社区里的内容 (创建者、版主或者拥有 community:manage 权限)
+func (s *Service) CanModerateCommunity(ctx context.Context, mc *jwt.MyClaims, communityID int64) (bool, error) {
+	return s.hasCommunityRole(ctx, mc, communityID, models.CommunityRoleOwner, models.CommunityRoleModerator)
+}
+
+// hasCommunityRole 在社区里是 roles 中的某个身份，或者拥有 community:manage 全局权限
+func (s *Service) hasCommunityRole(ctx context.Context, mc *jwt.MyClaims, communityID int64, roles ...string) (bool, error) {
+	member, err := s.store.GetCommunityMember(ctx, communityID, mc.UserID)
+	if err == nil && slices.Contains(roles, member.Role) {
+		return true, nil
+	}
+	if err != nil && !errors.Is(err, dao.ErrorNotCommunityMember) {
+		return false, err
+	}
+	return s.HasPermission(ctx, mc, PermissionCommunityManage)
+}
+
+// buildCommunity 数据库里的社区转成接口返回的格式
+func buildCommunity(c *models.Community) *models.ResCommunity {
+	return &models.ResCommunity{
+		ID:           c.CommunityID,
+		Name:         c.Name,
+		Introduction: c.Introduction,
+		MemberCount:  c.MemberCount,
+		CreateTime:   c.CreateTime,
+	}
+}
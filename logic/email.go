@@ -0,0 +1,201 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+	"gin-api-scaffold-v1/pkg/mailer"
+)
+
+var (
+	ErrorInvalidEmailToken    = errors.New("链接无效或已过期")
+	ErrorEmailAlreadyVerified = errors.New("邮箱已验证")
+)
+
+// SendVerifyEmail 重新发送验证邮件 (登录后调用)
+func (s *Service) SendVerifyEmail(ctx context.Context, userID int64) error {
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return ErrorEmailAlreadyVerified
+	}
+	return s.sendVerifyEmail(ctx, user)
+}
+
+// VerifyEmail 点击验证邮件里的链接
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	// 1. 校验签名、类型、过期时间
+	mc, err := s.jwt.ParseEmailToken(token, jwt.TokenTypeVerifyEmail)
+	if err != nil {
+		return ErrorInvalidEmailToken
+	}
+
+	// 2. 发信之后改过邮箱，旧链接不能用来验证新邮箱
+	user, err := s.repos.Users.GetByID(ctx, mc.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email != mc.Email {
+		return ErrorInvalidEmailToken
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	// 3. 核销链接
+	if err = s.consumeEmailToken(ctx, mc); err != nil {
+		return err
+	}
+	return s.repos.Users.SetEmailVerified(ctx, user.UserID)
+}
+
+// ForgotPassword 给邮箱发送重置密码链接
+// ⚠️ 邮箱不存在、发送太频繁都当作成功返回，否则这个接口就能用来探测哪些邮箱注册过
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.repos.Users.GetByEmail(ctx, email)
+	if errors.Is(err, dao.ErrorUserNotFound) {
+		s.log.Info("forgot password for unknown email", zap.String("email", email))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.sendEmailToken(ctx, jwt.TokenTypeResetPassword, user, s.resetPasswordExpire(),
+		"重置密码", "reset-password",
+		"你正在重置 %s 的密码，请在 %s 内点击下面的链接设置新密码：\n\n%s\n\n如果不是你本人操作，请忽略这封邮件，你的密码不会被修改。")
+}
+
+// ResetPassword 通过邮件链接重置密码
+func (s *Service) ResetPassword(ctx context.Context, p *models.ParamResetPassword) error {
+	// 1. 校验链接
+	mc, err := s.jwt.ParseEmailToken(p.Token, jwt.TokenTypeResetPassword)
+	if err != nil {
+		return ErrorInvalidEmailToken
+	}
+	user, err := s.repos.Users.GetByID(ctx, mc.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email != mc.Email {
+		return ErrorInvalidEmailToken
+	}
+	if err = s.consumeEmailToken(ctx, mc); err != nil {
+		return err
+	}
+
+	// 2. 保存新密码
+	password, err := s.hasher().Hash(p.Password)
+	if err != nil {
+		return err
+	}
+	if err = s.repos.Users.UpdatePassword(ctx, user.UserID, password); err != nil {
+		return err
+	}
+
+	// 3. 密码可能已经泄露：踢掉所有已登录的设备，同时解除因为别人乱试密码导致的锁定
+	if err = s.LogoutAll(ctx, user.UserID); err != nil {
+		s.log.Error("logout all after reset password failed", zap.Int64("user_id", user.UserID), zap.Error(err))
+	}
+	if err = s.store.ClearLoginFailures(ctx, dao.LoginSubjectUser, user.Username); err != nil {
+		s.log.Warn("clear login failures failed", zap.String("username", user.Username), zap.Error(err))
+	}
+
+	// 4. 能收到重置邮件，说明邮箱确实是本人的
+	if !user.EmailVerified {
+		if err = s.repos.Users.SetEmailVerified(ctx, user.UserID); err != nil {
+			s.log.Warn("set email verified failed", zap.Int64("user_id", user.UserID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// sendVerifyEmail 给用户发送验证邮件
+func (s *Service) sendVerifyEmail(ctx context.Context, user *models.User) error {
+	return s.sendEmailToken(ctx, jwt.TokenTypeVerifyEmail, user, s.verifyEmailExpire(),
+		"验证你的邮箱", "verify-email",
+		"%s，欢迎注册！请在 %s 内点击下面的链接验证你的邮箱：\n\n%s\n\n如果不是你本人操作，请忽略这封邮件。")
+}
+
+// sendEmailToken 签发一次性链接并发信
+// format 的三个参数依次是：用户名、有效期、链接
+func (s *Service) sendEmailToken(ctx context.Context, tokenType string, user *models.User, expire time.Duration, subject, path, format string) error {
+	// 1. 冷却期内不重复发信
+	ok, err := s.store.AllowMailSend(ctx, tokenType, user.Email, s.mailInterval())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		s.log.Info("mail throttled", zap.String("type", tokenType), zap.String("email", user.Email))
+		return nil
+	}
+
+	// 2. 签发 Token，jti 记到 Redis 里，之前发过的链接随之作废
+	token, jti, err := s.jwt.GenEmailToken(tokenType, user.UserID, user.Email, expire)
+	if err != nil {
+		return err
+	}
+	if err = s.store.SaveEmailToken(ctx, tokenType, user.UserID, jti, expire); err != nil {
+		return err
+	}
+
+	// 3. 异步发信，SMTP 慢或者挂了都不影响接口响应
+	link := fmt.Sprintf("%s/%s?token=%s", strings.TrimRight(s.cfg.GetString("mail.link_base_url"), "/"), path, url.QueryEscape(token))
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf(format, user.Username, expire, link),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			s.log.Error("send mail failed", zap.String("type", tokenType), zap.String("to", msg.To), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// consumeEmailToken 核销链接，用过的、被新链接替换掉的都算无效
+func (s *Service) consumeEmailToken(ctx context.Context, mc *jwt.MyClaims) error {
+	ok, err := s.store.ConsumeEmailToken(ctx, mc.TokenType, mc.UserID, mc.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrorInvalidEmailToken
+	}
+	return nil
+}
+
+// verifyEmailExpire 验证邮件链接有效期 (auth.verify_email_expire，单位小时，默认 24 小时)
+func (s *Service) verifyEmailExpire() time.Duration {
+	if h := s.cfg.GetInt("auth.verify_email_expire"); h > 0 {
+		return time.Duration(h) * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// resetPasswordExpire 重置密码链接有效期 (auth.reset_password_expire，单位分钟，默认 30 分钟)
+func (s *Service) resetPasswordExpire() time.Duration {
+	if m := s.cfg.GetInt("auth.reset_password_expire"); m > 0 {
+		return time.Duration(m) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+// mailInterval 同一邮箱两封同类邮件的最小间隔 (mail.interval，单位秒，默认 60 秒)
+func (s *Service) mailInterval() time.Duration {
+	if n := s.cfg.GetInt("mail.interval"); n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	return time.Minute
+}
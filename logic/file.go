@@ -0,0 +1,202 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/storage"
+)
+
+var (
+	ErrorFileTooLarge       = errors.New("文件太大")
+	ErrorFileTypeNotAllowed = errors.New("不支持的文件类型")
+)
+
+// fileExtensions 支持上传的文件类型 (按内容识别) 和保存时用的扩展名
+var fileExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// avatarTypes 头像只允许图片
+var avatarTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// storageTimeout 读写存储的超时时间
+const storageTimeout = 30 * time.Second
+
+// UploadFile 上传普通文件，返回文件信息和限时下载地址
+func (s *Service) UploadFile(ctx context.Context, userID int64, r io.Reader) (*models.ResFile, error) {
+	file, err := s.saveFile(ctx, userID, r, s.MaxUploadSize(), s.uploadAllowedTypes())
+	if err != nil {
+		return nil, err
+	}
+	url, err := s.storage.SignedURL(file.Key, s.fileURLExpire())
+	if err != nil {
+		return nil, err
+	}
+	return &models.ResFile{Hash: file.Hash, Size: file.Size, MimeType: file.MimeType, URL: url}, nil
+}
+
+// UploadAvatar 上传头像，返回修改后的个人资料
+// 旧头像文件不删除：内容去重之后同一个文件可能还被别人用着
+func (s *Service) UploadAvatar(ctx context.Context, userID int64, r io.Reader) (*models.ResProfile, error) {
+	file, err := s.saveFile(ctx, userID, r, s.MaxAvatarSize(), avatarTypes)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repos.Users.UpdateProfile(ctx, userID, map[string]interface{}{"avatar": file.Key}); err != nil {
+		return nil, err
+	}
+	return s.GetProfile(ctx, userID)
+}
+
+// DeleteAvatar 删除头像 (恢复成默认头像)
+func (s *Service) DeleteAvatar(ctx context.Context, userID int64) (*models.ResProfile, error) {
+	if err := s.repos.Users.UpdateProfile(ctx, userID, map[string]interface{}{"avatar": ""}); err != nil {
+		return nil, err
+	}
+	return s.GetProfile(ctx, userID)
+}
+
+// OpenFile 校验下载链接后打开文件 (只有本地存储会走到这里，S3 的链接直接指向对象存储)
+// 返回文件内容和 Content-Type
+func (s *Service) OpenFile(ctx context.Context, key, expires, signature string) (io.ReadCloser, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+	rc, err := storage.OpenSigned(ctx, s.storage, key, expires, signature)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidSignature) {
+		return nil, "", dao.ErrorFileNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return rc, contentType, nil
+}
+
+// MaxUploadSize 普通文件的大小上限 (字节)，upload.max_size 单位 MB，默认 10MB
+func (s *Service) MaxUploadSize() int64 {
+	if n := s.cfg.GetInt64("upload.max_size"); n > 0 {
+		return n << 20
+	}
+	return 10 << 20
+}
+
+// MaxAvatarSize 头像的大小上限 (字节)，upload.avatar_max_size 单位 MB，默认 2MB
+func (s *Service) MaxAvatarSize() int64 {
+	if n := s.cfg.GetInt64("upload.avatar_max_size"); n > 0 {
+		return n << 20
+	}
+	return 2 << 20
+}
+
+// saveFile 校验大小和类型，按内容去重后保存
+func (s *Service) saveFile(ctx context.Context, userID int64, r io.Reader, maxSize int64, allowed []string) (*models.File, error) {
+	// 1. 读进内存，多读 1 个字节用来判断是否超过上限
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrorFileTooLarge
+	}
+
+	// 2. 按文件内容识别类型，客户端传的文件名和 Content-Type 都可以伪造
+	mimeType := detectFileType(data)
+	if !slices.Contains(allowed, mimeType) {
+		return nil, ErrorFileTypeNotAllowed
+	}
+
+	// 3. 同样的内容已经存过了，直接复用
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	file, err := s.store.GetFileByHash(ctx, hash)
+	if err == nil {
+		return file, nil
+	}
+	if !errors.Is(err, dao.ErrorFileNotFound) {
+		return nil, err
+	}
+
+	// 4. 按哈希决定存储位置，先存文件再记录到数据库
+	file = &models.File{
+		Hash:       hash,
+		Key:        fmt.Sprintf("files/%s/%s%s", hash[:2], hash, fileExtensions[mimeType]),
+		Size:       int64(len(data)),
+		MimeType:   mimeType,
+		UploaderID: userID,
+	}
+	ctx, cancel := context.WithTimeout(ctx, storageTimeout)
+	defer cancel()
+	if err = s.storage.Put(ctx, file.Key, bytes.NewReader(data), file.Size, mimeType); err != nil {
+		return nil, err
+	}
+	if err = s.store.InsertFile(ctx, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// detectFileType 按内容识别文件类型，去掉 "; charset=..." 之类的参数
+func detectFileType(data []byte) string {
+	mimeType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	return mimeType
+}
+
+// avatarURL 头像的限时访问地址，签名失败只打日志，不影响查询资料
+func (s *Service) avatarURL(key string) string {
+	if key == "" {
+		return ""
+	}
+	url, err := s.storage.SignedURL(key, s.fileURLExpire())
+	if err != nil {
+		s.log.Error("sign avatar url failed", zap.String("key", key), zap.Error(err))
+		return ""
+	}
+	return url
+}
+
+// uploadAllowedTypes 允许上传的文件类型 (upload.allowed_types)，只认 fileExtensions 里有的
+func (s *Service) uploadAllowedTypes() []string {
+	var types []string
+	for _, t := range s.cfg.GetStringSlice("upload.allowed_types") {
+		if _, ok := fileExtensions[t]; ok {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		for t := range fileExtensions {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// fileURLExpire 下载链接有效期，storage.url_expire 单位分钟，默认 60 分钟
+func (s *Service) fileURLExpire() time.Duration {
+	if n := s.cfg.GetInt("storage.url_expire"); n > 0 {
+		return time.Duration(n) * time.Minute
+	}
+	return time.Hour
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSaveFileValidation 超过大小、类型不对的文件在写存储和数据库之前就被拒绝
+func TestSaveFileValidation(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	assert.Equal(t, "image/png", detectFileType(png))
+
+	_, err := s.saveFile(ctx, 1, bytes.NewReader(png), int64(len(png)-1), avatarTypes)
+	assert.ErrorIs(t, err, ErrorFileTooLarge)
+
+	// 不管客户端声称是什么类型，按内容识别出来是网页就拒绝
+	_, err = s.saveFile(ctx, 1, strings.NewReader("<html><script>alert(1)</script></html>"), 1<<20, avatarTypes)
+	assert.ErrorIs(t, err, ErrorFileTypeNotAllowed)
+
+	_, err = s.saveFile(ctx, 1, bytes.NewReader(nil), 1<<20, avatarTypes)
+	assert.ErrorIs(t, err, ErrorFileTypeNotAllowed)
+}
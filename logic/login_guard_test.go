@@ -0,0 +1,25 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/dao"
+)
+
+// TestLoginPolicyDefaults 只配了阈值时，窗口和锁定时长使用默认值
+func TestLoginPolicyDefaults(t *testing.T) {
+	s := newTestService(t)
+	s.cfg.Set("login_limit.max_attempts", 5)
+
+	p := s.loginPolicy(dao.LoginSubjectUser)
+	assert.Equal(t, int64(5), p.MaxAttempts)
+	assert.Equal(t, 15*time.Minute, p.Window)
+	assert.Equal(t, time.Minute, p.LockBase)
+	assert.Equal(t, time.Hour, p.LockMax)
+
+	s.cfg.Set("login_limit.lock_base", 30)
+	assert.Equal(t, 30*time.Second, s.loginPolicy(dao.LoginSubjectUser).LockBase)
+}
@@ -0,0 +1,259 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/totp"
+)
+
+// recoveryCodeCount 每次生成多少个恢复码
+const recoveryCodeCount = 10
+
+var (
+	ErrorInvalidMFACode  = errors.New("验证码错误")
+	ErrorMFANotEnabled   = errors.New("未开启两步验证")
+	ErrorInvalidMFAToken = errors.New("两步验证凭证无效或已过期")
+)
+
+// EnrollMFA 开始绑定两步验证：生成新密钥，返回 otpauth:// 地址
+// 此时还没有生效，需要用 App 上的验证码调用 ConfirmMFA 确认
+// 已经开启了两步验证时是换绑，必须先提供当前的验证码或一个恢复码，否则偷到 Access Token 就能把验证器换成自己的
+func (s *Service) EnrollMFA(ctx context.Context, userID int64, username, code string, client models.ClientInfo) (*models.ResMFAEnroll, error) {
+	mfa, err := s.getEnabledMFA(ctx, userID)
+	switch {
+	case err == nil:
+		if err = s.verifyMFACode(ctx, userID, username, mfa.Secret, code, true, client); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, ErrorMFANotEnabled):
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repos.MFA.SavePendingSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+	return &models.ResMFAEnroll{
+		Secret: secret,
+		URI:    totp.URI(s.mfaIssuer(), username, secret),
+	}, nil
+}
+
+// ConfirmMFA 确认绑定：验证码正确则启用两步验证，并返回一批新的恢复码
+func (s *Service) ConfirmMFA(ctx context.Context, userID int64, username, code string, client models.ClientInfo) (*models.ResRecoveryCodes, error) {
+	mfa, err := s.repos.MFA.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.PendingSecret == "" {
+		return nil, dao.ErrorMFANotFound
+	}
+	if err = s.verifyMFACode(ctx, userID, username, mfa.PendingSecret, code, false, client); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repos.MFA.Enable(ctx, userID, mfa.PendingSecret, hashes); err != nil {
+		return nil, err
+	}
+	return &models.ResRecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码 (旧的全部作废)，需要当前的验证码
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64, username, code string, client models.ClientInfo) (*models.ResRecoveryCodes, error) {
+	mfa, err := s.getEnabledMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.verifyMFACode(ctx, userID, username, mfa.Secret, code, false, client); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repos.MFA.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return &models.ResRecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// LoginMFA 两步登录第二步：用临时 Token + 验证码 (或恢复码) 换正式 Token
+func (s *Service) LoginMFA(ctx context.Context, p *models.ParamLoginMFA, client models.ClientInfo) (*models.ResToken, error) {
+	// 1. 校验临时 Token
+	mc, err := s.jwt.ParseMFAToken(p.MFAToken)
+	if err != nil {
+		return nil, ErrorInvalidMFAToken
+	}
+
+	// 2. 验证码也会被爆破，和密码共用同一套失败计数和锁定
+	if err = s.checkLoginLocked(ctx, mc.Username, client.IP); err != nil {
+		return nil, err
+	}
+
+	mfa, err := s.getEnabledMFA(ctx, mc.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 6 位数字按 TOTP 校验，其他格式当作恢复码
+	if isTOTPCode(p.Code) {
+		err = s.verifyTOTP(ctx, mc.UserID, mfa.Secret, p.Code)
+	} else {
+		err = s.useRecoveryCode(ctx, mc.UserID, p.Code)
+	}
+	if err != nil {
+		if errors.Is(err, ErrorInvalidMFACode) {
+			s.recordLoginFailure(ctx, mc.Username, client.IP)
+		}
+		return nil, err
+	}
+
+	// 4. 临时 Token 只能用一次
+	first, err := s.store.MarkTokenUsed(ctx, mc.ID, s.jwt.MFAExpire())
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		return nil, ErrorInvalidMFAToken
+	}
+
+	if err = s.store.ClearLoginFailures(ctx, dao.LoginSubjectUser, mc.Username); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, mc.UserID, mc.Username, client)
+}
+
+// mfaRequired 用户是否开启了两步验证
+func (s *Service) mfaRequired(ctx context.Context, userID int64) (bool, error) {
+	_, err := s.getEnabledMFA(ctx, userID)
+	if errors.Is(err, ErrorMFANotEnabled) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *Service) getEnabledMFA(ctx context.Context, userID int64) (*models.UserMFA, error) {
+	mfa, err := s.repos.MFA.Get(ctx, userID)
+	if errors.Is(err, dao.ErrorMFANotFound) {
+		return nil, ErrorMFANotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.Enabled || mfa.Secret == "" {
+		return nil, ErrorMFANotEnabled
+	}
+	return mfa, nil
+}
+
+// verifyMFACode 已登录用户的敏感操作 (换绑、确认绑定、重新生成恢复码) 校验验证码
+// allowRecovery 为 true 时不是 6 位数字的当作恢复码；和登录共用同一套失败计数和锁定，防止被爆破
+func (s *Service) verifyMFACode(ctx context.Context, userID int64, username, secret, code string, allowRecovery bool, client models.ClientInfo) error {
+	if err := s.checkLoginLocked(ctx, username, client.IP); err != nil {
+		return err
+	}
+	var err error
+	switch {
+	case code == "":
+		err = ErrorInvalidMFACode
+	case allowRecovery && !isTOTPCode(code):
+		err = s.useRecoveryCode(ctx, userID, code)
+	default:
+		err = s.verifyTOTP(ctx, userID, secret, code)
+	}
+	if errors.Is(err, ErrorInvalidMFACode) {
+		s.recordLoginFailure(ctx, username, client.IP)
+	}
+	return err
+}
+
+// verifyTOTP 校验验证码，同一个时间片的验证码只能用一次
+func (s *Service) verifyTOTP(ctx context.Context, userID int64, secret, code string) error {
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return ErrorInvalidMFACode
+	}
+	// 记录保留到这个时间片彻底过了允许的偏差范围
+	first, err := s.store.MarkTOTPStepUsed(ctx, userID, step, (2*totp.Skew+1)*totp.Period)
+	if err != nil {
+		return err
+	}
+	if !first {
+		return ErrorInvalidMFACode
+	}
+	return nil
+}
+
+// useRecoveryCode 使用恢复码
+func (s *Service) useRecoveryCode(ctx context.Context, userID int64, code string) error {
+	ok, err := s.repos.MFA.ConsumeRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrorInvalidMFACode
+	}
+	return nil
+}
+
+// generateRecoveryCodes 生成恢复码，格式 xxxxx-xxxxx (小写 base32，50 bit 熵)
+// 返回明文 (给用户看一次) 和哈希 (存库)
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err = rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		s := strings.ToLower(enc.EncodeToString(b))[:10]
+		code := s[:5] + "-" + s[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode 恢复码熵足够高，用 SHA-256 即可，不需要慢哈希
+// 先统一大小写、去掉分隔符，用户输入时不用在意格式
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// mfaIssuer 显示在验证器 App 里的名字
+func (s *Service) mfaIssuer() string {
+	if issuer := s.cfg.GetString("auth.mfa_issuer"); issuer != "" {
+		return issuer
+	}
+	return s.cfg.GetString("app.name")
+}
@@ -0,0 +1,58 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/totp"
+)
+
+// TestReEnrollMFA 已经开启两步验证时，换绑必须提供当前的验证码或恢复码
+func TestReEnrollMFA(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	client := models.ClientInfo{IP: "10.0.0.1"}
+
+	// 1. 第一次绑定不需要验证码
+	enroll, err := s.EnrollMFA(ctx, 7, "bob", "", client)
+	assert.NoError(t, err)
+	code, _ := totp.GenerateCode(enroll.Secret, time.Now())
+	res, err := s.ConfirmMFA(ctx, 7, "bob", code, client)
+	assert.NoError(t, err)
+
+	// 2. 只有 Access Token 不能换绑，当前密钥保持不变
+	_, err = s.EnrollMFA(ctx, 7, "bob", "", client)
+	assert.ErrorIs(t, err, ErrorInvalidMFACode)
+	_, err = s.EnrollMFA(ctx, 7, "bob", "00000-00000", client)
+	assert.ErrorIs(t, err, ErrorInvalidMFACode)
+	mfa, _ := s.repos.MFA.Get(ctx, 7)
+	assert.Equal(t, enroll.Secret, mfa.Secret)
+	assert.Empty(t, mfa.PendingSecret)
+
+	// 3. 用恢复码证明持有第二因素后可以换绑
+	again, err := s.EnrollMFA(ctx, 7, "bob", res.RecoveryCodes[0], client)
+	assert.NoError(t, err)
+	assert.NotEqual(t, enroll.Secret, again.Secret)
+}
+
+// TestMFACodeLockout 确认绑定时猜错验证码和登录共用失败计数，错太多次被锁定
+func TestMFACodeLockout(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	s.cfg.Set("login_limit.max_attempts", 3)
+	client := models.ClientInfo{IP: "10.0.0.1"}
+
+	_, err := s.EnrollMFA(ctx, 7, "bob", "", client)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = s.ConfirmMFA(ctx, 7, "bob", "000000", client)
+		assert.ErrorIs(t, err, ErrorInvalidMFACode)
+	}
+	var locked *LoginLockedError
+	_, err = s.ConfirmMFA(ctx, 7, "bob", "000000", client)
+	assert.ErrorAs(t, err, &locked)
+}
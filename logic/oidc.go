@@ -0,0 +1,203 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/oidc"
+	"gin-api-scaffold-v1/pkg/validator"
+)
+
+const (
+	// oidcStateExpire 从跳转到身份提供方到回调，最多允许这么久
+	oidcStateExpire = 10 * time.Minute
+	// oidcTimeout 请求身份提供方 (discovery、换 Token) 的超时时间
+	oidcTimeout = 10 * time.Second
+)
+
+var (
+	ErrorUnknownOIDCProvider = errors.New("不支持的登录方式")
+	ErrorInvalidOIDCState    = errors.New("第三方登录已过期，请重新登录")
+	ErrorOIDCLoginFailed     = errors.New("第三方登录失败")
+	// ErrorOIDCEmailConflict 邮箱已经被一个未验证邮箱的本地账号占用
+	// 不能直接绑定：否则别人先用你的邮箱注册一个账号，等你用第三方登录时就绑进了他的账号
+	ErrorOIDCEmailConflict = errors.New("该邮箱已注册，请先用密码登录并验证邮箱")
+)
+
+// OIDCAuthURL 发起第三方登录：生成 state / nonce / PKCE，返回身份提供方的登录地址
+func (s *Service) OIDCAuthURL(ctx context.Context, providerName string) (*models.ResOIDCAuthURL, error) {
+	ctx, cancel := context.WithTimeout(ctx, oidcTimeout)
+	defer cancel()
+	p, err := s.getOIDCProvider(ctx, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+	st := &models.OIDCState{Provider: providerName, Verifier: oidc.NewVerifier(), Nonce: nonce}
+	if err = s.store.SaveOIDCState(ctx, state, st, oidcStateExpire); err != nil {
+		return nil, err
+	}
+	return &models.ResOIDCAuthURL{AuthURL: p.AuthCodeURL(state, st.Nonce, st.Verifier)}, nil
+}
+
+// OIDCLogin 第三方登录回调：换 Token、校验 ID Token，找到 (或创建) 对应的本地用户后签发我们自己的 Token
+func (s *Service) OIDCLogin(ctx context.Context, providerName string, p *models.ParamOIDCCallback, client models.ClientInfo) (*models.ResToken, error) {
+	// 1. state 必须是我们发出去的，并且只能用一次
+	st, err := s.store.TakeOIDCState(ctx, p.State)
+	if errors.Is(err, dao.ErrorOIDCStateNotFound) {
+		return nil, ErrorInvalidOIDCState
+	}
+	if err != nil {
+		return nil, err
+	}
+	if st.Provider != providerName {
+		return nil, ErrorInvalidOIDCState
+	}
+
+	// 2. 授权码 + PKCE verifier 换 Token，校验 ID Token
+	ctx, cancel := context.WithTimeout(ctx, oidcTimeout)
+	defer cancel()
+	provider, err := s.getOIDCProvider(ctx, providerName)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := provider.Exchange(ctx, p.Code, st.Verifier, st.Nonce)
+	if err != nil {
+		s.log.Warn("oidc exchange failed", zap.String("provider", providerName), zap.Error(err))
+		return nil, ErrorOIDCLoginFailed
+	}
+
+	// 3. 找到对应的本地用户
+	user, err := s.resolveOIDCUser(ctx, providerName, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. 后面和密码登录一样 (两步验证、签发 Token)
+	return s.finishLogin(ctx, user, client)
+}
+
+// resolveOIDCUser 第三方身份 -> 本地用户
+// 1. 已经绑定过：直接用绑定的用户
+// 2. 邮箱已验证且和某个本地用户 (邮箱也已验证) 一致：自动绑定到这个用户
+// 3. 都没有：新建一个用户
+func (s *Service) resolveOIDCUser(ctx context.Context, provider string, id *oidc.Identity) (*models.User, error) {
+	identity, err := s.store.GetUserIdentity(ctx, provider, id.Subject)
+	if err == nil {
+		return s.repos.Users.GetByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, dao.ErrorIdentityNotFound) {
+		return nil, err
+	}
+
+	binding := &models.UserIdentity{Provider: provider, Subject: id.Subject, Email: id.Email}
+	if id.Email != "" {
+		user, err := s.repos.Users.GetByEmail(ctx, id.Email)
+		switch {
+		case err == nil:
+			if !id.EmailVerified || !user.EmailVerified {
+				return nil, ErrorOIDCEmailConflict
+			}
+			binding.UserID = user.UserID
+			if err = s.store.InsertUserIdentity(ctx, binding); err != nil {
+				return nil, err
+			}
+			s.log.Info("oidc identity linked by email",
+				zap.String("provider", provider), zap.Int64("user_id", user.UserID))
+			return user, nil
+		case !errors.Is(err, dao.ErrorUserNotFound):
+			return nil, err
+		}
+	}
+	return s.createOIDCUser(ctx, binding, id)
+}
+
+// createOIDCUser 第三方首次登录，新建本地用户
+// 密码是随机的，用户想用密码登录可以走找回密码设置一个
+func (s *Service) createOIDCUser(ctx context.Context, binding *models.UserIdentity, id *oidc.Identity) (*models.User, error) {
+	username, err := s.uniqueUsername(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	random := make([]byte, 32)
+	if _, err = rand.Read(random); err != nil {
+		return nil, err
+	}
+	password, err := s.hasher().Hash(hex.EncodeToString(random))
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		UserID:        s.ids.GenID(),
+		Username:      username,
+		Password:      password,
+		Email:         id.Email,
+		EmailVerified: id.Email != "" && id.EmailVerified,
+	}
+	if err = s.store.InsertUserWithIdentity(ctx, user, binding); err != nil {
+		return nil, err
+	}
+	s.indexUser(ctx, user)
+	return user, nil
+}
+
+// uniqueUsername 优先用身份提供方给的用户名，其次邮箱前缀；重名就加随机后缀
+// 和注册接口的用户名规则一致：不允许的字符换成下划线，留出随机后缀的长度，太短就用 user
+func (s *Service) uniqueUsername(ctx context.Context, id *oidc.Identity) (string, error) {
+	base := id.Username
+	if base == "" {
+		base, _, _ = strings.Cut(id.Email, "@")
+	}
+	base = strings.Map(func(r rune) rune {
+		if validator.IsUsernameRune(r) {
+			return r
+		}
+		return '_'
+	}, base)
+	base = strings.Trim(base[:min(len(base), validator.UsernameMaxLen-len("_0000"))], "_.-")
+	if len(base) < validator.UsernameMinLen {
+		base = "user"
+	}
+	name := base
+	for i := 0; i < 5; i++ {
+		err := s.repos.Users.CheckUserExist(ctx, name)
+		if err == nil {
+			return name, nil
+		}
+		if !errors.Is(err, dao.ErrorUserExist) {
+			return "", err
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", err
+		}
+		name = fmt.Sprintf("%s_%04d", base, n.Int64())
+	}
+	return "", dao.ErrorUserExist
+}
+
+func (s *Service) getOIDCProvider(ctx context.Context, name string) (*oidc.Provider, error) {
+	p, err := s.oidc.Get(ctx, name)
+	if errors.Is(err, oidc.ErrUnknownProvider) {
+		return nil, ErrorUnknownOIDCProvider
+	}
+	return p, err
+}
@@ -0,0 +1,32 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/oidc"
+)
+
+// TestUniqueUsername 第三方登录生成的用户名也要符合注册接口的规则
+func TestUniqueUsername(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	name, err := s.uniqueUsername(ctx, &oidc.Identity{Username: "张 三", Email: "john.doe@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "user", name, "全是不允许的字符，替换后就不剩什么了")
+
+	name, _ = s.uniqueUsername(ctx, &oidc.Identity{Email: "john.doe+news@example.com"})
+	assert.Equal(t, "john.doe_news", name)
+
+	long := "abcdefghijklmnopqrstuvwxyz0123456789"
+	name, _ = s.uniqueUsername(ctx, &oidc.Identity{Username: long})
+	assert.Equal(t, long[:27], name, "留出随机后缀的长度")
+
+	assert.NoError(t, s.repos.Users.Insert(ctx, &models.User{UserID: 1, Username: "alice", Email: "alice@example.com"}))
+	name, _ = s.uniqueUsername(ctx, &oidc.Identity{Username: "alice"})
+	assert.Regexp(t, `^alice_\d{4}$`, name)
+}
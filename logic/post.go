@@ -0,0 +1,235 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+// ErrorPostForbidden 不能编辑 / 删除别人的帖子
+var ErrorPostForbidden = errors.New("没有操作该帖子的权限")
+
+// PermissionPostDelete 删除任意帖子的全局权限
+const PermissionPostDelete = "post:delete"
+
+// CreatePost 发帖
+func (s *Service) CreatePost(ctx context.Context, userID int64, p *models.ParamCreatePost) (*models.ResPost, error) {
+	if _, err := s.store.GetCommunityByID(ctx, p.CommunityID); err != nil {
+		return nil, err
+	}
+	post := &models.Post{
+		PostID:      s.ids.GenID(),
+		AuthorID:    userID,
+		CommunityID: p.CommunityID,
+		Title:       p.Title,
+		Content:     p.Content,
+	}
+	if err := s.store.InsertPost(ctx, post); err != nil {
+		return nil, err
+	}
+	// 帖子已经保存成功，排行写失败只打日志 (帖子仍然可以通过 ID 访问)
+	if err := s.store.CreatePostIndex(ctx, post.PostID, post.CommunityID, post.CreateTime); err != nil {
+		s.log.Error("dao.CreatePostIndex failed", zap.Int64("post_id", post.PostID), zap.Error(err))
+	}
+	s.indexPost(ctx, post)
+	return s.buildPost(ctx, post)
+}
+
+// GetPost 帖子详情
+func (s *Service) GetPost(ctx context.Context, postID int64) (*models.ResPost, error) {
+	post, err := s.store.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildPost(ctx, post)
+}
+
+// UpdatePost 编辑帖子，只有作者本人可以编辑
+func (s *Service) UpdatePost(ctx context.Context, userID, postID int64, p *models.ParamUpdatePost) (*models.ResPost, error) {
+	post, err := s.store.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.AuthorID != userID {
+		return nil, ErrorPostForbidden
+	}
+	if err = s.store.UpdatePost(ctx, postID, p.Title, p.Content); err != nil {
+		return nil, err
+	}
+	post.Title, post.Content = p.Title, p.Content
+	s.indexPost(ctx, post)
+	return s.GetPost(ctx, postID)
+}
+
+// DeletePost 删除帖子
+// 作者本人、社区的创建者 / 版主、拥有 post:delete 权限的人可以删除
+func (s *Service) DeletePost(ctx context.Context, mc *jwt.MyClaims, postID int64) error {
+	post, err := s.store.GetPostByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post.AuthorID != mc.UserID {
+		ok, err := s.canDeleteContent(ctx, mc, post.CommunityID, PermissionPostDelete)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrorPostForbidden
+		}
+	}
+	if err = s.store.DeletePost(ctx, postID); err != nil {
+		return err
+	}
+	if err = s.store.RemovePostIndex(ctx, postID, post.CommunityID); err != nil {
+		s.log.Error("dao.RemovePostIndex failed", zap.Int64("post_id", postID), zap.Error(err))
+	}
+	s.removePost(ctx, postID)
+	return nil
+}
+
+// ListPosts 全站帖子列表，按时间或热度排序
+func (s *Service) ListPosts(ctx context.Context, p *models.ParamPostList) (*models.ResPostList, error) {
+	return s.listPosts(ctx, 0, p)
+}
+
+// ListCommunityPosts 社区帖子列表，按时间或热度排序
+func (s *Service) ListCommunityPosts(ctx context.Context, communityID int64, p *models.ParamPostList) (*models.ResPostList, error) {
+	if _, err := s.store.GetCommunityByID(ctx, communityID); err != nil {
+		return nil, err
+	}
+	return s.listPosts(ctx, communityID, p)
+}
+
+// listPosts 先从 Redis 的排行里取出这一页的帖子 ID，再去 MySQL 查内容，按排行的顺序返回
+// 游标取自排行本身，这一页里有帖子刚被删除也不影响下一页从哪里开始
+func (s *Service) listPosts(ctx context.Context, communityID int64, p *models.ParamPostList) (*models.ResPostList, error) {
+	p.Normalize()
+	if p.Order == "" {
+		p.Order = models.OrderTime
+	}
+	cursor, err := s.cursor.Decode(p.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	ranks, total, err := s.store.ListPostIDs(ctx, communityID, p.Order, cursor, p.Size)
+	if err != nil {
+		return nil, err
+	}
+	ranks, page := common.NewCursorPage(s.cursor, ranks, p.Size, func(r dao.PostRank) common.Cursor {
+		return common.Cursor{Key: strconv.FormatFloat(r.Score, 'f', -1, 64), ID: r.PostID}
+	})
+
+	ids := make([]int64, 0, len(ranks))
+	for _, r := range ranks {
+		ids = append(ids, r.PostID)
+	}
+	rows, err := s.store.GetPostsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Post, len(rows))
+	for _, post := range rows {
+		byID[post.PostID] = post
+	}
+	// 排行里有、库里没有的 (刚被删除) 直接跳过
+	posts := make([]models.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := byID[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	list, err := s.buildPosts(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ResPostList{List: list, Total: total, CursorPage: page}, nil
+}
+
+// canDeleteContent 删除别人的内容：需要全局权限 permission，或者是内容所在社区的管理者
+func (s *Service) canDeleteContent(ctx context.Context, mc *jwt.MyClaims, communityID int64, permission string) (bool, error) {
+	ok, err := s.HasPermission(ctx, mc, permission)
+	if err != nil || ok {
+		return ok, err
+	}
+	return s.CanModerateCommunity(ctx, mc, communityID)
+}
+
+// buildPost 补上作者名和社区名
+func (s *Service) buildPost(ctx context.Context, post *models.Post) (*models.ResPost, error) {
+	list, err := s.buildPosts(ctx, []models.Post{*post})
+	if err != nil {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// buildPosts 批量补上作者名、社区名、票数和评论数，每种只查一次
+// 作者注销、社区删除之后帖子仍然返回，名字留空
+func (s *Service) buildPosts(ctx context.Context, posts []models.Post) ([]*models.ResPost, error) {
+	postIDs := make([]int64, 0, len(posts))
+	authorIDs := make([]int64, 0, len(posts))
+	communityIDs := make([]int64, 0, len(posts))
+	votingIDs := make([]int64, 0, len(posts))
+	for _, p := range posts {
+		postIDs = append(postIDs, p.PostID)
+		authorIDs = append(authorIDs, p.AuthorID)
+		communityIDs = append(communityIDs, p.CommunityID)
+		if !p.VoteClosed {
+			votingIDs = append(votingIDs, p.PostID)
+		}
+	}
+	users, err := s.repos.Users.ListByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	communities, err := s.store.GetCommunitiesByIDs(ctx, communityIDs)
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[int64]string, len(users))
+	for _, u := range users {
+		usernames[u.UserID] = u.Username
+	}
+	communityNames := make(map[int64]string, len(communities))
+	for _, c := range communities {
+		communityNames[c.CommunityID] = c.Name
+	}
+	// 投票期内的票数在 Redis 里，已归档的直接用 MySQL 里的
+	votes, err := s.store.GetPostVoteCounts(ctx, votingIDs)
+	if err != nil {
+		return nil, err
+	}
+	commentCounts, err := s.store.GetPostCommentCounts(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.ResPost, 0, len(posts))
+	for _, p := range posts {
+		vote, ok := votes[p.PostID]
+		if !ok {
+			vote = models.ResVote{UpVotes: p.UpVotes, DownVotes: p.DownVotes}
+		}
+		list = append(list, &models.ResPost{
+			ID:            p.PostID,
+			CommunityID:   p.CommunityID,
+			CommunityName: communityNames[p.CommunityID],
+			AuthorID:      p.AuthorID,
+			AuthorName:    usernames[p.AuthorID],
+			Title:         p.Title,
+			Content:       p.Content,
+			ResVote:       vote,
+			CommentCount:  commentCounts[p.PostID],
+			CreateTime:    p.CreateTime,
+			UpdateTime:    p.UpdateTime,
+		})
+	}
+	return list, nil
+}
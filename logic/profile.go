@@ -0,0 +1,126 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/encrypt"
+)
+
+// ErrorWrongPassword 修改密码 / 邮箱时输入的当前密码不对
+var ErrorWrongPassword = errors.New("密码错误")
+
+// GetProfile 查询个人资料
+func (s *Service) GetProfile(ctx context.Context, userID int64) (*models.ResProfile, error) {
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildProfile(user), nil
+}
+
+// UpdateProfile 修改个人资料，只修改传了的字段，返回修改后的资料
+func (s *Service) UpdateProfile(ctx context.Context, userID int64, p *models.ParamUpdateProfile) (*models.ResProfile, error) {
+	updates := make(map[string]interface{})
+	if p.Gender != nil {
+		updates["gender"] = models.ParseGender(*p.Gender)
+	}
+	if len(updates) > 0 {
+		if err := s.repos.Users.UpdateProfile(ctx, userID, updates); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetProfile(ctx, userID)
+}
+
+// ChangePassword 修改密码 (需要旧密码)
+// 改完之后踢掉所有设备，再给当前设备签发一对新 Token，当前设备不用重新登录
+func (s *Service) ChangePassword(ctx context.Context, userID int64, p *models.ParamChangePassword, client models.ClientInfo) (*models.ResToken, error) {
+	// 1. 校验旧密码
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkCurrentPassword(ctx, user, p.OldPassword, client.IP); err != nil {
+		return nil, err
+	}
+
+	// 2. 保存新密码
+	password, err := s.hasher().Hash(p.Password)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repos.Users.UpdatePassword(ctx, user.UserID, password); err != nil {
+		return nil, err
+	}
+
+	// 3. 其他设备上的登录全部作废，当前设备换一对新 Token
+	if err = s.LogoutAll(ctx, user.UserID); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, user.UserID, user.Username, client)
+}
+
+// ChangeEmail 修改邮箱 (需要密码)，新邮箱改为未验证并发送验证邮件
+func (s *Service) ChangeEmail(ctx context.Context, userID int64, p *models.ParamChangeEmail, ip string) error {
+	// 1. 校验密码
+	user, err := s.repos.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err = s.checkCurrentPassword(ctx, user, p.Password, ip); err != nil {
+		return err
+	}
+	if p.Email == user.Email {
+		return nil
+	}
+
+	// 2. 新邮箱不能被别人占用
+	if err = s.repos.Users.CheckEmailExist(ctx, p.Email); err != nil {
+		return err
+	}
+	if err = s.repos.Users.UpdateEmail(ctx, user.UserID, p.Email); err != nil {
+		return err
+	}
+
+	// 3. 发验证邮件，发给旧邮箱的链接因为邮箱对不上自动失效
+	user.Email = p.Email
+	user.EmailVerified = false
+	if err = s.sendVerifyEmail(ctx, user); err != nil {
+		s.log.Error("send verify email failed", zap.Int64("user_id", user.UserID), zap.Error(err))
+	}
+	return nil
+}
+
+// checkCurrentPassword 校验当前密码
+// 和登录共用失败计数，防止拿到 Access Token 的人在这里暴力猜密码
+func (s *Service) checkCurrentPassword(ctx context.Context, user *models.User, password, ip string) error {
+	if err := s.checkLoginLocked(ctx, user.Username, ip); err != nil {
+		return err
+	}
+	ok, _, err := encrypt.VerifyPassword(s.hasher(), password, user.Password)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		s.recordLoginFailure(ctx, user.Username, ip)
+		return ErrorWrongPassword
+	}
+	return nil
+}
+
+// buildProfile 数据库里的用户转成个人资料
+func (s *Service) buildProfile(user *models.User) *models.ResProfile {
+	return &models.ResProfile{
+		UserID:        user.UserID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Gender:        models.GenderName(user.Gender),
+		AvatarURL:     s.avatarURL(user.Avatar),
+		CreateTime:    user.CreateTime,
+	}
+}
@@ -0,0 +1,19 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/models"
+)
+
+func TestBuildProfileGender(t *testing.T) {
+	s := newTestService(t)
+	for _, name := range []string{"secret", "male", "female"} {
+		user := &models.User{UserID: 1, Gender: models.ParseGender(name)}
+		assert.Equal(t, name, s.buildProfile(user).Gender)
+	}
+	// 数据库里的脏数据按保密处理
+	assert.Equal(t, "secret", s.buildProfile(&models.User{Gender: 9}).Gender)
+}
@@ -0,0 +1,158 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+// RoleAdmin 超级管理员角色，拥有全部权限，不需要在 role_permission 里配置
+const RoleAdmin = "admin"
+
+// ErrorAssignAdminForbidden 只有管理员本人 (不能通过 API Key) 才能授予或收回 admin 角色
+var ErrorAssignAdminForbidden = errors.New("只有管理员才能授予或收回管理员角色")
+
+// rolePermissionsCacheTTL 角色权限缓存时间，直接改库调整角色权限后最多这么久生效
+const rolePermissionsCacheTTL = 10 * time.Minute
+
+// HasPermission 判断 Token 里的角色是否拥有某个权限
+// API Key 还要求权限在它的 Scopes 范围内，Key 泄露时损失可控
+func (s *Service) HasPermission(ctx context.Context, mc *jwt.MyClaims, permission string) (bool, error) {
+	if !HasScope(mc, permission) {
+		return false, nil
+	}
+	for _, role := range mc.Roles {
+		if role == RoleAdmin {
+			return true, nil
+		}
+		granted, err := s.rolePermissions(ctx, role)
+		if err != nil {
+			return false, err
+		}
+		for _, g := range granted {
+			if matchPermission(g, permission) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// HasScope 判断 API Key 的 Scopes 是否覆盖某个权限点，登录 Token 不受 Scopes 限制
+func HasScope(mc *jwt.MyClaims, permission string) bool {
+	if mc.TokenType != jwt.TokenTypeAPIKey {
+		return true
+	}
+	return slices.ContainsFunc(mc.Scopes, func(scope string) bool {
+		return matchPermission(scope, permission)
+	})
+}
+
+// AssignRole 给用户分配角色，用户下次刷新 Token 时生效
+// 拥有 role:assign 权限的人不一定是管理员，admin 角色只能由管理员授予，否则 role:assign 就等于全部权限
+func (s *Service) AssignRole(ctx context.Context, mc *jwt.MyClaims, userID int64, roleName string) error {
+	if roleName == RoleAdmin && !isAdmin(mc) {
+		return ErrorAssignAdminForbidden
+	}
+	return s.grantRole(ctx, userID, roleName)
+}
+
+// grantRole 分配角色，不检查操作人
+func (s *Service) grantRole(ctx context.Context, userID int64, roleName string) error {
+	if _, err := s.repos.Users.GetByID(ctx, userID); err != nil {
+		return err
+	}
+	role, err := s.repos.Roles.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	return s.repos.Roles.AddUserRole(ctx, userID, role.ID)
+}
+
+// RevokeRole 收回用户的角色
+// 收权限不能等 Token 自然过期，直接让用户的所有 Token 失效，重新登录后拿到新的角色列表
+// 和 AssignRole 一样，admin 角色只能由管理员收回
+func (s *Service) RevokeRole(ctx context.Context, mc *jwt.MyClaims, userID int64, roleName string) error {
+	if roleName == RoleAdmin && !isAdmin(mc) {
+		return ErrorAssignAdminForbidden
+	}
+	role, err := s.repos.Roles.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if err = s.repos.Roles.RemoveUserRole(ctx, userID, role.ID); err != nil {
+		return err
+	}
+	return s.LogoutAll(ctx, userID)
+}
+
+// CreateAdmin 创建管理员账号 (命令行 create-admin 用)
+// 用户名已经存在时不动密码和邮箱，直接授予 admin 角色；created 表示是否新建了用户
+func (s *Service) CreateAdmin(ctx context.Context, p *models.ParamSignUp) (userID int64, created bool, err error) {
+	if userID, created, err = s.EnsureUser(ctx, p, false); err != nil {
+		return 0, false, err
+	}
+	if err = s.grantRole(ctx, userID, RoleAdmin); err != nil {
+		return 0, false, err
+	}
+	return userID, created, nil
+}
+
+// isAdmin 操作人是管理员本人登录 (API Key 即使属于管理员也不算)
+func isAdmin(mc *jwt.MyClaims) bool {
+	return mc != nil && mc.TokenType != jwt.TokenTypeAPIKey && slices.Contains(mc.Roles, RoleAdmin)
+}
+
+// userRoles 签发 Token 时查询用户的角色，写进 Token
+// 配置 auth.admin_ids 里的用户 ID 自动拥有 admin 角色 (按 ID 而不是用户名，用户名谁都能注册)
+// 第一个管理员建议直接用 create-admin 命令创建
+func (s *Service) userRoles(ctx context.Context, userID int64) ([]string, error) {
+	roles, err := s.repos.Roles.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(s.cfg.GetStringSlice("auth.admin_ids"), strconv.FormatInt(userID, 10)) && !slices.Contains(roles, RoleAdmin) {
+		roles = append(roles, RoleAdmin)
+	}
+	return roles, nil
+}
+
+// rolePermissions 查询角色的权限点，优先读 Redis 缓存
+func (s *Service) rolePermissions(ctx context.Context, role string) ([]string, error) {
+	permissions, hit, err := s.store.GetCachedRolePermissions(ctx, role)
+	if err != nil {
+		// 缓存挂了直接查库，不影响鉴权
+		s.log.Warn("get cached role permissions failed", zap.String("role", role), zap.Error(err))
+	}
+	if hit {
+		return permissions, nil
+	}
+	permissions, err = s.repos.Roles.GetRolePermissions(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.store.CacheRolePermissions(ctx, role, permissions, rolePermissionsCacheTTL); err != nil {
+		s.log.Warn("cache role permissions failed", zap.String("role", role), zap.Error(err))
+	}
+	return permissions, nil
+}
+
+// matchPermission 判断已授予的权限 granted 是否覆盖 want
+// 支持通配符：* 匹配一切，post:* 匹配 post:delete、post:update 等
+func matchPermission(granted, want string) bool {
+	if granted == "*" || granted == want {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(granted, "*"); ok && strings.HasSuffix(prefix, ":") {
+		return strings.HasPrefix(want, prefix)
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+func TestMatchPermission(t *testing.T) {
+	cases := []struct {
+		granted, want string
+		ok            bool
+	}{
+		{"post:delete", "post:delete", true},
+		{"post:delete", "post:update", false},
+		{"post:*", "post:delete", true},
+		{"post:*", "comment:delete", false},
+		{"post:*", "postx:delete", false},
+		{"*", "comment:delete", true},
+		{"post*", "post:delete", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.ok, matchPermission(c.granted, c.want), "%s vs %s", c.granted, c.want)
+	}
+}
+
+// TestHasPermissionAdmin admin 角色不用查权限表
+func TestHasPermissionAdmin(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	ok, err := s.HasPermission(ctx, &jwt.MyClaims{Roles: []string{RoleAdmin}}, "post:delete")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = s.HasPermission(ctx, &jwt.MyClaims{}, "post:delete")
+	assert.NoError(t, err)
+	assert.False(t, ok, "没有任何角色")
+}
+
+// TestHasPermissionAPIKey API Key 只能用 Scopes 范围内的权限，即使用户是 admin
+func TestHasPermissionAPIKey(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	mc := &jwt.MyClaims{TokenType: jwt.TokenTypeAPIKey, Roles: []string{RoleAdmin}, Scopes: []string{"post:*"}}
+
+	ok, err := s.HasPermission(ctx, mc, "post:delete")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = s.HasPermission(ctx, mc, "user:unlock")
+	assert.NoError(t, err)
+	assert.False(t, ok, "超出 Scopes")
+
+	mc.Scopes = nil
+	ok, _ = s.HasPermission(ctx, mc, "post:delete")
+	assert.False(t, ok, "没有 Scopes 的 Key 不带任何权限")
+}
+
+// TestAssignRole 用内存实现，不需要数据库
+func TestAssignRole(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	assert.NoError(t, s.repos.Users.Insert(ctx, &models.User{UserID: 7, Username: "bob", Email: "bob@example.com"}))
+
+	op := &jwt.MyClaims{Roles: []string{"moderator"}} // 有 role:assign 权限但不是管理员
+	assert.ErrorIs(t, s.AssignRole(ctx, op, 8, "moderator"), dao.ErrorUserNotFound)
+	assert.ErrorIs(t, s.AssignRole(ctx, op, 7, "nobody"), dao.ErrorRoleNotFound)
+
+	assert.NoError(t, s.AssignRole(ctx, op, 7, "moderator"))
+	assert.NoError(t, s.AssignRole(ctx, op, 7, "moderator"), "重复分配直接忽略")
+	roles, err := s.userRoles(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"moderator"}, roles)
+}
+
+// TestAssignAdminRole admin 角色只能由管理员本人授予 / 收回
+func TestAssignAdminRole(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	assert.NoError(t, s.repos.Users.Insert(ctx, &models.User{UserID: 7, Username: "bob", Email: "bob@example.com"}))
+
+	moderator := &jwt.MyClaims{Roles: []string{"moderator"}}
+	assert.ErrorIs(t, s.AssignRole(ctx, moderator, 7, RoleAdmin), ErrorAssignAdminForbidden)
+	adminKey := &jwt.MyClaims{TokenType: jwt.TokenTypeAPIKey, Roles: []string{RoleAdmin}, Scopes: []string{"*"}}
+	assert.ErrorIs(t, s.AssignRole(ctx, adminKey, 7, RoleAdmin), ErrorAssignAdminForbidden, "API Key 不行")
+
+	admin := &jwt.MyClaims{Roles: []string{RoleAdmin}}
+	assert.NoError(t, s.AssignRole(ctx, admin, 7, RoleAdmin))
+	assert.ErrorIs(t, s.RevokeRole(ctx, moderator, 7, RoleAdmin), ErrorAssignAdminForbidden)
+	roles, _ := s.userRoles(ctx, 7)
+	assert.Equal(t, []string{RoleAdmin}, roles)
+}
+
+// TestBootstrapAdminByID auth.admin_ids 按用户 ID 授予 admin，和用户名无关
+func TestBootstrapAdminByID(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	s.cfg.Set("auth.admin_ids", []int64{7})
+
+	roles, err := s.userRoles(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{RoleAdmin}, roles)
+	roles, _ = s.userRoles(ctx, 8)
+	assert.Empty(t, roles)
+}
@@ -0,0 +1,34 @@
+package logic
+
+import (
+	"gorm.io/gorm"
+
+	"gin-api-scaffold-v1/dao"
+)
+
+// Repositories logic 层读写用户、角色、两步验证数据用的接口，默认是 dao 里的 GORM 实现
+// 单元测试可以换成 dao 里的内存实现 (NewMemoryRepositories)，用户相关的功能不需要连数据库；
+// 社区、帖子、评论等其他数据还是通过 dao.Store 读写 MySQL，没有对应的内存实现
+type Repositories struct {
+	Users dao.UserRepository
+	Roles dao.RoleRepository
+	MFA   dao.MFARepository
+}
+
+// NewGormRepositories 全部使用 db 这个连接上的 GORM 实现
+func NewGormRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Users: dao.GormUserRepository{DB: db},
+		Roles: dao.GormRoleRepository{DB: db},
+		MFA:   dao.GormMFARepository{DB: db},
+	}
+}
+
+// NewMemoryRepositories 全部使用内存实现，数据只在当前进程里有效
+func NewMemoryRepositories() Repositories {
+	return Repositories{
+		Users: dao.NewMemoryUserRepository(),
+		Roles: dao.NewMemoryRoleRepository(),
+		MFA:   dao.NewMemoryMFARepository(),
+	}
+}
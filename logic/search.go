@@ -0,0 +1,137 @@
+package logic
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/search"
+)
+
+// searchSnippetSize 帖子内容的高亮片段最多多少个字
+const searchSnippetSize = 120
+
+// Search 搜索帖子或用户，按相关度排序
+func (s *Service) Search(ctx context.Context, p *models.ParamSearch) (*models.ResSearch, error) {
+	p.Normalize()
+	if p.Type == "" {
+		p.Type = search.TypePost
+	}
+	q := search.Query{
+		Type:        p.Type,
+		Text:        p.Q,
+		CommunityID: p.CommunityID,
+		Start:       p.StartDate,
+		Offset:      p.Offset(),
+		Limit:       p.Size,
+	}
+	// 结束日期当天也要包含在内
+	if !p.EndDate.IsZero() {
+		q.End = p.EndDate.AddDate(0, 0, 1)
+	}
+	result, err := s.search.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.ResSearch{Type: p.Type, Total: result.Total, Page: p.Page, Size: p.Size}
+	if p.Type == search.TypeUser {
+		res.Users, err = s.searchUsers(ctx, result.IDs, p.Q)
+	} else {
+		res.Posts, err = s.searchPosts(ctx, result.IDs, p.Q)
+	}
+	return res, err
+}
+
+// searchPosts 按搜索结果的顺序查出帖子并高亮，搜索结果里有、库里已经没有的跳过
+func (s *Service) searchPosts(ctx context.Context, ids []int64, query string) ([]*models.ResSearchPost, error) {
+	rows, err := s.store.GetPostsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Post, len(rows))
+	for _, post := range rows {
+		byID[post.PostID] = post
+	}
+	posts := make([]models.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := byID[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	list, err := s.buildPosts(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*models.ResSearchPost, 0, len(list))
+	for _, post := range list {
+		res = append(res, &models.ResSearchPost{
+			ResPost:          post,
+			HighlightTitle:   search.Highlight(post.Title, query, 0),
+			HighlightContent: search.Highlight(post.Content, query, searchSnippetSize),
+		})
+	}
+	return res, nil
+}
+
+// searchUsers 按搜索结果的顺序查出用户并高亮
+func (s *Service) searchUsers(ctx context.Context, ids []int64, query string) ([]*models.ResSearchUser, error) {
+	rows, err := s.repos.Users.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.User, len(rows))
+	for _, u := range rows {
+		byID[u.UserID] = u
+	}
+	res := make([]*models.ResSearchUser, 0, len(ids))
+	for _, id := range ids {
+		u, ok := byID[id]
+		if !ok {
+			continue
+		}
+		res = append(res, &models.ResSearchUser{
+			UserID:            u.UserID,
+			Username:          u.Username,
+			HighlightUsername: search.Highlight(u.Username, query, 0),
+			AvatarURL:         s.avatarURL(u.Avatar),
+		})
+	}
+	return res, nil
+}
+
+// indexPost / removePost / indexUser 内容变化后更新搜索索引
+// 使用 MySQL FULLTEXT 时什么都不做；索引失败不影响主流程，只打日志
+// 数据已经写进数据库了，请求超时或者客户端断开也要把索引更新完，所以不跟着 ctx 取消
+
+func (s *Service) indexPost(ctx context.Context, post *models.Post) {
+	err := s.search.Index(context.WithoutCancel(ctx), search.Document{
+		Type:        search.TypePost,
+		ID:          post.PostID,
+		Text:        post.Title + "\n" + post.Content,
+		CommunityID: post.CommunityID,
+		CreateTime:  post.CreateTime,
+	})
+	if err != nil {
+		s.log.Error("index post failed", zap.Int64("post_id", post.PostID), zap.Error(err))
+	}
+}
+
+func (s *Service) removePost(ctx context.Context, postID int64) {
+	if err := s.search.Remove(context.WithoutCancel(ctx), search.TypePost, postID); err != nil {
+		s.log.Error("remove post from search index failed", zap.Int64("post_id", postID), zap.Error(err))
+	}
+}
+
+func (s *Service) indexUser(ctx context.Context, user *models.User) {
+	err := s.search.Index(context.WithoutCancel(ctx), search.Document{
+		Type:       search.TypeUser,
+		ID:         user.UserID,
+		Text:       user.Username,
+		CreateTime: user.CreateTime,
+	})
+	if err != nil {
+		s.log.Error("index user failed", zap.Int64("user_id", user.UserID), zap.Error(err))
+	}
+}
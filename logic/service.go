@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/pkg/encrypt"
+	"gin-api-scaffold-v1/pkg/jwt"
+	"gin-api-scaffold-v1/pkg/mailer"
+	"gin-api-scaffold-v1/pkg/oidc"
+	"gin-api-scaffold-v1/pkg/search"
+	"gin-api-scaffold-v1/pkg/snowflake"
+	"gin-api-scaffold-v1/pkg/storage"
+)
+
+// Deps Service 依赖的组件，由 app.New 按配置创建好传进来
+type Deps struct {
+	Config  *viper.Viper
+	Logger  *zap.Logger
+	Store   *dao.Store
+	Repos   Repositories
+	IDs     *snowflake.Node
+	JWT     *jwt.Manager
+	Mailer  mailer.Mailer
+	Storage storage.Storage
+	Search  search.Engine
+	OIDC    *oidc.Registry
+	Cursor  *common.CursorCodec
+}
+
+// Service logic 层的业务逻辑，所有依赖都从 Deps 注入，不读包级别的全局变量
+// 一个 app.App 对应一个 Service，同一个进程里的多个 App 互不影响
+type Service struct {
+	cfg     *viper.Viper
+	log     *zap.Logger
+	store   *dao.Store
+	repos   Repositories
+	ids     *snowflake.Node
+	jwt     *jwt.Manager
+	mailer  mailer.Mailer
+	storage storage.Storage
+	search  search.Engine
+	oidc    *oidc.Registry
+	cursor  *common.CursorCodec
+}
+
+func NewService(d Deps) *Service {
+	return &Service{
+		cfg:     d.Config,
+		log:     d.Logger,
+		store:   d.Store,
+		repos:   d.Repos,
+		ids:     d.IDs,
+		jwt:     d.JWT,
+		mailer:  d.Mailer,
+		storage: d.Storage,
+		search:  d.Search,
+		oidc:    d.OIDC,
+		cursor:  d.Cursor,
+	}
+}
+
+// hasher 生成新密码哈希用的算法，每次都读配置 auth.password_hasher，改了配置立即生效
+func (s *Service) hasher() encrypt.Hasher {
+	return encrypt.DefaultHasher(s.cfg.GetString("auth.password_hasher"))
+}
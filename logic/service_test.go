@@ -0,0 +1,71 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+	"gin-api-scaffold-v1/pkg/mailer"
+	"gin-api-scaffold-v1/pkg/oidc"
+	"gin-api-scaffold-v1/pkg/search"
+	"gin-api-scaffold-v1/pkg/snowflake"
+	"gin-api-scaffold-v1/pkg/storage"
+)
+
+// newTestService 创建一个不依赖 MySQL 的 Service
+// 用户、角色、两步验证数据用内存实现，Redis 用 miniredis，文件存到临时目录，每次调用都是全新的一套数据
+func newTestService(t *testing.T) *Service {
+	cfg := viper.New()
+	cfg.Set("auth.jwt_secret", "test_secret")
+	cfg.Set("auth.access_expire", 15)
+	cfg.Set("auth.refresh_expire", 24)
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	ids, err := snowflake.NewNode("2026-01-01", 1)
+	assert.NoError(t, err)
+	jm, err := jwt.NewManager(cfg)
+	assert.NoError(t, err)
+
+	return NewService(Deps{
+		Config:  cfg,
+		Logger:  zap.NewNop(),
+		Store:   dao.NewStore(nil, rdb),
+		Repos:   NewMemoryRepositories(),
+		IDs:     ids,
+		JWT:     jm,
+		Mailer:  &mailer.FileMailer{},
+		Storage: &storage.LocalStorage{Dir: t.TempDir(), Secret: []byte("test_secret")},
+		Search:  search.NewMemoryEngine(),
+		OIDC:    oidc.NewRegistry(cfg),
+		Cursor:  common.NewCursorCodec("", "test_secret"),
+	})
+}
+
+// TestServiceIsolation 两个 Service 各自持有自己的依赖，数据互不可见
+func TestServiceIsolation(t *testing.T) {
+	ctx := context.Background()
+	s1, s2 := newTestService(t), newTestService(t)
+	p := &models.ParamSignUp{Username: "alice", Password: "12345678", RePassword: "12345678", Email: "alice@example.com"}
+
+	_, created, err := s1.EnsureUser(ctx, p, true)
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	_, err = s2.repos.Users.GetByUsername(ctx, "alice")
+	assert.ErrorIs(t, err, dao.ErrorUserNotFound)
+	_, created, err = s2.EnsureUser(ctx, p, true)
+	assert.NoError(t, err)
+	assert.True(t, created, "另一个实例里还没有这个用户")
+}
@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+// ListSessions 列出当前用户登录着的设备，标记出发起请求的这一台
+func (s *Service) ListSessions(ctx context.Context, mc *jwt.MyClaims) ([]*models.Session, error) {
+	sessions, err := s.store.ListSessions(ctx, mc.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if sessions == nil {
+		sessions = []*models.Session{}
+	}
+	for _, sess := range sessions {
+		sess.Current = sess.ID == mc.FamilyID
+	}
+	return sessions, nil
+}
+
+// RevokeSession 踢掉某台设备：吊销它的 Refresh Token，删除会话
+// 它手上的 Access Token 在下一次请求时就会因为会话不存在而失效
+func (s *Service) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	sess, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// 只能踢自己的设备，别人的会话一律当作不存在
+	if sess.UserID != userID {
+		return dao.ErrorSessionNotFound
+	}
+	if err = s.store.RevokeRefreshFamily(ctx, sessionID); err != nil {
+		return err
+	}
+	return s.store.DeleteSession(ctx, userID, sessionID)
+}
+
+// createSession 登录成功后记录会话
+func (s *Service) createSession(ctx context.Context, sessionID string, userID int64, client models.ClientInfo) error {
+	now := time.Now()
+	return s.store.SaveSession(ctx, &models.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		Device:     parseDevice(client.UserAgent),
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}, s.jwt.RefreshExpire())
+}
+
+// checkSession 会话被踢掉之后，这次登录签发的 Access Token 立即失效；顺便更新最后活跃时间
+func (s *Service) checkSession(ctx context.Context, mc *jwt.MyClaims) error {
+	ok, err := s.store.TouchSession(ctx, mc.FamilyID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrorTokenRevoked
+	}
+	return nil
+}
+
+// refreshSession 刷新 Token 时更新会话
+// 上线会话功能之前登录的设备没有会话记录，在这里补上，不用强制重新登录
+func (s *Service) refreshSession(ctx context.Context, sessionID string, userID int64, client models.ClientInfo) error {
+	_, err := s.store.GetSession(ctx, sessionID)
+	if errors.Is(err, dao.ErrorSessionNotFound) {
+		return s.createSession(ctx, sessionID, userID, client)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.store.TouchSession(ctx, sessionID, time.Now())
+	return err
+}
+
+// parseDevice 从 User-Agent 里粗略解析出 "浏览器 on 系统"，给用户辨认设备用，不追求精确
+func parseDevice(ua string) string {
+	if ua == "" {
+		return "Unknown"
+	}
+	browser := firstMatch(ua, []string{"Edg/", "Edge", "OPR/", "Opera", "Firefox", "Chrome", "Safari", "curl", "okhttp", "python-requests", "Go-http-client"},
+		map[string]string{"Edg/": "Edge", "OPR/": "Opera"})
+	system := firstMatch(ua, []string{"Windows", "iPhone", "iPad", "Android", "Mac OS X", "Linux"},
+		map[string]string{"Mac OS X": "macOS"})
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Unknown"
+	}
+}
+
+// firstMatch 按顺序找第一个出现在 s 里的关键字 (顺序很重要：Edge 和 Chrome 的 UA 里都有 "Chrome")
+func firstMatch(s string, keywords []string, rename map[string]string) string {
+	for _, k := range keywords {
+		if strings.Contains(s, k) {
+			if name, ok := rename[k]; ok {
+				return name
+			}
+			return k
+		}
+	}
+	return ""
+}
@@ -1,44 +1,172 @@
 package logic
 
 import (
+	"context"
 	"errors"
+
+	"go.uber.org/zap"
+
 	"gin-api-scaffold-v1/dao"
 	"gin-api-scaffold-v1/models"
 	"gin-api-scaffold-v1/pkg/encrypt"
-	"gin-api-scaffold-v1/pkg/jwt" // 👈 1. 引入这一行
-	"gin-api-scaffold-v1/pkg/snowflake"
 )
 
-// SignUp 处理注册业务 (保持不变)
-func SignUp(p *models.ParamSignUp) (err error) {
-	if err = dao.CheckUserExist(p.Username); err != nil {
+// SignUp 处理注册业务
+func (s *Service) SignUp(ctx context.Context, p *models.ParamSignUp) (err error) {
+	if err = s.repos.Users.CheckUserExist(ctx, p.Username); err != nil {
+		return err
+	}
+	if err = s.repos.Users.CheckEmailExist(ctx, p.Email); err != nil {
+		return err
+	}
+	password, err := s.hasher().Hash(p.Password)
+	if err != nil {
 		return err
 	}
-	userID := snowflake.GenID()
+	userID := s.ids.GenID()
 	user := &models.User{
 		UserID:   userID,
 		Username: p.Username,
-		Password: encrypt.EncryptPassword(p.Password),
+		Password: password,
+		Email:    p.Email,
+		Gender:   models.ParseGender(p.Gender),
+	}
+	if err = s.repos.Users.Insert(ctx, user); err != nil {
+		return err
+	}
+	s.indexUser(ctx, user)
+
+	// 注册已经成功，验证邮件发不出去不影响注册，用户登录后可以重新发送
+	if err = s.sendVerifyEmail(ctx, user); err != nil {
+		s.log.Error("send verify email failed", zap.Int64("user_id", userID), zap.Error(err))
+	}
+	return nil
+}
+
+// EnsureUser 用户名不存在时注册一个新用户，已经存在时什么都不改 (命令行 seed / create-admin 用)
+// verified 为 true 时直接把邮箱标记为已验证；created 表示是否新建了用户
+func (s *Service) EnsureUser(ctx context.Context, p *models.ParamSignUp, verified bool) (userID int64, created bool, err error) {
+	err = s.SignUp(ctx, p)
+	if err != nil && !errors.Is(err, dao.ErrorUserExist) {
+		return 0, false, err
+	}
+	created = err == nil
+	user, err := s.repos.Users.GetByUsername(ctx, p.Username)
+	if err != nil {
+		return 0, false, err
+	}
+	if verified && !user.EmailVerified {
+		if err = s.repos.Users.SetEmailVerified(ctx, user.UserID); err != nil {
+			return 0, false, err
+		}
 	}
-	return dao.InsertUser(user)
+	return user.UserID, created, nil
 }
 
 // Login 处理登录业务
-func Login(p *models.ParamLogin) (token string, err error) {
+// client.IP 用于按 IP 统计失败次数，防止撞库；client 同时会记录到会话里
+func (s *Service) Login(ctx context.Context, p *models.ParamLogin, client models.ClientInfo) (token *models.ResToken, err error) {
+	// 0. 用户名或 IP 失败次数太多，锁定期内直接拒绝，连密码都不校验
+	if err = s.checkLoginLocked(ctx, p.Username, client.IP); err != nil {
+		return nil, err
+	}
+
 	// 1. 去数据库查用户是否存在
-	user, err := dao.GetUserByUsername(p.Username)
+	user, err := s.repos.Users.GetByUsername(ctx, p.Username)
 	if err != nil {
-		return "", errors.New("用户不存在")
+		s.recordLoginFailure(ctx, p.Username, client.IP)
+		return nil, errors.New("用户不存在")
 	}
 
 	// 2. 校验密码
-	password := encrypt.EncryptPassword(p.Password)
-	if password != user.Password {
-		return "", errors.New("密码错误")
+	ok, needsRehash, err := encrypt.VerifyPassword(s.hasher(), p.Password, user.Password)
+	if err != nil {
+		return nil, err
 	}
+	if !ok {
+		s.recordLoginFailure(ctx, p.Username, client.IP)
+		return nil, errors.New("密码错误")
+	}
+
+	// 登录成功，清掉这个用户名的失败计数 (IP 的计数不清，避免攻击者用自己的账号给 IP "洗白")
+	if err = s.store.ClearLoginFailures(ctx, dao.LoginSubjectUser, p.Username); err != nil {
+		s.log.Warn("clear login failures failed", zap.String("username", p.Username), zap.Error(err))
+	}
+
+	// 3. 老用户 (MD5) 或者哈希参数过时：趁着拿到明文，悄悄升级成新算法
+	// 升级失败不影响本次登录，下次登录会再试
+	if needsRehash {
+		s.rehashPassword(ctx, user.UserID, p.Password)
+	}
+
+	// 4. 签发 Token (开启了两步验证的用户先拿临时 Token)
+	return s.finishLogin(ctx, user, client)
+}
+
+// finishLogin 身份已经确认 (密码或第三方登录)，签发 Token
+func (s *Service) finishLogin(ctx context.Context, user *models.User, client models.ClientInfo) (*models.ResToken, error) {
+	// 开启了两步验证：先发一张临时 Token，输入验证码后再换正式 Token
+	required, err := s.mfaRequired(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		mfaToken, err := s.jwt.GenMFAToken(user.UserID, user.Username)
+		if err != nil {
+			return nil, err
+		}
+		return &models.ResToken{Username: user.Username, MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	// ⚡️⚡️ 签发短期 Access Token + 可轮换的 Refresh Token ⚡️⚡️
+	// Access Token 过期后，前端拿 Refresh Token 调 /auth/refresh 换新的，不需要重新输密码
+	return s.issueTokens(ctx, user.UserID, user.Username, client)
+}
+
+// legacyPasswordBatch WrapLegacyPasswords 每批处理多少个用户
+const legacyPasswordBatch = 500
+
+// WrapLegacyPasswords 把库里所有老的 MD5 密码包一层 argon2id (encrypt.WrapLegacyMD5)，返回处理了多少个
+// 老格式能直接还原明文，不能等用户登录时才升级：一直不登录的用户会永远留在库里
+// 不需要明文，可以重复执行；期间用户改了密码或者登录升级过的不会被覆盖
+func WrapLegacyPasswords(ctx context.Context, users dao.UserRepository) (n int, err error) {
+	var after int64
+	for {
+		batch, err := users.ListLegacyPasswords(ctx, after, legacyPasswordBatch)
+		if err != nil {
+			return n, err
+		}
+		for _, u := range batch {
+			after = u.UserID
+			// 不是 $ 开头的不一定是老格式 (比如第三方登录的用户没有密码)
+			if !encrypt.IsLegacyMD5(u.Password) {
+				continue
+			}
+			wrapped, err := encrypt.WrapLegacyMD5(u.Password)
+			if err != nil {
+				return n, err
+			}
+			swapped, err := users.SwapPassword(ctx, u.UserID, u.Password, wrapped)
+			if err != nil {
+				return n, err
+			}
+			if swapped {
+				n++
+			}
+		}
+		if len(batch) < legacyPasswordBatch {
+			return n, nil
+		}
+	}
+}
 
-	// 3. ⚡️⚡️ 生成标准的 JWT Token ⚡️⚡️
-	// 使用我们在 pkg/jwt 里封装好的 GenToken 函数
-	// 只要这一步不报错，前端拿到的就是一张合法的“通行证”
-	return jwt.GenToken(user.UserID, user.Username)
+// rehashPassword 用当前默认算法重新生成密码哈希并保存
+func (s *Service) rehashPassword(ctx context.Context, userID int64, password string) {
+	encoded, err := s.hasher().Hash(password)
+	if err == nil {
+		err = s.repos.Users.UpdatePassword(ctx, userID, encoded)
+	}
+	if err != nil {
+		s.log.Warn("rehash password failed", zap.Int64("user_id", userID), zap.Error(err))
+	}
 }
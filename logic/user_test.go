@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/models"
+	"gin-api-scaffold-v1/pkg/encrypt"
+)
+
+// TestWrapLegacyPasswords 一直不登录的老用户也不能在库里留下能还原明文的 MD5 值
+func TestWrapLegacyPasswords(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	modern, _ := s.hasher().Hash("123456")
+	for _, u := range []models.User{
+		{UserID: 1, Username: "alice", Email: "alice@example.com", Password: encrypt.EncryptPassword("123456")},
+		{UserID: 2, Username: "bob", Email: "bob@example.com", Password: modern},
+		{UserID: 3, Username: "carol", Email: "carol@example.com"}, // 第三方登录，没有密码
+	} {
+		assert.NoError(t, s.repos.Users.Insert(ctx, &u))
+	}
+
+	n, err := WrapLegacyPasswords(ctx, s.repos.Users)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, _ = WrapLegacyPasswords(ctx, s.repos.Users)
+	assert.Zero(t, n, "可以重复执行")
+
+	alice, _ := s.repos.Users.GetByID(ctx, 1)
+	assert.True(t, strings.HasPrefix(alice.Password, "$md5$argon2id$"))
+	bob, _ := s.repos.Users.GetByID(ctx, 2)
+	assert.Equal(t, modern, bob.Password)
+
+	// 原来的密码照样能登录，登录后升级成直接用明文生成的哈希
+	_, err = s.Login(ctx, &models.ParamLogin{Username: "alice", Password: "123456"}, models.ClientInfo{})
+	assert.NoError(t, err)
+	alice, _ = s.repos.Users.GetByID(ctx, 1)
+	assert.True(t, strings.HasPrefix(alice.Password, "$argon2id$"))
+}
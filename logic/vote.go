@@ -0,0 +1,121 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"gin-api-scaffold-v1/dao"
+	"gin-api-scaffold-v1/models"
+)
+
+// ErrorVoteTimeExpired 超过投票期，票数已经冻结
+var ErrorVoteTimeExpired = errors.New("投票时间已过")
+
+// archiveBatchSize 每一轮最多归档多少个帖子，没归档完的下一轮继续
+const archiveBatchSize = 100
+
+// VotePost 给帖子投票，direction: 1 赞成 / -1 反对 / 0 取消
+func (s *Service) VotePost(ctx context.Context, userID, postID int64, direction int8) (*models.ResVote, error) {
+	post, err := s.store.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.VoteClosed || time.Since(post.CreateTime) > s.voteWindow() {
+		return nil, ErrorVoteTimeExpired
+	}
+	res, err := s.store.VotePost(ctx, post.PostID, post.CommunityID, userID, direction, post.CreateTime)
+	if errors.Is(err, dao.ErrorVoteClosed) {
+		return nil, ErrorVoteTimeExpired
+	}
+	return res, err
+}
+
+// ArchiveVotes 把投票期已经结束的帖子的投票记录从 Redis 搬到 MySQL，返回归档了多少个帖子
+// 多个实例同时跑也没关系：CloseVoting 只有一个实例能成功
+func (s *Service) ArchiveVotes(ctx context.Context, now time.Time) (int, error) {
+	ids, err := s.store.ListExpiredVotingPosts(ctx, now.Add(-s.voteWindow()), archiveBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	archived := 0
+	for _, postID := range ids {
+		// 1. 先关闭投票，之后投票脚本会直接拒绝，票数不会再变
+		ok, err := s.store.CloseVoting(ctx, postID)
+		if err != nil {
+			return archived, err
+		}
+		if !ok {
+			continue
+		}
+
+		// 2. 写 MySQL，失败就重新放回去，下一轮重试
+		// 投票已经关闭了，关机时 ctx 被取消也要把这个帖子处理完，否则票数会一直留在 Redis 里
+		postCtx := context.WithoutCancel(ctx)
+		if err = s.archivePost(postCtx, postID); err != nil {
+			s.log.Error("archive post votes failed", zap.Int64("post_id", postID), zap.Error(err))
+			if err = s.store.ReopenVoting(postCtx, postID, now.Add(-s.voteWindow())); err != nil {
+				s.log.Error("reopen voting failed", zap.Int64("post_id", postID), zap.Error(err))
+			}
+			continue
+		}
+		archived++
+	}
+	return archived, nil
+}
+
+// RunVoteArchiver 后台定时归档投票，ctx 取消后退出
+func (s *Service) RunVoteArchiver(ctx context.Context) {
+	ticker := time.NewTicker(s.voteArchiveInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := s.ArchiveVotes(ctx, now)
+			if err != nil {
+				s.log.Error("archive votes failed", zap.Error(err))
+			}
+			if n > 0 {
+				s.log.Info("archived post votes", zap.Int("posts", n))
+			}
+		}
+	}
+}
+
+// archivePost 归档一个帖子：写 MySQL，再删掉 Redis 里的投票记录
+// 帖子已经被删除时直接清理 Redis
+func (s *Service) archivePost(ctx context.Context, postID int64) error {
+	votes, err := s.store.GetPostVotes(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if _, err = s.store.GetPostByID(ctx, postID); err != nil && !errors.Is(err, dao.ErrorPostNotFound) {
+		return err
+	}
+	if err == nil {
+		if err = s.store.ArchivePostVotes(ctx, postID, votes); err != nil {
+			return err
+		}
+	}
+	return s.store.DeletePostVotes(ctx, postID)
+}
+
+// voteWindow 投票期，vote.window 单位天，默认 7 天
+func (s *Service) voteWindow() time.Duration {
+	if n := s.cfg.GetInt("vote.window"); n > 0 {
+		return time.Duration(n) * 24 * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+// voteArchiveInterval 多久检查一次需要归档的帖子，vote.archive_interval 单位分钟，默认 10 分钟
+func (s *Service) voteArchiveInterval() time.Duration {
+	if n := s.cfg.GetInt("vote.archive_interval"); n > 0 {
+		return time.Duration(n) * time.Minute
+	}
+	return 10 * time.Minute
+}
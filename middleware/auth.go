@@ -1,39 +1,66 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
 
 	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/logic"
 	"gin-api-scaffold-v1/pkg/jwt"
+	"gin-api-scaffold-v1/pkg/session"
 
 	"github.com/gin-gonic/gin"
 )
 
 // JWTAuthMiddleware 基于 JWT 的认证中间件
-func JWTAuthMiddleware() gin.HandlerFunc {
+// 同时支持 API Key：Authorization: ApiKey <key> 或者 X-API-Key: <key>
+func JWTAuthMiddleware(svc *logic.Service, tokens *jwt.Manager, cookies *session.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// 0. 脚本 / 其他服务用 API Key 访问
+		if key := apiKeyFromRequest(c); key != "" {
+			mc, err := svc.AuthenticateAPIKey(c.Request.Context(), key)
+			if err != nil {
+				if errors.Is(err, logic.ErrorInvalidAPIKey) {
+					common.Error(c, common.CodeInvalidToken, err)
+				} else {
+					common.Error(c, common.CodeServerBusy, err)
+				}
+				c.Abort()
+				return
+			}
+			setCurrentUser(c, mc)
+			c.Next()
+			return
+		}
+
 		// 1. 获取 Authorization Header
 		// 行业规范：前端要把 Token 放在 Header 的 "Authorization" 字段里
+		// 开启了 Cookie 登录模式时，没有 Authorization 再去 Cookie 里找 (写请求由 CSRFMiddleware 把关)
 		authHeader := c.Request.Header.Get("Authorization")
+		tokenString := ""
 		if authHeader == "" {
-			// 如果没带 Token，直接拒绝，返回 "需要登录"
-			common.Error(c, common.CodeNeedLogin, nil)
-			c.Abort() // 🚫 阻止执行后续函数
-			return
-		}
-
-		// 2. 解析 Header 格式
-		// 行业规范：Authorization: Bearer <token>
-		// 所以我们要按空格切割，取第2部分
-		parts := strings.SplitN(authHeader, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			common.Error(c, common.CodeInvalidToken, nil)
-			c.Abort()
-			return
+			tokenString = cookies.AccessToken(c)
+			if tokenString == "" {
+				// 如果没带 Token，直接拒绝，返回 "需要登录"
+				common.Error(c, common.CodeNeedLogin, nil)
+				c.Abort() // 🚫 阻止执行后续函数
+				return
+			}
+		} else {
+			// 2. 解析 Header 格式
+			// 行业规范：Authorization: Bearer <token>
+			// 所以我们要按空格切割，取第2部分
+			parts := strings.SplitN(authHeader, " ", 2)
+			if !(len(parts) == 2 && parts[0] == "Bearer") {
+				common.Error(c, common.CodeInvalidToken, nil)
+				c.Abort()
+				return
+			}
+			tokenString = parts[1]
 		}
 
 		// 3. 解析 Token
-		mc, err := jwt.ParseToken(parts[1])
+		mc, err := tokens.ParseToken(tokenString)
 		if err != nil {
 			// Token 过期或无效
 			common.Error(c, common.CodeInvalidToken, err)
@@ -41,11 +68,56 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 4. ✅ 验证通过！将当前请求的 UserID 信息保存到上下文 c 中
-		// 这样后续的 Controller 就能知道是谁在访问了
-		c.Set("userID", mc.UserID)
-		c.Set("username", mc.Username)
+		// 4. 检查 Token 是否已经注销 (黑名单 / 退出所有设备)
+		// 签名没问题不代表还能用，用户主动退出后 Token 要立即失效
+		if err := svc.CheckTokenRevoked(c.Request.Context(), mc); err != nil {
+			if errors.Is(err, logic.ErrorTokenRevoked) {
+				common.Error(c, common.CodeInvalidToken, err)
+			} else {
+				common.Error(c, common.CodeServerBusy, err)
+			}
+			c.Abort()
+			return
+		}
+
+		// 5. ✅ 验证通过！将当前请求的用户信息保存到上下文 c 中
+		setCurrentUser(c, mc)
 
 		c.Next() // 放行，进入下一个环节
 	}
 }
+
+// DenyAPIKey 账号安全相关的接口 (注销、两步验证、管理 API Key 等) 只允许用户本人登录后操作
+// 必须挂在 JWTAuthMiddleware 之后
+func DenyAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, _ := c.Get("claims")
+		if mc, ok := v.(*jwt.MyClaims); ok && mc.TokenType == jwt.TokenTypeAPIKey {
+			common.Error(c, common.CodeForbidden, nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// setCurrentUser 把当前用户写进上下文
+// 这样后续的 Controller 就能知道是谁在访问了，不用关心是 JWT 还是 API Key
+func setCurrentUser(c *gin.Context, mc *jwt.MyClaims) {
+	c.Set("userID", mc.UserID)
+	c.Set("username", mc.Username)
+	// 完整的 claims 也存一份，注销等接口需要用到 jti 和过期时间，权限校验需要用到角色
+	c.Set("claims", mc)
+}
+
+// apiKeyFromRequest 取出请求里的 API Key，没有返回空字符串
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	scheme, key, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
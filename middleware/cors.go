@@ -2,31 +2,43 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Cors 处理跨域请求,支持options访问
-func Cors() gin.HandlerFunc {
+// 只有 allowedOrigins (cors.allowed_origins) 里的来源才会原样回显并允许携带 Cookie，
+// 否则任何网站都能带着用户的 Cookie 调接口；配置了 "*" 时其他来源也能访问，但不允许携带 Cookie
+func Cors(allowedOrigins []string) gin.HandlerFunc {
+	allowAny := slices.Contains(allowedOrigins, "*")
 	return func(c *gin.Context) {
 		method := c.Request.Method
 		origin := c.Request.Header.Get("Origin") // 获取请求来源
 
-		// ⚡️ 核心修复：手动补上这个 if 判断
-		// 如果有 origin 就用 origin，否则用 *
-		if origin != "" {
+		// 响应内容随 Origin 变化，告诉缓存不能混用
+		c.Header("Vary", "Origin")
+		switch {
+		case origin != "" && slices.Contains(allowedOrigins, origin):
 			c.Header("Access-Control-Allow-Origin", origin)
-		} else {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		case allowAny:
 			c.Header("Access-Control-Allow-Origin", "*")
+		default:
+			// 不在白名单里：不返回任何 CORS 头，浏览器会拦下跨域请求
+			if method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusNoContent)
+			}
+			c.Next()
+			return
 		}
 
-		c.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token, Authorization, Token")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
+		c.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token, Authorization, Token, X-API-Key")
+		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE, UPDATE")
 		c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type")
-		c.Header("Access-Control-Allow-Credentials", "true")
 
 		// 放行所有OPTIONS方法
-		if method == "OPTIONS" {
+		if method == http.MethodOptions {
 			c.AbortWithStatus(http.StatusNoContent)
 		}
 		// 处理请求
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCors 只有白名单里的来源可以携带 Cookie，"*" 只放开不带 Cookie 的访问
+func TestCors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	call := func(allowed []string, origin string) http.Header {
+		r := gin.New()
+		r.Use(Cors(allowed))
+		r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Header()
+	}
+
+	h := call([]string{"https://app.example.com"}, "https://app.example.com")
+	assert.Equal(t, "https://app.example.com", h.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", h.Get("Access-Control-Allow-Credentials"))
+
+	h = call([]string{"https://app.example.com"}, "https://evil.example.com")
+	assert.Empty(t, h.Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, h.Get("Access-Control-Allow-Credentials"))
+
+	h = call([]string{"*"}, "https://evil.example.com")
+	assert.Equal(t, "*", h.Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, h.Get("Access-Control-Allow-Credentials"), "任意来源不能携带 Cookie")
+}
@@ -14,8 +14,8 @@ import (
 )
 
 // GinLogger 接收 gin 框架默认的日志，用 zap 替代
-// ⚡️ 修改说明：移除了 logger 参数，直接使用全局的 zap.L()
-func GinLogger() gin.HandlerFunc {
+// logger 由 app.App 传进来，不使用 zap 的全局 Logger
+func GinLogger(logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -25,8 +25,7 @@ func GinLogger() gin.HandlerFunc {
 
 		cost := time.Since(start)
 
-		// ⚡️ 修改：使用 zap.L()
-		zap.L().Info(path,
+		logger.Info(path,
 			zap.Int("status", c.Writer.Status()),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
@@ -40,8 +39,7 @@ func GinLogger() gin.HandlerFunc {
 }
 
 // GinRecovery recover掉项目可能出现的panic，并使用zap记录相关日志
-// ⚡️ 修改说明：移除了 logger 参数，直接使用全局的 zap.L()
-func GinRecovery(stack bool) gin.HandlerFunc {
+func GinRecovery(logger *zap.Logger, stack bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
@@ -58,8 +56,7 @@ func GinRecovery(stack bool) gin.HandlerFunc {
 
 				httpRequest, _ := httputil.DumpRequest(c.Request, false)
 				if brokenPipe {
-					// ⚡️ 修改：使用 zap.L()
-					zap.L().Error(c.Request.URL.Path,
+					logger.Error(c.Request.URL.Path,
 						zap.Any("error", err),
 						zap.String("request", string(httpRequest)),
 					)
@@ -70,15 +67,13 @@ func GinRecovery(stack bool) gin.HandlerFunc {
 				}
 
 				if stack {
-					// ⚡️ 修改：使用 zap.L()
-					zap.L().Error("[Recovery from panic]",
+					logger.Error("[Recovery from panic]",
 						zap.Any("error", err),
 						zap.String("request", string(httpRequest)),
 						zap.String("stack", string(debug.Stack())),
 					)
 				} else {
-					// ⚡️ 修改：使用 zap.L()
-					zap.L().Error("[Recovery from panic]",
+					logger.Error("[Recovery from panic]",
 						zap.Any("error", err),
 						zap.String("request", string(httpRequest)),
 					)
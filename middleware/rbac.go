@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/logic"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+// RequirePermission 权限校验中间件，比如 RequirePermission("post:delete")
+// 必须挂在 JWTAuthMiddleware 之后；角色来自 Token，角色拥有的权限查库 (带缓存)
+func RequirePermission(svc *logic.Service, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, _ := c.Get("claims")
+		mc, ok := v.(*jwt.MyClaims)
+		if !ok {
+			common.Error(c, common.CodeNeedLogin, nil)
+			c.Abort()
+			return
+		}
+		allowed, err := svc.HasPermission(c.Request.Context(), mc, permission)
+		if err != nil {
+			common.Error(c, common.CodeServerBusy, err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			common.Error(c, common.CodeForbidden, nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope API Key 能访问的接口都要声明需要的 Scope，比如 RequireScope("post:write")
+// 登录 Token 直接放行；API Key 的 Scopes 不覆盖时拒绝，Key 泄露时只能做创建时授权过的事
+// 必须挂在 JWTAuthMiddleware 之后
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, _ := c.Get("claims")
+		mc, ok := v.(*jwt.MyClaims)
+		if !ok {
+			common.Error(c, common.CodeNeedLogin, nil)
+			c.Abort()
+			return
+		}
+		if !logic.HasScope(mc, scope) {
+			common.Error(c, common.CodeForbidden, nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
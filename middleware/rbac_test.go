@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/common"
+	"gin-api-scaffold-v1/pkg/jwt"
+)
+
+// TestRequireScope 登录 Token 不受限制，API Key 只能调用 Scopes 覆盖的接口
+func TestRequireScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	call := func(mc *jwt.MyClaims) common.ResCode {
+		r := gin.New()
+		r.POST("/posts", func(c *gin.Context) {
+			c.Set("claims", mc)
+		}, RequireScope("post:write"), func(c *gin.Context) {
+			common.Success(c, nil)
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts", nil))
+		var resp common.Response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Code
+	}
+
+	assert.Equal(t, common.CodeSuccess, call(&jwt.MyClaims{}))
+	assert.Equal(t, common.CodeSuccess, call(&jwt.MyClaims{TokenType: jwt.TokenTypeAPIKey, Scopes: []string{"post:*"}}))
+	assert.Equal(t, common.CodeForbidden, call(&jwt.MyClaims{TokenType: jwt.TokenTypeAPIKey, Scopes: []string{"comment:write"}}))
+	assert.Equal(t, common.CodeForbidden, call(&jwt.MyClaims{TokenType: jwt.TokenTypeAPIKey}), "没有 Scopes 的 Key 什么都调不了")
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout 给每个请求设置处理期限
+// 期限挂在 c.Request 的 Context 上，controller 把它一路传到 logic / dao，
+// 超时后正在执行的 MySQL / Redis 查询会被取消，接口返回 common.CodeRequestTimeout
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/common"
+)
+
+// TestTimeout 处理函数里等待的查询在期限到了之后被取消，接口返回请求超时
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Timeout(20 * time.Millisecond))
+	r.GET("/slow", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			common.Error(c, common.CodeServerBusy, c.Request.Context().Err())
+		case <-time.After(time.Second):
+			common.Success(c, nil)
+		}
+	})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Less(t, time.Since(start), time.Second)
+
+	var resp common.Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, common.CodeRequestTimeout, resp.Code)
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
+
+	"gin-api-scaffold-v1/common"
+)
+
+// Translator 把校验错误的翻译器放进请求上下文，common.Error 用它把参数错误翻译成中文
+func Translator(trans ut.Translator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(common.ContextTranslatorKey, trans)
+		c.Next()
+	}
+}
@@ -0,0 +1,16 @@
+-- 用户表
+-- 对应 models.User 最初的结构，后面的字段由之后的迁移添加
+
+CREATE TABLE IF NOT EXISTS `user` (
+    `id`          BIGINT      NOT NULL AUTO_INCREMENT,
+    `user_id`     BIGINT      NOT NULL,
+    `username`    VARCHAR(64) NOT NULL,
+    `password`    VARCHAR(64) NOT NULL,
+    `email`       VARCHAR(64) NULL,
+    `gender`      TINYINT     NOT NULL DEFAULT 0 COMMENT '0 保密 / 1 男 / 2 女',
+    `create_time` DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_user_id` (`user_id`),
+    UNIQUE KEY `idx_username` (`username`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,2 @@
+-- 已经有 argon2id 哈希时会被截断，回滚前先确认没有这样的数据
+ALTER TABLE `user` MODIFY COLUMN `password` VARCHAR(64) NOT NULL;
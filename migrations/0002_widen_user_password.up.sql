@@ -0,0 +1,3 @@
+-- argon2id 的 PHC 编码哈希约 97 个字符，bcrypt 60 个字符，老的 varchar(64) 放不下
+
+ALTER TABLE `user` MODIFY COLUMN `password` VARCHAR(255) NOT NULL;
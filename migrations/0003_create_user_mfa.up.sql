@@ -0,0 +1,24 @@
+-- 两步验证 (TOTP) 相关表
+-- 对应 models.UserMFA / models.UserRecoveryCode
+
+CREATE TABLE IF NOT EXISTS `user_mfa` (
+    `id`             BIGINT       NOT NULL AUTO_INCREMENT,
+    `user_id`        BIGINT       NOT NULL,
+    `secret`         VARCHAR(64)  NOT NULL DEFAULT '' COMMENT '已启用的 TOTP 密钥 (base32)',
+    `pending_secret` VARCHAR(64)  NOT NULL DEFAULT '' COMMENT '绑定中、未确认的密钥',
+    `enabled`        TINYINT(1)   NOT NULL DEFAULT 0,
+    `create_time`    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time`    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_recovery_code` (
+    `id`          BIGINT      NOT NULL AUTO_INCREMENT,
+    `user_id`     BIGINT      NOT NULL,
+    `code_hash`   CHAR(64)    NOT NULL COMMENT 'SHA-256(恢复码)',
+    `used_at`     DATETIME    NULL,
+    `create_time` DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,3 @@
+ALTER TABLE `user`
+    DROP KEY `idx_email`,
+    DROP COLUMN `email_verified`;
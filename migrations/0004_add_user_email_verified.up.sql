@@ -0,0 +1,5 @@
+-- 注册时收集邮箱，并记录邮箱是否已验证
+
+ALTER TABLE `user`
+    ADD COLUMN `email_verified` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '邮箱是否已验证' AFTER `email`,
+    ADD KEY `idx_email` (`email`);
@@ -0,0 +1,49 @@
+-- 角色 / 权限 (RBAC) 相关表
+-- 对应 models.Role / models.Permission / models.RolePermission / models.UserRole
+
+CREATE TABLE IF NOT EXISTS `role` (
+    `id`          BIGINT       NOT NULL AUTO_INCREMENT,
+    `name`        VARCHAR(64)  NOT NULL,
+    `description` VARCHAR(255) NOT NULL DEFAULT '',
+    `create_time` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_name` (`name`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `permission` (
+    `id`          BIGINT       NOT NULL AUTO_INCREMENT,
+    `code`        VARCHAR(128) NOT NULL COMMENT '<资源>:<动作>，支持 post:* 和 * 通配',
+    `description` VARCHAR(255) NOT NULL DEFAULT '',
+    `create_time` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_code` (`code`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `role_permission` (
+    `role_id`       BIGINT NOT NULL,
+    `permission_id` BIGINT NOT NULL,
+    PRIMARY KEY (`role_id`, `permission_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_role` (
+    `user_id`     BIGINT   NOT NULL,
+    `role_id`     BIGINT   NOT NULL,
+    `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`, `role_id`),
+    KEY `idx_role_id` (`role_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- 内置角色和权限 (admin 在代码里拥有全部权限，不需要配置 role_permission)
+INSERT IGNORE INTO `role` (`name`, `description`) VALUES
+    ('admin', '超级管理员'),
+    ('moderator', '版主');
+
+INSERT IGNORE INTO `permission` (`code`, `description`) VALUES
+    ('user:unlock', '解除登录锁定'),
+    ('role:assign', '分配 / 收回角色'),
+    ('post:delete', '删除任意帖子'),
+    ('comment:delete', '删除任意评论');
+
+INSERT IGNORE INTO `role_permission` (`role_id`, `permission_id`)
+SELECT r.id, p.id FROM `role` r JOIN `permission` p
+WHERE r.name = 'moderator' AND p.code IN ('post:delete', 'comment:delete', 'user:unlock');
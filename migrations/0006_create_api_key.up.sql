@@ -0,0 +1,20 @@
+-- 个人 API Key
+-- 对应 models.APIKey
+
+CREATE TABLE IF NOT EXISTS `api_key` (
+    `id`           BIGINT       NOT NULL AUTO_INCREMENT,
+    `key_id`       BIGINT       NOT NULL COMMENT '对外暴露的编号',
+    `user_id`      BIGINT       NOT NULL,
+    `name`         VARCHAR(64)  NOT NULL,
+    `prefix`       VARCHAR(16)  NOT NULL COMMENT '明文前几位，方便辨认',
+    `key_hash`     CHAR(64)     NOT NULL COMMENT 'SHA-256(明文)',
+    `scopes`       VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '逗号分隔的权限点',
+    `expires_at`   DATETIME     NULL,
+    `last_used_at` DATETIME     NULL,
+    `revoked_at`   DATETIME     NULL,
+    `create_time`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_key_id` (`key_id`),
+    UNIQUE KEY `idx_key_hash` (`key_hash`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
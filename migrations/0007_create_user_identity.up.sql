@@ -0,0 +1,14 @@
+-- 第三方登录 (OIDC) 绑定关系
+-- 对应 models.UserIdentity
+
+CREATE TABLE IF NOT EXISTS `user_identity` (
+    `id`          BIGINT       NOT NULL AUTO_INCREMENT,
+    `user_id`     BIGINT       NOT NULL,
+    `provider`    VARCHAR(64)  NOT NULL COMMENT 'oidc.providers 里的名字',
+    `subject`     VARCHAR(255) NOT NULL COMMENT 'ID Token 的 sub',
+    `email`       VARCHAR(255) NOT NULL DEFAULT '',
+    `create_time` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_provider_subject` (`provider`, `subject`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE `user` DROP COLUMN `avatar`;
+DROP TABLE IF EXISTS `file`;
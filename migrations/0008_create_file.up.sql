@@ -0,0 +1,17 @@
+-- 上传文件 (头像等)，按内容 SHA-256 去重
+-- 对应 models.File / models.User.Avatar
+
+CREATE TABLE IF NOT EXISTS `file` (
+    `id`          BIGINT       NOT NULL AUTO_INCREMENT,
+    `hash`        CHAR(64)     NOT NULL COMMENT 'SHA-256(文件内容)',
+    `storage_key` VARCHAR(255) NOT NULL COMMENT '在存储里的位置',
+    `size`        BIGINT       NOT NULL,
+    `mime_type`   VARCHAR(128) NOT NULL,
+    `uploader_id` BIGINT       NOT NULL COMMENT '第一次上传的用户',
+    `create_time` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_hash` (`hash`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE `user`
+    ADD COLUMN `avatar` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '头像在存储里的位置 (file.storage_key)' AFTER `gender`;
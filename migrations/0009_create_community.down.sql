@@ -0,0 +1,6 @@
+DELETE rp FROM `role_permission` rp JOIN `permission` p ON rp.permission_id = p.id
+WHERE p.code = 'community:manage';
+DELETE FROM `permission` WHERE `code` = 'community:manage';
+
+DROP TABLE IF EXISTS `community_member`;
+DROP TABLE IF EXISTS `community`;
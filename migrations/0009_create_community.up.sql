@@ -0,0 +1,35 @@
+-- 社区 (版块) 和社区成员
+-- 对应 models.Community / models.CommunityMember
+
+CREATE TABLE IF NOT EXISTS `community` (
+    `id`           BIGINT       NOT NULL AUTO_INCREMENT,
+    `community_id` BIGINT       NOT NULL,
+    `name`         VARCHAR(64)  NOT NULL,
+    `introduction` VARCHAR(256) NOT NULL DEFAULT '',
+    `creator_id`   BIGINT       NOT NULL,
+    `member_count` BIGINT       NOT NULL DEFAULT 0,
+    `create_time`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_community_id` (`community_id`),
+    UNIQUE KEY `idx_name` (`name`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `community_member` (
+    `id`           BIGINT      NOT NULL AUTO_INCREMENT,
+    `community_id` BIGINT      NOT NULL,
+    `user_id`      BIGINT      NOT NULL,
+    `role`         VARCHAR(16) NOT NULL DEFAULT 'member' COMMENT 'owner / moderator / member',
+    `create_time`  DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_community_user` (`community_id`, `user_id`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- 管理任意社区的全局权限 (版主角色默认拥有)
+INSERT IGNORE INTO `permission` (`code`, `description`) VALUES
+    ('community:manage', '管理任意社区 (任免版主、管理帖子评论)');
+
+INSERT IGNORE INTO `role_permission` (`role_id`, `permission_id`)
+SELECT r.id, p.id FROM `role` r JOIN `permission` p
+WHERE r.name = 'moderator' AND p.code = 'community:manage';
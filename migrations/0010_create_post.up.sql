@@ -0,0 +1,17 @@
+-- 帖子
+-- 对应 models.Post
+
+CREATE TABLE IF NOT EXISTS `post` (
+    `id`           BIGINT       NOT NULL AUTO_INCREMENT,
+    `post_id`      BIGINT       NOT NULL,
+    `author_id`    BIGINT       NOT NULL,
+    `community_id` BIGINT       NOT NULL,
+    `title`        VARCHAR(128) NOT NULL,
+    `content`      TEXT         NOT NULL,
+    `create_time`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_post_id` (`post_id`),
+    KEY `idx_author_id` (`author_id`),
+    KEY `idx_community_id` (`community_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
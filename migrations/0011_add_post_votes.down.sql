@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `post_vote`;
+
+ALTER TABLE `post`
+    DROP COLUMN `vote_closed`,
+    DROP COLUMN `down_votes`,
+    DROP COLUMN `up_votes`;
@@ -0,0 +1,16 @@
+-- 帖子投票
+-- 投票期内的票数在 Redis 里，投票期结束后由后台任务写入这里
+-- 对应 models.Post 的票数字段和 models.PostVote
+
+ALTER TABLE `post`
+    ADD COLUMN `up_votes`    BIGINT     NOT NULL DEFAULT 0 AFTER `content`,
+    ADD COLUMN `down_votes`  BIGINT     NOT NULL DEFAULT 0 AFTER `up_votes`,
+    ADD COLUMN `vote_closed` TINYINT(1) NOT NULL DEFAULT 0 AFTER `down_votes`;
+
+CREATE TABLE IF NOT EXISTS `post_vote` (
+    `post_id`   BIGINT  NOT NULL,
+    `user_id`   BIGINT  NOT NULL,
+    `direction` TINYINT NOT NULL COMMENT '1 赞成 / -1 反对',
+    PRIMARY KEY (`post_id`, `user_id`),
+    KEY `idx_user_id` (`user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
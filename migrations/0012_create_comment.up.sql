@@ -0,0 +1,19 @@
+-- 评论
+-- 对应 models.Comment
+
+CREATE TABLE IF NOT EXISTS `comment` (
+    `id`          BIGINT     NOT NULL AUTO_INCREMENT,
+    `comment_id`  BIGINT     NOT NULL,
+    `post_id`     BIGINT     NOT NULL,
+    `parent_id`   BIGINT     NOT NULL DEFAULT 0 COMMENT '0 表示一级评论',
+    `author_id`   BIGINT     NOT NULL,
+    `content`     TEXT       NOT NULL,
+    `deleted`     TINYINT(1) NOT NULL DEFAULT 0 COMMENT '软删除，删除后内容清空',
+    `create_time` DATETIME   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `update_time` DATETIME   NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_comment_id` (`comment_id`),
+    KEY `idx_post_parent` (`post_id`, `parent_id`, `comment_id`),
+    KEY `idx_parent_id` (`parent_id`),
+    KEY `idx_author_id` (`author_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE `user` DROP INDEX `ft_user_username`;
+ALTER TABLE `post` DROP INDEX `ft_post_title_content`;
@@ -0,0 +1,7 @@
+-- 搜索用的全文索引
+-- 使用 ngram parser 支持中文，分词长度由 MySQL 的 ngram_token_size 决定 (默认 2，和 pkg/search.Tokenize 一致)
+-- 对应 dao.MySQLSearchEngine
+
+ALTER TABLE `post` ADD FULLTEXT INDEX `ft_post_title_content` (`title`, `content`) WITH PARSER ngram;
+
+ALTER TABLE `user` ADD FULLTEXT INDEX `ft_user_username` (`username`) WITH PARSER ngram;
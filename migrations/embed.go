@@ -0,0 +1,8 @@
+// Package migrations 数据库迁移脚本，编译时嵌入二进制
+// 新建迁移用 ./main migrate create <名字>，不要修改已经发布过的迁移
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
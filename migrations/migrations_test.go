@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/pkg/migrate"
+)
+
+// TestMigrations 嵌入的迁移都能加载，版本号从 1 开始连续
+func TestMigrations(t *testing.T) {
+	list, err := migrate.Load(FS)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, list)
+	for i, mg := range list {
+		assert.Equal(t, int64(i+1), mg.Version, mg.Name)
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// APIKey 用户的个人 API Key (给脚本 / 其他服务调用接口用)
+// 只保存 SHA-256 哈希，明文只在创建时返回一次
+type APIKey struct {
+	ID     int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	KeyID  int64  `gorm:"column:key_id;not null;uniqueIndex"` // 对外暴露的编号
+	UserID int64  `gorm:"column:user_id;not null;index"`
+	Name   string `gorm:"column:name;not null"`
+	// Prefix 明文的前几位，方便用户在列表里认出是哪个 Key
+	Prefix  string `gorm:"column:prefix;not null"`
+	KeyHash string `gorm:"column:key_hash;not null;uniqueIndex"`
+	// Scopes 逗号分隔的权限点，API Key 只能使用用户权限和 Scopes 的交集
+	Scopes     string     `gorm:"column:scopes"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at"` // 为空表示永不过期
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+	CreateTime time.Time  `gorm:"column:create_time;autoCreateTime"`
+}
+
+func (APIKey) TableName() string {
+	return "api_key"
+}
+
+// ParamCreateAPIKey 创建 API Key 参数
+type ParamCreateAPIKey struct {
+	Name string `json:"name" binding:"required,max=64"`
+	// Scopes 权限点列表，比如 ["post:write", "comment:*"]，每个接口都要求对应的 Scope，不传则什么接口都调不了
+	Scopes []string `json:"scopes" binding:"omitempty,dive,required,max=128,excludesall=0x2C"`
+	// ExpireDays 有效期(天)，不传表示永不过期
+	ExpireDays int `json:"expire_days" binding:"omitempty,gte=1,lte=3650"`
+}
+
+// ResAPIKey API Key 列表里的一项 (不含明文)
+type ResAPIKey struct {
+	ID         int64      `json:"id,string"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreateTime time.Time  `json:"create_time"`
+}
+
+// ResCreateAPIKey 创建成功的返回，Key 明文只出现这一次
+type ResCreateAPIKey struct {
+	ResAPIKey
+	Key string `json:"key"`
+}
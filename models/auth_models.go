@@ -0,0 +1,45 @@
+package models
+
+// ParamRefreshToken 刷新 Token 参数
+// Cookie 登录模式下可以不传，从 Cookie 里取
+type ParamRefreshToken struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ResToken 登录 / 刷新成功后返回给前端的 Token 对
+// 开启了两步验证的用户，登录第一步只返回 MFARequired + MFAToken，不返回正式 Token
+type ResToken struct {
+	// Token 与 AccessToken 相同，保留这个字段是为了兼容只认 token 的老前端
+	Token        string `json:"token,omitempty"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"` // Access Token 有效期 (秒)
+	Username     string `json:"username"`
+
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"` // 拿着它和验证码调 /login/mfa
+}
+
+// ParamUnlockLogin 管理员解锁登录参数
+type ParamUnlockLogin struct {
+	Username string `json:"username" binding:"required"`
+	// IP 可选，传了会一并解除这个 IP 的锁定
+	IP string `json:"ip" binding:"omitempty,ip"`
+}
+
+// ParamEmailToken 邮件链接里带的 Token (验证邮箱)
+type ParamEmailToken struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ParamForgotPassword 找回密码参数
+type ParamForgotPassword struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ParamResetPassword 重置密码参数
+type ParamResetPassword struct {
+	Token      string `json:"token" binding:"required"`
+	Password   string `json:"password" binding:"required,min=6,max=72"`
+	RePassword string `json:"re_password" binding:"required,eqfield=Password"`
+}
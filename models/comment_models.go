@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"gin-api-scaffold-v1/common"
+)
+
+// DeletedCommentText 被删除的评论对外显示的内容
+const DeletedCommentText = "[deleted]"
+
+// 评论树默认展开几层、最多展开几层
+const (
+	defaultCommentDepth = 3
+	maxCommentDepth     = 10
+)
+
+// Comment 评论
+// 删除是软删除：回复还挂在下面，楼层结构不变，只是内容不再显示
+type Comment struct {
+	ID         int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	CommentID  int64     `gorm:"column:comment_id;not null;uniqueIndex"` // 对外暴露的编号 (雪花 ID)
+	PostID     int64     `gorm:"column:post_id;not null;index"`
+	ParentID   int64     `gorm:"column:parent_id;not null;index"` // 0 表示直接回复帖子的一级评论
+	AuthorID   int64     `gorm:"column:author_id;not null;index"`
+	Content    string    `gorm:"column:content;not null"`
+	Deleted    bool      `gorm:"column:deleted;not null"`
+	CreateTime time.Time `gorm:"column:create_time;autoCreateTime"`
+	UpdateTime time.Time `gorm:"column:update_time;autoUpdateTime"`
+}
+
+func (Comment) TableName() string {
+	return "comment"
+}
+
+// ParamCreateComment 发表评论参数，parent_id 不传表示直接回复帖子
+type ParamCreateComment struct {
+	ParentID int64  `json:"parent_id,string"`
+	Content  string `json:"content" binding:"required,notblank,max=5000"`
+}
+
+// ParamCommentTree 评论树参数 (query: ?cursor=xxx&size=20&depth=3)
+// 只对一级评论分页，每个一级评论下面的回复展开 depth - 1 层；查某条评论的回复时对它的直接回复分页
+type ParamCommentTree struct {
+	ParamCursor
+	Depth int `form:"depth" binding:"omitempty,gte=1,lte=10"`
+}
+
+// Normalize 没传的参数填上默认值
+func (p *ParamCommentTree) Normalize() {
+	p.ParamCursor.Normalize()
+	if p.Depth <= 0 {
+		p.Depth = defaultCommentDepth
+	}
+	if p.Depth > maxCommentDepth {
+		p.Depth = maxCommentDepth
+	}
+}
+
+// ResComment 评论，Replies 是展开的回复
+// ReplyCount 是直接回复的数量，每条评论最多展开前几条回复，超过展开层数的评论 Replies 为空，
+// Replies 比 ReplyCount 少时前端可以显示"查看更多回复"，用 GET /comments/{id}/replies 分页加载
+type ResComment struct {
+	ID         int64         `json:"id,string"`
+	PostID     int64         `json:"post_id,string"`
+	ParentID   int64         `json:"parent_id,string"`
+	AuthorID   int64         `json:"author_id,string"`
+	AuthorName string        `json:"author_name"`
+	Content    string        `json:"content"`
+	Deleted    bool          `json:"deleted"`
+	ReplyCount int64         `json:"reply_count"`
+	Replies    []*ResComment `json:"replies"`
+	CreateTime time.Time     `json:"create_time"`
+}
+
+// ResCommentReplies 某条评论的回复 (游标分页)，每条回复同样展开 depth - 1 层
+type ResCommentReplies struct {
+	List []*ResComment `json:"list"`
+	common.CursorPage
+}
+
+// ResCommentTree 帖子的评论树
+type ResCommentTree struct {
+	List  []*ResComment `json:"list"`
+	Total int64         `json:"total"` // 帖子的评论总数 (不含已删除的)
+	common.CursorPage
+}
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// 社区成员的身份
+const (
+	CommunityRoleOwner     = "owner"     // 创建者，可以任免版主，不能退出
+	CommunityRoleModerator = "moderator" // 版主，可以管理社区里的帖子和评论
+	CommunityRoleMember    = "member"
+)
+
+// Community 社区 (版块)
+type Community struct {
+	ID           int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	CommunityID  int64     `gorm:"column:community_id;not null;uniqueIndex"` // 对外暴露的编号
+	Name         string    `gorm:"column:name;not null;uniqueIndex"`
+	Introduction string    `gorm:"column:introduction"`
+	CreatorID    int64     `gorm:"column:creator_id;not null"`
+	MemberCount  int64     `gorm:"column:member_count;not null"` // 冗余的成员数，加入 / 退出时在同一个事务里更新
+	CreateTime   time.Time `gorm:"column:create_time;autoCreateTime"`
+	UpdateTime   time.Time `gorm:"column:update_time;autoUpdateTime"`
+}
+
+func (Community) TableName() string {
+	return "community"
+}
+
+// CommunityMember 社区成员
+type CommunityMember struct {
+	ID          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	CommunityID int64     `gorm:"column:community_id;not null"`
+	UserID      int64     `gorm:"column:user_id;not null"`
+	Role        string    `gorm:"column:role;not null"` // owner / moderator / member
+	CreateTime  time.Time `gorm:"column:create_time;autoCreateTime"`
+}
+
+func (CommunityMember) TableName() string {
+	return "community_member"
+}
+
+// ParamCreateCommunity 创建社区参数
+type ParamCreateCommunity struct {
+	Name         string `json:"name" binding:"required,min=2,max=64"`
+	Introduction string `json:"introduction" binding:"omitempty,max=256"`
+}
+
+// ResCommunity 社区列表里的一项
+type ResCommunity struct {
+	ID           int64     `json:"id,string"`
+	Name         string    `json:"name"`
+	Introduction string    `json:"introduction"`
+	MemberCount  int64     `json:"member_count"`
+	CreateTime   time.Time `json:"create_time"`
+}
+
+// ResCommunityList 社区列表 (分页)
+type ResCommunityList struct {
+	List  []*ResCommunity `json:"list"`
+	Total int64           `json:"total"`
+	Page  int             `json:"page"`
+	Size  int             `json:"size"`
+}
+
+// ResCommunityMember 社区的创建者 / 版主
+type ResCommunityMember struct {
+	UserID   int64  `json:"user_id,string"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// ResCommunityDetail 社区详情，带上创建者和版主
+type ResCommunityDetail struct {
+	ResCommunity
+	Moderators []*ResCommunityMember `json:"moderators"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// File 上传过的文件 (按内容去重：同样的内容只存一份)
+type File struct {
+	ID int64 `gorm:"column:id;primaryKey;autoIncrement"`
+	// Hash 内容的 SHA-256，用来去重
+	Hash       string    `gorm:"column:hash;not null;uniqueIndex"`
+	Key        string    `gorm:"column:storage_key;not null"` // 在存储里的位置
+	Size       int64     `gorm:"column:size;not null"`
+	MimeType   string    `gorm:"column:mime_type;not null"`
+	UploaderID int64     `gorm:"column:uploader_id;not null"` // 第一次上传的用户
+	CreateTime time.Time `gorm:"column:create_time;autoCreateTime"`
+}
+
+func (File) TableName() string {
+	return "file"
+}
+
+// ResFile 上传成功后返回的文件信息
+type ResFile struct {
+	Hash     string `json:"hash"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mime_type"`
+	URL      string `json:"url"` // 限时有效的下载地址
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// UserMFA 用户的两步验证 (TOTP) 设置，一个用户一行
+type UserMFA struct {
+	ID     int64 `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID int64 `gorm:"column:user_id;not null;uniqueIndex"`
+	// Secret 已确认启用的 TOTP 密钥 (base32)
+	Secret string `gorm:"column:secret"`
+	// PendingSecret 绑定中、还没确认的密钥。确认之前不影响已启用的 Secret，方便更换手机
+	PendingSecret string    `gorm:"column:pending_secret"`
+	Enabled       bool      `gorm:"column:enabled;not null;default:false"`
+	CreateTime    time.Time `gorm:"column:create_time;autoCreateTime"`
+	UpdateTime    time.Time `gorm:"column:update_time;autoUpdateTime"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// UserRecoveryCode 两步验证恢复码，只保存哈希，每个只能用一次
+type UserRecoveryCode struct {
+	ID         int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID     int64      `gorm:"column:user_id;not null;index"`
+	CodeHash   string     `gorm:"column:code_hash;not null"`
+	UsedAt     *time.Time `gorm:"column:used_at"`
+	CreateTime time.Time  `gorm:"column:create_time;autoCreateTime"`
+}
+
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_code"
+}
+
+// ParamMFAEnroll 开始绑定两步验证参数
+type ParamMFAEnroll struct {
+	// Code 已经开启两步验证时 (换绑) 必填：当前的 6 位验证码，或者一个恢复码
+	Code string `json:"code"`
+}
+
+// ParamMFACode 需要输入验证码的操作 (确认绑定、重新生成恢复码)
+type ParamMFACode struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// ParamLoginMFA 两步登录第二步参数
+type ParamLoginMFA struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	// Code 6 位 TOTP 验证码，或者一个恢复码
+	Code string `json:"code" binding:"required"`
+}
+
+// ResMFAEnroll 开始绑定时返回的信息
+type ResMFAEnroll struct {
+	Secret string `json:"secret"`      // 手动输入用
+	URI    string `json:"otpauth_uri"` // 前端渲染成二维码
+}
+
+// ResRecoveryCodes 恢复码 (明文只在生成时返回这一次)
+type ResRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// UserIdentity 用户绑定的第三方身份 (OIDC)，一个用户可以绑定多个身份提供方
+type UserIdentity struct {
+	ID       int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID   int64  `gorm:"column:user_id;not null;index"`
+	Provider string `gorm:"column:provider;not null;uniqueIndex:idx_provider_subject"`
+	// Subject 身份提供方那边的用户唯一标识 (ID Token 的 sub)，邮箱可能会变，sub 不会
+	Subject    string    `gorm:"column:subject;not null;uniqueIndex:idx_provider_subject"`
+	Email      string    `gorm:"column:email"`
+	CreateTime time.Time `gorm:"column:create_time;autoCreateTime"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identity"
+}
+
+// OIDCState 发起第三方登录时保存在 Redis 里的状态，回调时按 state 取出来
+type OIDCState struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"` // PKCE code_verifier，只保存在服务端
+	Nonce    string `json:"nonce"`
+}
+
+// ResOIDCAuthURL 第三方登录跳转地址
+type ResOIDCAuthURL struct {
+	AuthURL string `json:"auth_url"`
+}
+
+// ParamOIDCCallback 第三方登录回调参数 (前端从回调地址的 query 里取出来提交)
+type ParamOIDCCallback struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
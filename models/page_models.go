@@ -0,0 +1,48 @@
+package models
+
+// 分页参数的默认值和上限
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// ParamPage 分页参数 (query: ?page=1&size=20)
+type ParamPage struct {
+	Page int `form:"page" binding:"omitempty,gte=1"`
+	Size int `form:"size" binding:"omitempty,gte=1,lte=100"`
+}
+
+// Normalize 没传的参数填上默认值
+func (p *ParamPage) Normalize() {
+	if p.Page <= 0 {
+		p.Page = 1
+	}
+	if p.Size <= 0 {
+		p.Size = defaultPageSize
+	}
+	if p.Size > maxPageSize {
+		p.Size = maxPageSize
+	}
+}
+
+// Offset 跳过多少条
+func (p *ParamPage) Offset() int {
+	return (p.Page - 1) * p.Size
+}
+
+// ParamCursor 游标分页参数 (query: ?cursor=xxx&size=20)
+// cursor 是上一页返回的 next_cursor，第一页不传
+type ParamCursor struct {
+	Cursor string `form:"cursor"`
+	Size   int    `form:"size" binding:"omitempty,gte=1,lte=100"`
+}
+
+// Normalize 没传的参数填上默认值
+func (p *ParamCursor) Normalize() {
+	if p.Size <= 0 {
+		p.Size = defaultPageSize
+	}
+	if p.Size > maxPageSize {
+		p.Size = maxPageSize
+	}
+}
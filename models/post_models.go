@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"gin-api-scaffold-v1/common"
+)
+
+// Post 帖子
+type Post struct {
+	ID          int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	PostID      int64  `gorm:"column:post_id;not null;uniqueIndex"` // 对外暴露的编号 (雪花 ID)
+	AuthorID    int64  `gorm:"column:author_id;not null;index"`
+	CommunityID int64  `gorm:"column:community_id;not null;index"`
+	Title       string `gorm:"column:title;not null"`
+	Content     string `gorm:"column:content;not null"`
+	// 投票期内票数以 Redis 为准，投票期结束后归档到这里，VoteClosed 置为 true
+	UpVotes    int64     `gorm:"column:up_votes;not null"`
+	DownVotes  int64     `gorm:"column:down_votes;not null"`
+	VoteClosed bool      `gorm:"column:vote_closed;not null"`
+	CreateTime time.Time `gorm:"column:create_time;autoCreateTime"`
+	UpdateTime time.Time `gorm:"column:update_time;autoUpdateTime"`
+}
+
+func (Post) TableName() string {
+	return "post"
+}
+
+// PostVote 归档后的投票记录
+type PostVote struct {
+	PostID    int64 `gorm:"column:post_id;primaryKey"`
+	UserID    int64 `gorm:"column:user_id;primaryKey"`
+	Direction int8  `gorm:"column:direction;not null"` // 1 赞成 / -1 反对
+}
+
+func (PostVote) TableName() string {
+	return "post_vote"
+}
+
+// 帖子列表的排序方式
+const (
+	OrderTime  = "time"  // 最新
+	OrderScore = "score" // 最热
+)
+
+// ParamPostList 帖子列表参数 (query: ?cursor=xxx&size=20&order=score)
+// 换了排序方式之后要从第一页重新开始，游标只对生成它的排序方式有效
+type ParamPostList struct {
+	ParamCursor
+	Order string `form:"order" binding:"omitempty,oneof=time score"` // 不传默认 time
+}
+
+// ParamVote 投票参数
+type ParamVote struct {
+	// Direction 1 赞成 / -1 反对 / 0 取消投票
+	Direction *int8 `json:"direction" binding:"required,oneof=1 0 -1"`
+}
+
+// ResVote 帖子的票数
+type ResVote struct {
+	UpVotes   int64 `json:"up_votes"`
+	DownVotes int64 `json:"down_votes"`
+}
+
+// ParamCreatePost 发帖参数
+type ParamCreatePost struct {
+	CommunityID int64  `json:"community_id,string" binding:"required"`
+	Title       string `json:"title" binding:"required,notblank,max=128"`
+	Content     string `json:"content" binding:"required,notblank,max=20000"`
+}
+
+// ParamUpdatePost 编辑帖子参数 (只能改标题和内容，不能换社区)
+type ParamUpdatePost struct {
+	Title   string `json:"title" binding:"required,notblank,max=128"`
+	Content string `json:"content" binding:"required,notblank,max=20000"`
+}
+
+// ResPost 帖子详情
+type ResPost struct {
+	ID            int64  `json:"id,string"`
+	CommunityID   int64  `json:"community_id,string"`
+	CommunityName string `json:"community_name"`
+	AuthorID      int64  `json:"author_id,string"`
+	AuthorName    string `json:"author_name"`
+	Title         string `json:"title"`
+	Content       string `json:"content"`
+	ResVote
+	CommentCount int64     `json:"comment_count"`
+	CreateTime   time.Time `json:"create_time"`
+	UpdateTime   time.Time `json:"update_time"`
+}
+
+// ResPostList 帖子列表 (游标分页)
+type ResPostList struct {
+	List  []*ResPost `json:"list"`
+	Total int64      `json:"total"` // 排行里的帖子总数
+	common.CursorPage
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Role 角色，比如 admin / moderator
+type Role struct {
+	ID          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Name        string    `gorm:"column:name;not null;uniqueIndex"`
+	Description string    `gorm:"column:description"`
+	CreateTime  time.Time `gorm:"column:create_time;autoCreateTime"`
+}
+
+func (Role) TableName() string {
+	return "role"
+}
+
+// Permission 权限点，格式 <资源>:<动作>，比如 post:delete
+// 分配给角色时可以用通配符：post:* 表示 post 下的所有动作，* 表示全部权限
+type Permission struct {
+	ID          int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Code        string    `gorm:"column:code;not null;uniqueIndex"`
+	Description string    `gorm:"column:description"`
+	CreateTime  time.Time `gorm:"column:create_time;autoCreateTime"`
+}
+
+func (Permission) TableName() string {
+	return "permission"
+}
+
+// RolePermission 角色拥有哪些权限
+type RolePermission struct {
+	RoleID       int64 `gorm:"column:role_id;primaryKey"`
+	PermissionID int64 `gorm:"column:permission_id;primaryKey"`
+}
+
+func (RolePermission) TableName() string {
+	return "role_permission"
+}
+
+// UserRole 用户拥有哪些角色
+type UserRole struct {
+	UserID     int64     `gorm:"column:user_id;primaryKey"`
+	RoleID     int64     `gorm:"column:role_id;primaryKey"`
+	CreateTime time.Time `gorm:"column:create_time;autoCreateTime"`
+}
+
+func (UserRole) TableName() string {
+	return "user_role"
+}
+
+// ParamAssignRole 给用户分配角色参数
+type ParamAssignRole struct {
+	Role string `json:"role" binding:"required"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// ParamSearch 搜索参数 (query: ?q=redis&type=post&community_id=1&start_date=2026-01-01&end_date=2026-01-31&page=1&size=20)
+type ParamSearch struct {
+	ParamPage
+	Q           string `form:"q" binding:"required,notblank,max=64"`
+	Type        string `form:"type" binding:"omitempty,oneof=post user"` // 不传默认 post
+	CommunityID int64  `form:"community_id"`                             // 只对帖子有效
+	// 按创建时间过滤，两端都包含
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate   time.Time `form:"end_date" time_format:"2006-01-02"`
+}
+
+// ResSearchPost 搜索到的帖子，highlight 里命中的部分用 <em> 标出 (已经做过 HTML 转义)
+type ResSearchPost struct {
+	*ResPost
+	HighlightTitle   string `json:"highlight_title"`
+	HighlightContent string `json:"highlight_content"` // 命中位置附近的片段
+}
+
+// ResSearchUser 搜索到的用户
+type ResSearchUser struct {
+	UserID            int64  `json:"user_id,string"`
+	Username          string `json:"username"`
+	HighlightUsername string `json:"highlight_username"`
+	AvatarURL         string `json:"avatar_url"`
+}
+
+// ResSearch 搜索结果 (分页)，按相关度排序；type 为 post 时只有 posts，为 user 时只有 users
+type ResSearch struct {
+	Type  string           `json:"type"`
+	Posts []*ResSearchPost `json:"posts,omitempty"`
+	Users []*ResSearchUser `json:"users,omitempty"`
+	Total int64            `json:"total"`
+	Page  int              `json:"page"`
+	Size  int              `json:"size"`
+}
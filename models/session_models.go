@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ClientInfo 发起登录的客户端信息，记录到会话里
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// Session 一次登录 (设备)。ID 就是这次登录的 Refresh Token family ID，
+// 轮换 Token 时 jti 会变，family ID 不变，正好对应 "一台设备上的一次登录"
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     int64     `json:"-"`
+	Device     string    `json:"device"` // 从 User-Agent 解析出来的简短描述，比如 "Chrome on Windows"
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"` // 是不是发起这次请求的设备
+}
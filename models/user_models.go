@@ -2,72 +2,51 @@ package models
 
 import "time"
 
-// SignUpParam 用户注册请求参数
-type SignUpParam struct {
-	// 1. 基础校验：必填
-	// json:"username" -> 报错时显示 "username"
-	// binding:"required" -> 必填
-	Username string `json:"username" binding:"required"`
-
-	// 2. 长度与格式校验
-	// min=6,max=20 -> 长度限制在 6 到 20 之间
-	Password string `json:"password" binding:"required,min=6,max=20"`
-
-	// 3. 跨字段校验 (最酷的功能！)
-	// eqfield=Password -> 这个字段的值必须和 Password 字段的值完全一样
-	// 常用于“确认密码”场景
-	RePassword string `json:"re_password" binding:"required,eqfield=Password"`
-
-	// 4. 类型校验
-	// email -> 必须是合法的邮箱格式 (xxx@xxx.com)
-	Email string `json:"email" binding:"required,email"`
-
-	// 5. 数值范围校验
-	// gte=1 -> 大于等于 1 (Greater Than or Equal)
-	// lte=130 -> 小于等于 130 (Less Than or Equal)
-	Age uint8 `json:"age" binding:"gte=1,lte=130"`
-
-	// 6. 枚举校验 (Enum)
-	// oneof -> 值必须是列出的其中一个，只能是 male, female 或 secret
-	Gender string `json:"gender" binding:"required,oneof=male female secret"`
-
-	// 7. 可选字段校验 (omitempty)
-	// omitempty -> 如果前端没传这个字段（或者传空值），就不校验
-	// url -> 如果传了，就必须是合法的 URL 格式 (http://...)
-	PersonalSite string `json:"personal_site" binding:"omitempty,url"`
+// 性别，数据库里存 int8
+const (
+	GenderSecret int8 = iota // 0 保密 (默认)
+	GenderMale               // 1 男
+	GenderFemale             // 2 女
+)
+
+var genderNames = map[int8]string{
+	GenderSecret: "secret",
+	GenderMale:   "male",
+	GenderFemale: "female",
 }
 
-// RegisterParam 用户注册请求参数
-// 这里的结构体名 RegisterParam 必须首字母大写，才能被其他包（如 controller）访问
-type RegisterParam struct {
-	// binding:"required": 必填
-	// json:"username":    报错时我们希望显示 "username" 而不是 "Username"
-	Username string `json:"username" binding:"required"`
-
-	// binding:"gte=6":    长度必须大于等于 6
-	Password string `json:"password" binding:"required,gte=6"`
-
-	// binding:"email":    必须是合法的邮箱格式
-	Email string `json:"email" binding:"required,email"`
-
-	// binding:"gte=18":   数值必须大于等于 18
-	// binding:"lte=130":  数值必须小于等于 130
-	Age uint8 `json:"age" binding:"gte=18,lte=130"`
+// GenderName 把数据库里的性别转成接口里用的字符串
+func GenderName(g int8) string {
+	if name, ok := genderNames[g]; ok {
+		return name
+	}
+	return genderNames[GenderSecret]
+}
 
-	// 演示非必填字段
-	Address string `json:"address"`
+// ParseGender 把接口里的性别字符串转成数据库里的值 (参数已经过 oneof 校验，未知值按保密处理)
+func ParseGender(name string) int8 {
+	for g, n := range genderNames {
+		if n == name {
+			return g
+		}
+	}
+	return GenderSecret
 }
+
 type User struct {
 	// gorm:"column:user_id" 告诉 GORM 这个字段对应数据库的 user_id 列
 	// primaryKey 告诉 GORM 这是主键
-	ID         int64     `gorm:"column:id;primaryKey;autoIncrement"`
-	UserID     int64     `gorm:"column:user_id;not null"`
-	Username   string    `gorm:"column:username"`
-	Password   string    `gorm:"column:password"`
-	Email      string    `gorm:"column:email"`
-	Gender     int8      `gorm:"column:gender"`
-	CreateTime time.Time `gorm:"column:create_time;autoCreateTime"` // 创建时自动填时间
-	UpdateTime time.Time `gorm:"column:update_time;autoUpdateTime"` // 更新时自动更新时间
+	ID       int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID   int64  `gorm:"column:user_id;not null"`
+	Username string `gorm:"column:username"`
+	Password string `gorm:"column:password"`
+	Email    string `gorm:"column:email"`
+	// EmailVerified 是否点过验证邮件里的链接
+	EmailVerified bool      `gorm:"column:email_verified"`
+	Gender        int8      `gorm:"column:gender"`
+	Avatar        string    `gorm:"column:avatar"`                     // 头像在存储里的位置 (File.Key)，空表示没有头像
+	CreateTime    time.Time `gorm:"column:create_time;autoCreateTime"` // 创建时自动填时间
+	UpdateTime    time.Time `gorm:"column:update_time;autoUpdateTime"` // 更新时自动更新时间
 }
 
 // 必须实现 TableName 方法，告诉 GORM 这张表叫 "user"
@@ -78,9 +57,21 @@ func (User) TableName() string {
 
 // ParamSignUp 注册参数 (前端传来的)
 type ParamSignUp struct {
-	Username   string `json:"username" binding:"required"`
-	Password   string `json:"password" binding:"required"`
+	// 1. 基础校验：必填，3 到 32 位，只能包含字母、数字、下划线、点和短横线 (username 是自定义规则)
+	// json:"username" -> 报错时显示 "username"
+	Username string `json:"username" binding:"required,min=3,max=32,username"`
+
+	// 2. 长度校验：min=6,max=72 -> 长度限制在 6 到 72 之间 (bcrypt 最多只取前 72 个字节，再长也没有意义)
+	Password string `json:"password" binding:"required,min=6,max=72"`
+
+	// 3. 跨字段校验：eqfield=Password -> 必须和 Password 完全一样 (确认密码)
 	RePassword string `json:"re_password" binding:"required,eqfield=Password"`
+
+	// 4. 格式校验：必须是合法的邮箱 (xxx@xxx.com)
+	Email string `json:"email" binding:"required,email"`
+
+	// 5. 可选的枚举校验：omitempty -> 没传就不校验；传了就只能是 male, female 或 secret
+	Gender string `json:"gender" binding:"omitempty,oneof=male female secret"`
 }
 
 // ParamLogin 登录参数
@@ -88,3 +79,32 @@ type ParamLogin struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+// ResProfile 个人资料
+type ResProfile struct {
+	UserID        int64     `json:"user_id,string"` // 雪花 ID 超过 JS 的安全整数范围，转成字符串
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Gender        string    `json:"gender"`
+	AvatarURL     string    `json:"avatar_url"` // 限时有效的头像地址，没有头像为空
+	CreateTime    time.Time `json:"create_time"`
+}
+
+// ParamUpdateProfile 修改个人资料参数 (PATCH：只更新传了的字段)
+type ParamUpdateProfile struct {
+	Gender *string `json:"gender" binding:"omitempty,oneof=male female secret"`
+}
+
+// ParamChangePassword 修改密码参数 (必须提供旧密码)
+type ParamChangePassword struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	Password    string `json:"password" binding:"required,min=6,max=72,nefield=OldPassword"`
+	RePassword  string `json:"re_password" binding:"required,eqfield=Password"`
+}
+
+// ParamChangeEmail 修改邮箱参数 (必须提供密码，改完需要重新验证)
+type ParamChangeEmail struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
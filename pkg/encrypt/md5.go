@@ -3,6 +3,8 @@ package encrypt
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
+	"strings"
 )
 
 // secret 是一个盐值，随便写什么都行，保密级别越高越好
@@ -11,9 +13,54 @@ import (
 const secret = "bluebell.app.secret.key"
 
 // EncryptPassword 将明文密码加密为 MD5 字符串
+//
+// Deprecated: 只用于校验老用户的密码，新密码请使用 DefaultHasher。
+// ⚠️ h.Sum(b) 的语义是 "把摘要追加到 b 后面"，所以这里实际存下来的是
+// hex(明文密码 + md5(secret))，明文可以直接还原，而且所有用户共用同一个盐。
+// 老用户登录成功后会被自动升级成 argon2id (见 VerifyPassword 的 needsRehash)，
+// 一直不登录的老用户由 migrate up 用 WrapLegacyMD5 包一层 argon2id，库里不再留有能还原明文的值。
 func EncryptPassword(oPassword string) string {
 	h := md5.New()
 	// 把 盐 + 密码 拼接起来一起加密
 	h.Write([]byte(secret))
 	return hex.EncodeToString(h.Sum([]byte(oPassword)))
 }
+
+// legacySuffix 老格式哈希固定以 hex(md5(secret)) 结尾
+var legacySuffix = EncryptPassword("")
+
+// IsLegacyMD5 判断是不是 EncryptPassword 生成的老格式哈希
+func IsLegacyMD5(encoded string) bool {
+	if len(encoded) < len(legacySuffix) || !strings.HasSuffix(encoded, legacySuffix) {
+		return false
+	}
+	_, err := hex.DecodeString(encoded)
+	return err == nil
+}
+
+// wrappedMD5Prefix 包了一层 argon2id 的老格式哈希: $md5$argon2id$v=19$...，里面的 argon2id 哈希的"密码"是老格式的 hex 值
+const wrappedMD5Prefix = "$md5"
+
+// WrapLegacyMD5 把老格式哈希再做一次 argon2id，不需要明文，可以直接批量处理库里的数据
+// 校验时先按老算法算出 hex 值，再按 argon2id 校验 (见 VerifyPassword)
+func WrapLegacyMD5(encoded string) (string, error) {
+	if !IsLegacyMD5(encoded) {
+		return "", errors.New("not a legacy md5 password hash")
+	}
+	wrapped, err := hashers[Argon2idID].Hash(encoded)
+	if err != nil {
+		return "", err
+	}
+	return wrappedMD5Prefix + wrapped, nil
+}
+
+// verifyWrappedMD5 校验 WrapLegacyMD5 生成的哈希，matched 为 false 说明不是这种格式
+func verifyWrappedMD5(password, encoded string) (matched, ok bool, err error) {
+	inner, found := strings.CutPrefix(encoded, wrappedMD5Prefix)
+	h := hashers[Argon2idID]
+	if !found || !h.Match(inner) {
+		return false, false, nil
+	}
+	ok, err = h.Verify(EncryptPassword(password), inner)
+	return true, ok, err
+}
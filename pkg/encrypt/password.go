@@ -0,0 +1,219 @@
+package encrypt
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnknownHashFormat 数据库里存的密码哈希格式无法识别
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Hasher 密码哈希算法接口
+// 想换算法 (比如 scrypt) 只需要实现这个接口并调用 RegisterHasher 注册
+type Hasher interface {
+	// ID 算法名称，对应配置项 auth.password_hasher
+	ID() string
+	// Hash 生成带随机盐的编码哈希 (PHC 格式，算法参数和盐都编码在字符串里)
+	Hash(password string) (string, error)
+	// Match 判断某个编码哈希是不是本算法生成的
+	Match(encoded string) bool
+	// Verify 校验明文密码和编码哈希是否匹配
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash 编码哈希使用的参数和当前参数不一致 (比如调高了 cost)，需要重新生成
+	NeedsRehash(encoded string) bool
+}
+
+// hashers 已注册的算法
+var hashers = map[string]Hasher{}
+
+func init() {
+	RegisterHasher(NewArgon2idHasher())
+	RegisterHasher(NewBcryptHasher(bcrypt.DefaultCost))
+}
+
+// RegisterHasher 注册一个密码哈希算法，同名会覆盖 (方便调整参数)
+func RegisterHasher(h Hasher) {
+	hashers[h.ID()] = h
+}
+
+// DefaultHasher 用于生成新哈希的算法 (注册、改密码时用)
+// name 对应配置项 auth.password_hasher，没配或者配错了都使用 argon2id
+func DefaultHasher(name string) Hasher {
+	if h, ok := hashers[name]; ok {
+		return h
+	}
+	return hashers[Argon2idID]
+}
+
+// VerifyPassword 校验密码 (登录时用)
+// 根据编码哈希的格式自动选择算法，兼容老版本的 MD5 密码
+// needsRehash 为 true 时说明哈希已经过时 (老的 MD5、不是 def 算法、参数变了)，
+// 调用方应该在校验通过后用 def.Hash 重新生成并保存
+func VerifyPassword(def Hasher, password, encoded string) (ok, needsRehash bool, err error) {
+	for _, h := range hashers {
+		if !h.Match(encoded) {
+			continue
+		}
+		ok, err = h.Verify(password, encoded)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, h.ID() != def.ID() || h.NeedsRehash(encoded), nil
+	}
+
+	// 老用户：包了一层 argon2id 的 MD5 格式，校验通过后升级成直接用明文生成的哈希
+	if matched, ok, err := verifyWrappedMD5(password, encoded); matched {
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, true, nil
+	}
+
+	// 老用户：MD5 格式
+	if IsLegacyMD5(encoded) {
+		ok = subtle.ConstantTimeCompare([]byte(EncryptPassword(password)), []byte(encoded)) == 1
+		return ok, ok, nil
+	}
+	return false, false, ErrUnknownHashFormat
+}
+
+// =================================================================
+// argon2id
+// =================================================================
+
+// Argon2idID argon2id 算法名称
+const Argon2idID = "argon2id"
+
+// Argon2idHasher argon2id 实现
+// 编码格式: $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+type Argon2idHasher struct {
+	Memory      uint32 // 内存开销 (KiB)
+	Iterations  uint32 // 迭代次数
+	Parallelism uint8  // 并行度
+	SaltLength  uint32 // 盐长度 (字节)
+	KeyLength   uint32 // 输出长度 (字节)
+}
+
+// NewArgon2idHasher 使用 OWASP 推荐的参数 (m=19MiB, t=2, p=1)
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{
+		Memory:      19 * 1024,
+		Iterations:  2,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func (h *Argon2idHasher) ID() string { return Argon2idID }
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Match(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return p.Memory != h.Memory || p.Iterations != h.Iterations || p.Parallelism != h.Parallelism ||
+		uint32(len(salt)) != h.SaltLength || uint32(len(key)) != h.KeyLength
+}
+
+// decodeArgon2id 解析 PHC 格式的 argon2id 哈希
+func decodeArgon2id(encoded string) (p *Argon2idHasher, salt, key []byte, err error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != Argon2idID {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, nil, nil, err
+	}
+	if version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+	p = new(Argon2idHasher)
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return nil, nil, nil, err
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, nil, nil, err
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, nil, nil, err
+	}
+	return p, salt, key, nil
+}
+
+// =================================================================
+// bcrypt
+// =================================================================
+
+// BcryptID bcrypt 算法名称
+const BcryptID = "bcrypt"
+
+// BcryptHasher bcrypt 实现，编码格式就是 bcrypt 自带的 $2a$10$<salt+hash>
+// ⚠️ bcrypt 只取密码的前 72 个字节
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher 创建 bcrypt 实现，cost 越大越慢 (默认 10)
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{Cost: cost}
+}
+
+func (h *BcryptHasher) ID() string { return BcryptID }
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(b), err
+}
+
+func (h *BcryptHasher) Match(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *BcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.Cost
+}
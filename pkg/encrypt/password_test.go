@@ -0,0 +1,94 @@
+package encrypt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHashAndVerify 两种算法都要能生成、校验，并且默认算法生成的哈希不需要再升级
+func TestHashAndVerify(t *testing.T) {
+	for _, algo := range []string{Argon2idID, BcryptID} {
+		t.Run(algo, func(t *testing.T) {
+			def := DefaultHasher(algo)
+
+			encoded, err := def.Hash("123456")
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(encoded, "$"), "应该是 PHC / modular crypt 格式")
+
+			ok, needsRehash, err := VerifyPassword(def, "123456", encoded)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.False(t, needsRehash)
+
+			ok, _, err = VerifyPassword(def, "654321", encoded)
+			assert.NoError(t, err)
+			assert.False(t, ok, "错误的密码不能通过")
+		})
+	}
+}
+
+// TestSaltPerHash 同一个密码两次哈希结果必须不同 (每次随机盐)
+func TestSaltPerHash(t *testing.T) {
+	def := DefaultHasher(Argon2idID)
+	a, _ := def.Hash("123456")
+	b, _ := def.Hash("123456")
+	assert.NotEqual(t, a, b)
+}
+
+// TestLegacyMD5 老的 MD5 密码能校验通过，并且要求升级
+func TestLegacyMD5(t *testing.T) {
+	def := DefaultHasher("")
+	legacy := EncryptPassword("123456")
+
+	ok, needsRehash, err := VerifyPassword(def, "123456", legacy)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+
+	ok, needsRehash, err = VerifyPassword(def, "wrong", legacy)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, needsRehash)
+}
+
+// TestWrappedLegacyMD5 老的 MD5 值包一层 argon2id 后，原来的密码照样能登录，并且要求升级
+func TestWrappedLegacyMD5(t *testing.T) {
+	def := DefaultHasher("")
+	legacy := EncryptPassword("123456")
+	wrapped, err := WrapLegacyMD5(legacy)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(wrapped, "$md5$argon2id$"))
+	assert.NotContains(t, wrapped, legacy[:12], "库里不能再留有能还原明文的值")
+
+	ok, needsRehash, err := VerifyPassword(def, "123456", wrapped)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+
+	ok, needsRehash, err = VerifyPassword(def, "wrong", wrapped)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, needsRehash)
+
+	_, err = WrapLegacyMD5(wrapped)
+	assert.Error(t, err, "已经包过的不能再包")
+}
+
+// TestNeedsRehashOnAlgorithmChange 切换默认算法后，老算法的哈希要求升级
+func TestNeedsRehashOnAlgorithmChange(t *testing.T) {
+	encoded, err := DefaultHasher(BcryptID).Hash("123456")
+	assert.NoError(t, err)
+
+	ok, needsRehash, err := VerifyPassword(DefaultHasher(Argon2idID), "123456", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+}
+
+// TestUnknownFormat 无法识别的哈希直接报错
+func TestUnknownFormat(t *testing.T) {
+	_, _, err := VerifyPassword(DefaultHasher(""), "123456", "not-a-hash")
+	assert.ErrorIs(t, err, ErrUnknownHashFormat)
+}
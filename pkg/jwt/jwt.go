@@ -1,55 +1,232 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/spf13/viper"
 )
 
+// Token 类型，写在 claims 的 token_type 字段里
+// 用来防止把 Refresh Token 当作 Access Token 去调用业务接口 (反之亦然)
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeMFA 密码校验通过、但还没输入两步验证码时发的临时 Token，只能用来换正式 Token
+	TokenTypeMFA = "mfa_pending"
+	// TokenTypeVerifyEmail / TokenTypeResetPassword 放在邮件链接里的一次性 Token
+	TokenTypeVerifyEmail   = "verify_email"
+	TokenTypeResetPassword = "reset_password"
+	// TokenTypeAPIKey 通过 API Key 鉴权的请求，claims 由 API Key 查出来，不会签成 JWT
+	TokenTypeAPIKey = "api_key"
+)
+
+// ErrInvalidTokenType Token 类型不匹配 (比如拿 Refresh Token 去访问私有接口)
+var ErrInvalidTokenType = errors.New("invalid token type")
+
 // MyClaims 自定义声明结构体并内嵌 jwt.RegisteredClaims
 // 对应图片里的 CustomClaims
 // 我们需要把 UserID 和 Username 存到 Token 里面，这样后端就不需要查数据库也能知道是谁
 type MyClaims struct {
 	UserID   int64  `json:"user_id"`
 	Username string `json:"username"`
+	// TokenType 区分 access / refresh，老版本签发的 Token 没有这个字段，按 access 处理
+	TokenType string `json:"token_type,omitempty"`
+	// FamilyID 同一次登录签发 / 轮换出来的所有 Token 属于同一个 family
+	// Refresh Token 必须有；Access Token 带上它，注销时才能顺手吊销对应的 Refresh Token
+	FamilyID string `json:"fid,omitempty"`
+	// Generation 签发时该用户的 Token 代数，"退出所有设备" 会让代数 +1，旧代数的 Token 全部失效
+	Generation int64 `json:"gen,omitempty"`
+	// Roles 签发时用户拥有的角色，鉴权时不用每次查库；角色变更在下次刷新 Token 时生效
+	Roles []string `json:"roles,omitempty"`
+	// Scopes 只有 API Key 有：权限必须同时被角色和 Scopes 覆盖
+	Scopes []string `json:"scopes,omitempty"`
+	// Email 邮件链接 Token 才有，校验时要求和用户当前的邮箱一致，改过邮箱后旧链接自动失效
+	Email string `json:"email,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenToken 生成 JWT
+// AccessExpire Access Token 的有效期
+// 优先读取 auth.access_expire (分钟)，没配的话兼容老配置 auth.jwt_expire (小时)
+func (m *Manager) AccessExpire() time.Duration {
+	if m := m.cfg.GetInt("auth.access_expire"); m > 0 {
+		return time.Duration(m) * time.Minute
+	}
+	return time.Duration(m.cfg.GetInt("auth.jwt_expire")) * time.Hour
+}
+
+// RefreshExpire Refresh Token 的有效期 (auth.refresh_expire，单位小时)
+func (m *Manager) RefreshExpire() time.Duration {
+	return time.Duration(m.cfg.GetInt("auth.refresh_expire")) * time.Hour
+}
+
+// MFAExpire 两步验证临时 Token 的有效期 (auth.mfa_pending_expire，单位分钟，默认 5 分钟)
+func (m *Manager) MFAExpire() time.Duration {
+	if m := m.cfg.GetInt("auth.mfa_pending_expire"); m > 0 {
+		return time.Duration(m) * time.Minute
+	}
+	return 5 * time.Minute
+}
+
+// GenToken 生成 JWT (Access Token)
 // 对应图片里的 GenToken 函数
-func GenToken(userID int64, username string) (string, error) {
-	// 创建一个我们自己的声明
-	c := MyClaims{
+func (m *Manager) GenToken(userID int64, username string) (string, error) {
+	return m.GenAccessToken(MyClaims{
 		UserID:   userID,
 		Username: username,
+	})
+}
+
+// GenAccessToken 根据调用方填好的用户信息 (UserID、Username、FamilyID、Generation) 签发 Access Token
+// 类型、jti、过期时间、签发人由这里统一补齐
+func (m *Manager) GenAccessToken(c MyClaims) (string, error) {
+	jti, err := newJTI()
+	if err != nil {
+		return "", err
+	}
+	c.TokenType = TokenTypeAccess
+	c.RegisteredClaims = jwt.RegisteredClaims{
+		// jti: 每张 Token 的唯一编号，注销时按它加入黑名单
+		ID: jti,
+		// 过期时间：从配置文件读取 (短有效期，过期后用 Refresh Token 换新的)
+		// 相比图片里的硬编码 time.Hour * 24，这样更灵活
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.AccessExpire())),
+		// 签发人
+		Issuer: "gin-api-scaffold",
+	}
+	return m.sign(c)
+}
+
+// GenRefreshToken 生成 Refresh Token
+// c.FamilyID: 登录时新建，之后每次轮换都沿用同一个
+// 返回值里的 jti 是这张 Refresh Token 的唯一编号，调用方需要把它记到 Redis 里做轮换校验
+func (m *Manager) GenRefreshToken(c MyClaims) (token, jti string, err error) {
+	jti, err = newJTI()
+	if err != nil {
+		return "", "", err
+	}
+	c.TokenType = TokenTypeRefresh
+	c.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        jti,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.RefreshExpire())),
+		Issuer:    "gin-api-scaffold",
+	}
+	token, err = m.sign(c)
+	return token, jti, err
+}
+
+// GenMFAToken 生成两步验证临时 Token
+func (m *Manager) GenMFAToken(userID int64, username string) (string, error) {
+	jti, err := newJTI()
+	if err != nil {
+		return "", err
+	}
+	return m.sign(MyClaims{
+		UserID:    userID,
+		Username:  username,
+		TokenType: TokenTypeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.MFAExpire())),
+			Issuer:    "gin-api-scaffold",
+		},
+	})
+}
+
+// GenEmailToken 生成邮件链接里的一次性 Token (邮箱验证 / 重置密码)
+// 返回的 jti 需要调用方存到 Redis，使用时核销，保证链接只能点一次
+func (m *Manager) GenEmailToken(tokenType string, userID int64, email string, expire time.Duration) (token, jti string, err error) {
+	jti, err = newJTI()
+	if err != nil {
+		return "", "", err
+	}
+	token, err = m.sign(MyClaims{
+		UserID:    userID,
+		TokenType: tokenType,
+		Email:     email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			// 过期时间：从配置文件读取 (比如 24 小时)
-			// 相比图片里的硬编码 time.Hour * 24，这样更灵活
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(viper.GetInt("auth.jwt_expire")) * time.Hour)),
-			// 签发人
-			Issuer: "gin-api-scaffold",
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expire)),
+			Issuer:    "gin-api-scaffold",
 		},
+	})
+	return token, jti, err
+}
+
+// ParseToken 解析 JWT (Access Token)
+// 对应图片里的 ParseToken 函数
+func (m *Manager) ParseToken(tokenString string) (*MyClaims, error) {
+	mc, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	// Refresh Token 不能直接拿来访问业务接口
+	if mc.TokenType != "" && mc.TokenType != TokenTypeAccess {
+		return nil, ErrInvalidTokenType
 	}
-	// 使用指定的签名方法创建签名对象 (HS256)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
+	return mc, nil
+}
 
-	// 使用指定的 Secret 签名并获得完整的编码后的字符串 Token
+// ParseRefreshToken 解析 Refresh Token
+func (m *Manager) ParseRefreshToken(tokenString string) (*MyClaims, error) {
+	mc, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if mc.TokenType != TokenTypeRefresh || mc.FamilyID == "" || mc.ID == "" {
+		return nil, ErrInvalidTokenType
+	}
+	return mc, nil
+}
+
+// ParseMFAToken 解析两步验证临时 Token
+func (m *Manager) ParseMFAToken(tokenString string) (*MyClaims, error) {
+	mc, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if mc.TokenType != TokenTypeMFA || mc.ID == "" {
+		return nil, ErrInvalidTokenType
+	}
+	return mc, nil
+}
+
+// ParseEmailToken 解析邮件链接 Token，tokenType 必须和签发时一致
+func (m *Manager) ParseEmailToken(tokenString, tokenType string) (*MyClaims, error) {
+	mc, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if mc.TokenType != tokenType || mc.ID == "" {
+		return nil, ErrInvalidTokenType
+	}
+	return mc, nil
+}
+
+// sign 使用当前的签名密钥创建签名对象并签名
+// 配置了 auth.jwt_keys 时用 active 密钥 (RS256 / EdDSA / HS256)，并在头部写上 kid，
+// 否则沿用老的 HS256 + auth.jwt_secret
+func (m *Manager) sign(c MyClaims) (string, error) {
+	k := m.signingKey()
+	token := jwt.NewWithClaims(k.Method, c)
+	if k.KID != "" {
+		token.Header["kid"] = k.KID
+	}
+
+	// 使用指定的密钥签名并获得完整的编码后的字符串 Token
 	// ⚡️ 注意：这里千万不要像图片里那样写死 []byte("夏天夏天...")
 	// 而是从配置文件读取，保证安全
-	return token.SignedString([]byte(viper.GetString("auth.jwt_secret")))
+	return token.SignedString(k.signKey)
 }
 
-// ParseToken 解析 JWT
-// 对应图片里的 ParseToken 函数
-func ParseToken(tokenString string) (*MyClaims, error) {
+// parse 校验签名和过期时间，返回解析出来的声明
+func (m *Manager) parse(tokenString string) (*MyClaims, error) {
 	// 解析 token
+	// keyFunc 会根据头部的 kid 挑选密钥，并检查算法是否和密钥匹配
 	var mc = new(MyClaims)
-	token, err := jwt.ParseWithClaims(tokenString, mc, func(token *jwt.Token) (interface{}, error) {
-		// 返回签名用的密钥
-		return []byte(viper.GetString("auth.jwt_secret")), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, mc, m.keyFunc)
 	if err != nil {
 		return nil, err
 	}
@@ -59,3 +236,12 @@ func ParseToken(tokenString string) (*MyClaims, error) {
 	}
 	return nil, errors.New("invalid token")
 }
+
+// newJTI 生成一个随机的 Token 编号 (128 bit)
+func newJTI() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
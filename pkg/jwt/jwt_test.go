@@ -2,20 +2,40 @@ package jwt
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
 
+// testConfig 只配置了 HS256 密钥的测试配置
+func testConfig() *viper.Viper {
+	cfg := viper.New()
+	cfg.Set("auth.jwt_secret", "my_test_secret_key")
+	cfg.Set("auth.access_expire", 15)
+	cfg.Set("auth.refresh_expire", 24)
+	return cfg
+}
+
+func newManager(t *testing.T, cfg *viper.Viper) *Manager {
+	m, err := NewManager(cfg)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	return m
+}
+
 // TestJWT 是一个综合测试，涵盖了生成和解析
 func TestJWT(t *testing.T) {
 	// =========================================================================
 	// 1. Mock 配置 (关键步骤！)
 	// =========================================================================
-	// 因为我们的 GenToken 和 ParseToken 依赖 viper 读取配置
+	// 因为我们的 GenToken 和 ParseToken 依赖配置
 	// 但单元测试不会去读 config.yaml，所以我们要手动设置假的配置项
-	viper.Set("auth.jwt_secret", "my_test_secret_key") // 设置假的密钥
-	viper.Set("auth.jwt_expire", 1)                    // 设置过期时间为 1 小时
+	cfg := viper.New()
+	cfg.Set("auth.jwt_secret", "my_test_secret_key") // 设置假的密钥
+	cfg.Set("auth.jwt_expire", 1)                    // 设置过期时间为 1 小时
+	m := newManager(t, cfg)
 
 	// =========================================================================
 	// 2. 定义测试数据
@@ -26,7 +46,7 @@ func TestJWT(t *testing.T) {
 	// =========================================================================
 	// 3. 测试生成 Token (GenToken)
 	// =========================================================================
-	token, err := GenToken(userID, username)
+	token, err := m.GenToken(userID, username)
 
 	// 断言：生成过程不应该报错
 	if err != nil {
@@ -38,7 +58,7 @@ func TestJWT(t *testing.T) {
 	// =========================================================================
 	// 4. 测试解析 Token (ParseToken)
 	// =========================================================================
-	claims, err := ParseToken(token)
+	claims, err := m.ParseToken(token)
 
 	// 断言：解析过程不应该报错
 	if err != nil {
@@ -60,14 +80,109 @@ func TestJWT(t *testing.T) {
 
 // TestParseInvalidToken 测试解析错误的 Token
 func TestParseInvalidToken(t *testing.T) {
-	viper.Set("auth.jwt_secret", "my_test_secret_key")
+	m := newManager(t, testConfig())
 
 	// 随便瞎写一个 Token
 	invalidToken := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.invalid.signature"
 
-	claims, err := ParseToken(invalidToken)
+	claims, err := m.ParseToken(invalidToken)
 
 	// 断言：这里必须报错
 	assert.Error(t, err, "解析错误的 Token 应该返回错误")
 	assert.Nil(t, claims, "解析失败 Claims 应该是 nil")
 }
+
+// TestRefreshToken 测试 Refresh Token 的生成与解析，以及两种 Token 不能混用
+func TestRefreshToken(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("auth.jwt_secret", "my_test_secret_key")
+	cfg.Set("auth.access_expire", 15)
+	cfg.Set("auth.refresh_expire", 24)
+	m := newManager(t, cfg)
+
+	refreshToken, jti, err := m.GenRefreshToken(MyClaims{UserID: 10086, Username: "qimi_test", FamilyID: "family-1"})
+	if err != nil {
+		t.Fatalf("GenRefreshToken failed: %v", err)
+	}
+	assert.NotEmpty(t, jti)
+
+	// 1. 正常解析 Refresh Token
+	claims, err := m.ParseRefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("ParseRefreshToken failed: %v", err)
+	}
+	assert.Equal(t, int64(10086), claims.UserID)
+	assert.Equal(t, "family-1", claims.FamilyID)
+	assert.Equal(t, jti, claims.ID)
+
+	// 2. Refresh Token 不能当 Access Token 用
+	_, err = m.ParseToken(refreshToken)
+	assert.ErrorIs(t, err, ErrInvalidTokenType)
+
+	// 3. Access Token 也不能拿去刷新
+	accessToken, err := m.GenToken(10086, "qimi_test")
+	if err != nil {
+		t.Fatalf("GenToken failed: %v", err)
+	}
+	_, err = m.ParseRefreshToken(accessToken)
+	assert.ErrorIs(t, err, ErrInvalidTokenType)
+
+	// 4. 配置了 access_expire 时以分钟为单位
+	assert.Equal(t, 15*time.Minute, m.AccessExpire())
+}
+
+// TestAccessTokenJTI 每张 Access Token 都要有唯一的 jti，并带上 family 和代数
+func TestAccessTokenJTI(t *testing.T) {
+	m := newManager(t, testConfig())
+
+	t1, err := m.GenAccessToken(MyClaims{UserID: 1, Username: "a", FamilyID: "f1", Generation: 3})
+	assert.NoError(t, err)
+	t2, err := m.GenAccessToken(MyClaims{UserID: 1, Username: "a", FamilyID: "f1", Generation: 3})
+	assert.NoError(t, err)
+
+	c1, err := m.ParseToken(t1)
+	assert.NoError(t, err)
+	c2, err := m.ParseToken(t2)
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, c1.ID)
+	assert.NotEqual(t, c1.ID, c2.ID, "jti 不能重复")
+	assert.Equal(t, "f1", c1.FamilyID)
+	assert.Equal(t, int64(3), c1.Generation)
+}
+
+// TestMFAToken 两步验证临时 Token 不能当 Access Token 用
+func TestMFAToken(t *testing.T) {
+	m := newManager(t, testConfig())
+
+	token, err := m.GenMFAToken(10086, "qimi_test")
+	assert.NoError(t, err)
+
+	claims, err := m.ParseMFAToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10086), claims.UserID)
+	assert.NotEmpty(t, claims.ID)
+
+	_, err = m.ParseToken(token)
+	assert.ErrorIs(t, err, ErrInvalidTokenType)
+	_, err = m.ParseRefreshToken(token)
+	assert.ErrorIs(t, err, ErrInvalidTokenType)
+}
+
+// TestEmailToken 邮件链接 Token 不能混用：验证邮箱的链接不能拿去重置密码
+func TestEmailToken(t *testing.T) {
+	m := newManager(t, testConfig())
+
+	token, jti, err := m.GenEmailToken(TokenTypeVerifyEmail, 10086, "qimi@example.com", time.Hour)
+	assert.NoError(t, err)
+
+	claims, err := m.ParseEmailToken(token, TokenTypeVerifyEmail)
+	assert.NoError(t, err)
+	assert.Equal(t, jti, claims.ID)
+	assert.Equal(t, "qimi@example.com", claims.Email)
+
+	_, err = m.ParseEmailToken(token, TokenTypeResetPassword)
+	assert.ErrorIs(t, err, ErrInvalidTokenType)
+	_, err = m.ParseToken(token)
+	assert.ErrorIs(t, err, ErrInvalidTokenType)
+}
@@ -0,0 +1,318 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+)
+
+var (
+	// ErrUnknownKey Token 头里的 kid 找不到对应的密钥 (或者已经过了保留期)
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrAlgMismatch Token 声明的算法和密钥的算法不一致 (防止 alg 混淆攻击)
+	ErrAlgMismatch = errors.New("signing algorithm mismatch")
+)
+
+// KeyConfig 单个签名密钥的配置，对应 config.yaml 里 auth.jwt_keys 的一项
+type KeyConfig struct {
+	KID string `mapstructure:"kid"`
+	// Alg 签名算法：HS256 / RS256 / EdDSA
+	Alg string `mapstructure:"alg"`
+	// Secret 只有 HS256 使用
+	Secret string `mapstructure:"secret"`
+	// PrivateKeyFile RS256 / EdDSA 的私钥 (PEM，PKCS#8 或 PKCS#1)
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	// PublicKeyFile 已经退役的密钥可以只保留公钥，只用来验签
+	PublicKeyFile string `mapstructure:"public_key_file"`
+	// RetiredAt 退役时间 (RFC3339)，退役后的密钥不再签名，
+	// 再保留一个 Refresh Token 有效期用来验签，之后自动失效
+	RetiredAt string `mapstructure:"retired_at"`
+}
+
+// Key 加载好的密钥
+type Key struct {
+	KID       string
+	Method    jwt.SigningMethod
+	signKey   interface{} // []byte / *rsa.PrivateKey / ed25519.PrivateKey，只保留公钥时为 nil
+	verifyKey interface{} // []byte / *rsa.PublicKey / ed25519.PublicKey
+	RetiredAt time.Time
+}
+
+// usable 密钥还能不能用来验签，retention 是退役后的保留期 (一个 Refresh Token 有效期)
+func (k *Key) usable(now time.Time, retention time.Duration) bool {
+	return k.RetiredAt.IsZero() || now.Before(k.RetiredAt.Add(retention))
+}
+
+// KeySet 当前的全部密钥
+type KeySet struct {
+	Active *Key
+	Keys   map[string]*Key
+	// Legacy 启用 KeySet 之前的 HS256 + auth.jwt_secret，用来验证没有 kid 的老 Token
+	// 和其他退役密钥一样在 auth.jwt_secret_retired_at + refresh_expire 之后失效；没配退役时间时为 nil，老 Token 一律不认
+	Legacy *Key
+}
+
+// Manager 负责签发和校验 Token，持有配置和当前的签名密钥
+type Manager struct {
+	cfg *viper.Viper
+	// keySet 由 LoadKeys 加载，原子替换，支持配置热加载后重新调用 LoadKeys
+	keySet atomic.Pointer[KeySet]
+}
+
+// NewManager 创建 Manager 并加载签名密钥
+func NewManager(cfg *viper.Viper) (*Manager, error) {
+	m := &Manager{cfg: cfg}
+	if err := m.LoadKeys(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadKeys 读取 auth.jwt_keys 和 auth.jwt_active_kid 加载签名密钥
+// 没有配置 auth.jwt_keys 时不做任何事，继续使用老的 HS256 + auth.jwt_secret
+func (m *Manager) LoadKeys() error {
+	var cfgs []KeyConfig
+	if err := m.cfg.UnmarshalKey("auth.jwt_keys", &cfgs); err != nil {
+		return err
+	}
+	if len(cfgs) == 0 {
+		m.keySet.Store(nil)
+		return nil
+	}
+
+	ks := &KeySet{Keys: make(map[string]*Key, len(cfgs))}
+	for _, cfg := range cfgs {
+		k, err := loadKey(cfg)
+		if err != nil {
+			return fmt.Errorf("load jwt key %q failed: %w", cfg.KID, err)
+		}
+		if _, dup := ks.Keys[k.KID]; dup {
+			return fmt.Errorf("duplicate jwt key id %q", k.KID)
+		}
+		ks.Keys[k.KID] = k
+	}
+
+	activeKID := m.cfg.GetString("auth.jwt_active_kid")
+	active, ok := ks.Keys[activeKID]
+	if !ok {
+		return fmt.Errorf("active jwt key %q not found in auth.jwt_keys", activeKID)
+	}
+	if active.signKey == nil || !active.RetiredAt.IsZero() {
+		return fmt.Errorf("active jwt key %q must have a private key and must not be retired", activeKID)
+	}
+	ks.Active = active
+
+	if retiredAt := m.cfg.GetString("auth.jwt_secret_retired_at"); retiredAt != "" && m.cfg.GetString("auth.jwt_secret") != "" {
+		t, err := time.Parse(time.RFC3339, retiredAt)
+		if err != nil {
+			return fmt.Errorf("invalid auth.jwt_secret_retired_at: %w", err)
+		}
+		ks.Legacy = m.legacyKey()
+		ks.Legacy.RetiredAt = t
+	}
+
+	m.keySet.Store(ks)
+	return nil
+}
+
+// signingKey 返回当前用于签名的密钥
+// 没加载 KeySet 时退化成老的 HS256 + auth.jwt_secret (不带 kid)
+func (m *Manager) signingKey() *Key {
+	if ks := m.keySet.Load(); ks != nil {
+		return ks.Active
+	}
+	return m.legacyKey()
+}
+
+// keyFunc 验签时根据 Token 头里的 kid 选择密钥
+func (m *Manager) keyFunc(token *jwt.Token) (interface{}, error) {
+	k, err := m.lookupKey(token)
+	if err != nil {
+		return nil, err
+	}
+	// ⚡️ 必须校验算法：否则攻击者可以把 alg 改成 HS256，拿公开的 RSA 公钥当 HMAC 密钥伪造 Token
+	if token.Method.Alg() != k.Method.Alg() {
+		return nil, ErrAlgMismatch
+	}
+	return k.verifyKey, nil
+}
+
+func (m *Manager) lookupKey(token *jwt.Token) (*Key, error) {
+	ks := m.keySet.Load()
+	kid, _ := token.Header["kid"].(string)
+
+	if ks == nil {
+		return m.legacyKey(), nil
+	}
+	k, ok := ks.Keys[kid]
+	if kid == "" {
+		// 启用 KeySet 之前签发的老 Token 没有 kid，按 jwt_secret 的退役时间决定还认不认
+		k, ok = ks.Legacy, ks.Legacy != nil
+	}
+	if !ok || !k.usable(time.Now(), m.RefreshExpire()) {
+		return nil, ErrUnknownKey
+	}
+	return k, nil
+}
+
+// legacyKey 老的 HS256 密钥，每次都从配置读取，改了配置立即生效
+func (m *Manager) legacyKey() *Key {
+	secret := []byte(m.cfg.GetString("auth.jwt_secret"))
+	return &Key{Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+}
+
+// loadKey 根据配置加载一个密钥
+func loadKey(cfg KeyConfig) (*Key, error) {
+	if cfg.KID == "" {
+		return nil, errors.New("kid is required")
+	}
+	k := &Key{KID: cfg.KID}
+	if cfg.RetiredAt != "" {
+		t, err := time.Parse(time.RFC3339, cfg.RetiredAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retired_at: %w", err)
+		}
+		k.RetiredAt = t
+	}
+
+	switch cfg.Alg {
+	case "HS256":
+		if cfg.Secret == "" {
+			return nil, errors.New("secret is required for HS256")
+		}
+		k.Method = jwt.SigningMethodHS256
+		k.signKey, k.verifyKey = []byte(cfg.Secret), []byte(cfg.Secret)
+		return k, nil
+	case "RS256":
+		k.Method = jwt.SigningMethodRS256
+	case "EdDSA":
+		k.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported alg %q", cfg.Alg)
+	}
+
+	// 非对称密钥：优先加载私钥 (公钥从私钥推出)，没有私钥再加载公钥
+	switch {
+	case cfg.PrivateKeyFile != "":
+		priv, err := readPEM(cfg.PrivateKeyFile, parsePrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		switch pk := priv.(type) {
+		case *rsa.PrivateKey:
+			k.signKey, k.verifyKey = pk, &pk.PublicKey
+		case ed25519.PrivateKey:
+			k.signKey, k.verifyKey = pk, pk.Public()
+		}
+	case cfg.PublicKeyFile != "":
+		pub, err := readPEM(cfg.PublicKeyFile, x509.ParsePKIXPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		k.verifyKey = pub
+	default:
+		return nil, errors.New("private_key_file or public_key_file is required")
+	}
+
+	// 文件里的密钥类型必须和 alg 对得上
+	switch k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		if k.Method != jwt.SigningMethodRS256 {
+			return nil, errors.New("RSA key configured with non-RS256 alg")
+		}
+	case ed25519.PublicKey:
+		if k.Method != jwt.SigningMethodEdDSA {
+			return nil, errors.New("Ed25519 key configured with non-EdDSA alg")
+		}
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+	return k, nil
+}
+
+func readPEM(filename string, parse func([]byte) (interface{}, error)) (interface{}, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", filename)
+	}
+	return parse(block.Bytes)
+}
+
+// parsePrivateKey 兼容 PKCS#8 ("PRIVATE KEY") 和 PKCS#1 ("RSA PRIVATE KEY")
+func parsePrivateKey(der []byte) (interface{}, error) {
+	if k, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return k, nil
+	}
+	return x509.ParsePKCS1PrivateKey(der)
+}
+
+// =================================================================
+// JWKS
+// =================================================================
+
+// JSONWebKey JWK 格式的公钥 (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 (OKP)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet JWKS 文档，其他服务拉取它就能独立验签
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS 导出所有还能用来验签的非对称公钥
+// HS256 是对称密钥，绝对不能公开，所以不会出现在这里
+func (m *Manager) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	ks := m.keySet.Load()
+	if ks == nil {
+		return set
+	}
+	now, retention := time.Now(), m.RefreshExpire()
+	for _, k := range ks.Keys {
+		if !k.usable(now, retention) {
+			continue
+		}
+		jwk := JSONWebKey{Kid: k.KID, Use: "sig", Alg: k.Method.Alg()}
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
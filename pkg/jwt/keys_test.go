@@ -0,0 +1,164 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeKey 把私钥以 PKCS#8 PEM 格式写到临时目录
+func writeKey(t *testing.T, name string, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), name+".pem")
+	if err = os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// setupKeys 按给定配置重新加载密钥 (模拟配置热加载)
+func setupKeys(t *testing.T, m *Manager, activeKID string, keys []map[string]interface{}) {
+	m.cfg.Set("auth.refresh_expire", 24)
+	m.cfg.Set("auth.jwt_active_kid", activeKID)
+	m.cfg.Set("auth.jwt_keys", keys)
+	if err := m.LoadKeys(); err != nil {
+		t.Fatalf("LoadKeys failed: %v", err)
+	}
+}
+
+// TestKeyRotation 测试非对称签名、kid 以及密钥轮换
+func TestKeyRotation(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaFile := writeKey(t, "rsa", rsaKey)
+	edFile := writeKey(t, "ed", edKey)
+
+	m := newManager(t, testConfig())
+
+	// 1. 老的 HS256 Token (没有 kid)
+	legacyToken, err := m.GenToken(1, "old")
+	assert.NoError(t, err)
+
+	// 2. 启用 RS256，jwt_secret 同时退役
+	m.cfg.Set("auth.jwt_secret_retired_at", time.Now().Format(time.RFC3339))
+	setupKeys(t, m, "k1", []map[string]interface{}{
+		{"kid": "k1", "alg": "RS256", "private_key_file": rsaFile},
+		{"kid": "k2", "alg": "EdDSA", "private_key_file": edFile},
+	})
+	rsToken, err := m.GenToken(1, "alice")
+	assert.NoError(t, err)
+	parsed, _, _ := jwt.NewParser().ParseUnverified(rsToken, &MyClaims{})
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+	assert.Equal(t, "k1", parsed.Header["kid"])
+
+	// 老 Token 在 jwt_secret 的保留期内继续有效
+	_, err = m.ParseToken(legacyToken)
+	assert.NoError(t, err)
+
+	// 3. 轮换：k2 成为 active，k1 退役
+	setupKeys(t, m, "k2", []map[string]interface{}{
+		{"kid": "k1", "alg": "RS256", "private_key_file": rsaFile, "retired_at": time.Now().Format(time.RFC3339)},
+		{"kid": "k2", "alg": "EdDSA", "private_key_file": edFile},
+	})
+	edToken, err := m.GenToken(1, "alice")
+	assert.NoError(t, err)
+	parsed, _, _ = jwt.NewParser().ParseUnverified(edToken, &MyClaims{})
+	assert.Equal(t, "EdDSA", parsed.Method.Alg())
+
+	// 退役但还在保留期内的 k1 签发的 Token 仍然有效
+	_, err = m.ParseToken(rsToken)
+	assert.NoError(t, err)
+	_, err = m.ParseToken(edToken)
+	assert.NoError(t, err)
+
+	// 4. k1 过了保留期：不再能验签，也不再出现在 JWKS 里
+	setupKeys(t, m, "k2", []map[string]interface{}{
+		{"kid": "k1", "alg": "RS256", "private_key_file": rsaFile, "retired_at": time.Now().Add(-48 * time.Hour).Format(time.RFC3339)},
+		{"kid": "k2", "alg": "EdDSA", "private_key_file": edFile},
+	})
+	_, err = m.ParseToken(rsToken)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	set := m.JWKS()
+	if assert.Len(t, set.Keys, 1) {
+		assert.Equal(t, "k2", set.Keys[0].Kid)
+		assert.Equal(t, "OKP", set.Keys[0].Kty)
+		assert.Equal(t, "Ed25519", set.Keys[0].Crv)
+	}
+}
+
+// TestAlgConfusion 用 RSA 公钥当 HMAC 密钥伪造的 Token 必须被拒绝
+func TestAlgConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaFile := writeKey(t, "rsa", rsaKey)
+	m := newManager(t, testConfig())
+	setupKeys(t, m, "k1", []map[string]interface{}{
+		{"kid": "k1", "alg": "RS256", "private_key_file": rsaFile},
+	})
+
+	pubDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, MyClaims{UserID: 1, Username: "evil"})
+	forged.Header["kid"] = "k1"
+	forgedToken, err := forged.SignedString(pubDER)
+	assert.NoError(t, err)
+
+	_, err = m.ParseToken(forgedToken)
+	assert.ErrorIs(t, err, ErrAlgMismatch)
+
+	// JWKS 里是 RSA 公钥
+	set := m.JWKS()
+	if assert.Len(t, set.Keys, 1) {
+		assert.Equal(t, "RSA", set.Keys[0].Kty)
+		assert.Equal(t, "AQAB", set.Keys[0].E)
+	}
+}
+
+// TestNewManagerInvalid active 密钥不存在时要报错
+func TestNewManagerInvalid(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("auth.jwt_active_kid", "missing")
+	cfg.Set("auth.jwt_keys", []map[string]interface{}{
+		{"kid": "k1", "alg": "HS256", "secret": "s"},
+	})
+	_, err := NewManager(cfg)
+	assert.Error(t, err)
+}
+
+// TestLegacySecretRetirement 启用 KeySet 后，没有 kid 的老 Token 只在 jwt_secret 的保留期内有效
+func TestLegacySecretRetirement(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaFile := writeKey(t, "rsa", rsaKey)
+	keys := []map[string]interface{}{{"kid": "k1", "alg": "RS256", "private_key_file": rsaFile}}
+
+	m := newManager(t, testConfig())
+	legacyToken, err := m.GenToken(1, "old")
+	assert.NoError(t, err)
+
+	// 没配 jwt_secret_retired_at：共享密钥不能再用来伪造 Token
+	setupKeys(t, m, "k1", keys)
+	_, err = m.ParseToken(legacyToken)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// 过了保留期：同样失效
+	m.cfg.Set("auth.jwt_secret_retired_at", time.Now().Add(-48*time.Hour).Format(time.RFC3339))
+	setupKeys(t, m, "k1", keys)
+	_, err = m.ParseToken(legacyToken)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// 退役时间格式错误时加载失败
+	m.cfg.Set("auth.jwt_secret_retired_at", "yesterday")
+	assert.Error(t, m.LoadKeys())
+}
@@ -0,0 +1,176 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Message 一封邮件 (纯文本)
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 发信接口
+// 线上用 SMTPMailer，本地开发用 FileMailer (不真正发信，写文件 + 打日志)
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New 根据 mail.driver 创建发信器
+// smtp: 通过 SMTP 服务器发送；log (默认): 写到 mail.dir 目录并打印日志
+func New(cfg *viper.Viper, log *zap.Logger) (Mailer, error) {
+	from := cfg.GetString("mail.from")
+	switch cfg.GetString("mail.driver") {
+	case "smtp":
+		if _, err := mail.ParseAddress(from); err != nil {
+			return nil, fmt.Errorf("invalid mail.from %q: %w", from, err)
+		}
+		return &SMTPMailer{
+			Host:     cfg.GetString("mail.smtp.host"),
+			Port:     cfg.GetInt("mail.smtp.port"),
+			Username: cfg.GetString("mail.smtp.username"),
+			Password: cfg.GetString("mail.smtp.password"),
+			From:     from,
+		}, nil
+	case "", "log":
+		return &FileMailer{Dir: cfg.GetString("mail.dir"), From: from, Log: log}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail.driver %q", cfg.GetString("mail.driver"))
+	}
+}
+
+// =================================================================
+// SMTP
+// =================================================================
+
+// SMTPMailer 通过 SMTP 发信
+// 465 端口使用隐式 TLS，其他端口 (25 / 587) 在服务器支持时自动 STARTTLS
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return err
+	}
+
+	var c *smtp.Client
+	if m.Port == 465 {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: m.Host})
+		if err != nil {
+			return err
+		}
+		if c, err = smtp.NewClient(conn, m.Host); err != nil {
+			return err
+		}
+	} else {
+		if c, err = smtp.Dial(addr); err != nil {
+			return err
+		}
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err = c.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+				c.Close()
+				return err
+			}
+		}
+	}
+	defer c.Close()
+
+	if m.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+	if err = c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err = c.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(build(m.From, msg)); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// =================================================================
+// 本地开发
+// =================================================================
+
+// FileMailer 不真正发信：把邮件写成 .eml 文件 (Dir 为空则不写)，并打印到日志
+// 本地开发时直接去日志或者 mail.dir 里找验证链接
+type FileMailer struct {
+	Dir  string
+	From string
+	Log  *zap.Logger // 为 nil 时不打日志
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	if m.Log != nil {
+		m.Log.Info("mail (not sent)",
+			zap.String("to", msg.To),
+			zap.String("subject", msg.Subject),
+			zap.String("body", msg.Body))
+	}
+	if m.Dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), build(m.From, msg), 0o644)
+}
+
+// build 拼出 RFC 5322 格式的邮件，标题用 MIME 编码以支持中文
+func build(from string, msg Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// sanitize 收件人地址用作文件名时去掉特殊字符
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
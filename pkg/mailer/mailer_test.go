@@ -0,0 +1,27 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFileMailer 本地开发模式把邮件写成 .eml 文件
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m := &FileMailer{Dir: dir, From: "Bluebell <no-reply@example.com>"}
+
+	err := m.Send(Message{To: "alice@example.com", Subject: "验证你的邮箱", Body: "第一行\n第二行"})
+	assert.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Len(t, files, 1)
+	b, _ := os.ReadFile(files[0])
+	raw := string(b)
+	assert.Contains(t, raw, "To: alice@example.com\r\n")
+	assert.Contains(t, raw, "Subject: =?utf-8?q?")
+	assert.True(t, strings.HasSuffix(raw, "第一行\r\n第二行"))
+}
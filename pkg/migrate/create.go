@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var nameRe = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create 在 dir 目录下新建一对空的迁移文件，版本号是现有最大版本号 + 1，返回两个文件的路径
+func Create(dir, name string) (up, down string, err error) {
+	if !nameRe.MatchString(name) {
+		return "", "", errors.New("migration name must match [a-z0-9_]+")
+	}
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	var version int64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%04d_%s", version, name))
+	up, down = base+".up.sql", base+".down.sql"
+	if err = writeNew(up, "-- "+name+"\n\n"); err != nil {
+		return "", "", err
+	}
+	if err = writeNew(down, "-- 回滚 "+name+"\n\n"); err != nil {
+		return "", "", err
+	}
+	return up, down, nil
+}
+
+// writeNew 只创建新文件，不覆盖已有的
+func writeNew(path, content string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,301 @@
+// Package migrate 版本化的 SQL 迁移
+// 迁移文件命名为 <版本号>_<名字>.up.sql / <版本号>_<名字>.down.sql，版本号是递增的整数
+// 已经执行过的版本记录在 schema_migrations 表里
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// lockName 用 MySQL 的 GET_LOCK 防止多个实例同时启动时重复执行迁移
+const (
+	lockName    = "schema_migrations"
+	lockTimeout = 60 // 秒
+)
+
+// ErrDirty 上一次迁移执行到一半失败了 (MySQL 的 DDL 不能回滚)
+// 需要手动修好数据库，再用 migrate force <版本号> 标记当前版本
+var ErrDirty = errors.New("数据库处于迁移失败的中间状态，请手动修复后执行 migrate force")
+
+var fileNameRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Migration
+	Applied   bool
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+// Migrator 在 db 上执行 migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New 从 fsys 的根目录加载迁移文件
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load 读取 fsys 根目录下的迁移文件，按版本号排序
+// 每个版本必须同时有 up 和 down 两个文件，版本号不能重复
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		m := fileNameRe.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		b, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		mg := byVersion[version]
+		if mg == nil {
+			mg = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mg
+		}
+		if mg.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s, %s", version, mg.Name, m[2])
+		}
+		if m[3] == "up" {
+			mg.Up = string(b)
+		} else {
+			mg.Down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mg := range byVersion {
+		if mg.Up == "" || mg.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", mg.Version, mg.Name)
+		}
+		migrations = append(migrations, *mg)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up 按顺序执行还没执行过的迁移，n <= 0 表示全部执行，返回这次执行了哪些
+func (m *Migrator) Up(ctx context.Context, n int) (done []Migration, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mg := range m.migrations {
+			if n > 0 && len(done) >= n {
+				break
+			}
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			// 先记一条 dirty 的记录，执行成功再改成 0；中途失败就留在 dirty 状态
+			if _, err = conn.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name, dirty) VALUES (?, ?, 1)", mg.Version, mg.Name); err != nil {
+				return err
+			}
+			if err = execScript(ctx, conn, mg.Up); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", mg.Version, mg.Name, err)
+			}
+			if _, err = conn.ExecContext(ctx,
+				"UPDATE schema_migrations SET dirty = 0 WHERE version = ?", mg.Version); err != nil {
+				return err
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down 从最新的版本开始回滚 n 个迁移 (n <= 0 按 1 处理)，返回这次回滚了哪些
+func (m *Migrator) Down(ctx context.Context, n int) (done []Migration, err error) {
+	if n <= 0 {
+		n = 1
+	}
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < n; i-- {
+			mg := m.migrations[i]
+			if _, ok := applied[mg.Version]; !ok {
+				continue
+			}
+			if _, err = conn.ExecContext(ctx,
+				"UPDATE schema_migrations SET dirty = 1 WHERE version = ?", mg.Version); err != nil {
+				return err
+			}
+			if err = execScript(ctx, conn, mg.Down); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", mg.Version, mg.Name, err)
+			}
+			if _, err = conn.ExecContext(ctx,
+				"DELETE FROM schema_migrations WHERE version = ?", mg.Version); err != nil {
+				return err
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status 所有迁移的执行状态，按版本号排序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := ensureTable(ctx, m.db); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, "SELECT version, dirty, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	type record struct {
+		dirty     bool
+		appliedAt time.Time
+	}
+	records := make(map[int64]record)
+	for rows.Next() {
+		var (
+			version int64
+			r       record
+		)
+		if err = rows.Scan(&version, &r.dirty, &r.appliedAt); err != nil {
+			return nil, err
+		}
+		records[version] = r
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	list := make([]Status, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		r, ok := records[mg.Version]
+		list = append(list, Status{Migration: mg, Applied: ok, Dirty: r.dirty, AppliedAt: r.appliedAt})
+	}
+	return list, nil
+}
+
+// Force 不执行任何 SQL，直接把数据库标记为 version 版本：
+// 不大于 version 的都算已执行 (并清除 dirty)，大于 version 的都算没执行
+// 用于修复 dirty 状态，或者接管以前手动建好表的数据库
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version > ?", version); err != nil {
+			return err
+		}
+		for _, mg := range m.migrations {
+			if mg.Version > version {
+				break
+			}
+			if _, err = tx.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name, dirty) VALUES (?, ?, 0) ON DUPLICATE KEY UPDATE dirty = 0",
+				mg.Version, mg.Name); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+}
+
+// withLock 拿到迁移锁之后在同一个连接上执行 fn (GET_LOCK 是连接级别的)
+// 有 dirty 的版本时直接拒绝，Force 除外
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	if err := ensureTable(ctx, m.db); err != nil {
+		return err
+	}
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var got sql.NullInt64
+	if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&got); err != nil {
+		return err
+	}
+	if got.Int64 != 1 {
+		return errors.New("timeout waiting for migration lock")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+	return fn(conn)
+}
+
+func ensureTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    BIGINT       NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    dirty      TINYINT(1)   NOT NULL DEFAULT 0,
+    applied_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (version)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4`)
+	return err
+}
+
+// appliedVersions 已经执行过的版本，有 dirty 的版本时返回 ErrDirty
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]struct{}, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, dirty FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int64]struct{})
+	for rows.Next() {
+		var (
+			version int64
+			dirty   bool
+		)
+		if err = rows.Scan(&version, &dirty); err != nil {
+			return nil, err
+		}
+		if dirty {
+			return nil, fmt.Errorf("version %d: %w", version, ErrDirty)
+		}
+		applied[version] = struct{}{}
+	}
+	return applied, rows.Err()
+}
+
+// execScript 逐条执行脚本里的语句 (没有开 multiStatements)
+func execScript(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
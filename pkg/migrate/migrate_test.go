@@ -0,0 +1,63 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- 注释里的分号; 不算
+CREATE TABLE t (
+    a VARCHAR(8) DEFAULT 'x;y' COMMENT "说明; 也不算", # 行尾注释;
+    ` + "`b;c`" + ` INT
+);
+/* 块注释; */ INSERT INTO t (a) VALUES ('it\'s;ok');;
+`
+	assert.Equal(t, []string{
+		"CREATE TABLE t (\n    a VARCHAR(8) DEFAULT 'x;y' COMMENT \"说明; 也不算\", \n    `b;c` INT\n)",
+		"INSERT INTO t (a) VALUES ('it\\'s;ok')",
+	}, splitStatements(script))
+	assert.Empty(t, splitStatements("-- 只有注释\n"))
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_b.up.sql":   {Data: []byte("B")},
+		"0002_b.down.sql": {Data: []byte("-B")},
+		"0001_a.up.sql":   {Data: []byte("A")},
+		"0001_a.down.sql": {Data: []byte("-A")},
+		"README.md":       {Data: []byte("忽略")},
+	}
+	migrations, err := Load(fsys)
+	assert.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "a", Up: "A", Down: "-A"},
+		{Version: 2, Name: "b", Up: "B", Down: "-B"},
+	}, migrations)
+
+	// 缺 down 文件 / 同一个版本两个名字
+	_, err = Load(fstest.MapFS{"0001_a.up.sql": {Data: []byte("A")}})
+	assert.Error(t, err)
+	fsys["0002_c.up.sql"] = &fstest.MapFile{Data: []byte("C")}
+	_, err = Load(fsys)
+	assert.Error(t, err)
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0007_x.up.sql"), []byte("X"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0007_x.down.sql"), []byte("-X"), 0o644))
+
+	up, down, err := Create(dir, "add_tag")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "0008_add_tag.up.sql"), up)
+	assert.Equal(t, filepath.Join(dir, "0008_add_tag.down.sql"), down)
+	assert.FileExists(t, up)
+
+	_, _, err = Create(dir, "Bad-Name")
+	assert.Error(t, err)
+}
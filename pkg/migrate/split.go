@@ -0,0 +1,58 @@
+package migrate
+
+import "strings"
+
+// splitStatements 按分号把脚本切成单条语句
+// 引号 ('、"、`) 里的分号不算，-- / # 单行注释和 /* */ 注释会被去掉，空语句跳过
+func splitStatements(script string) []string {
+	var (
+		stmts []string
+		cur   strings.Builder
+		quote rune // 当前所在的引号，0 表示不在引号里
+	)
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			stmts = append(stmts, s)
+		}
+		cur.Reset()
+	}
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch {
+		case quote != 0:
+			cur.WriteRune(r)
+			if r == '\\' && quote != '`' && next != 0 {
+				cur.WriteRune(next)
+				i++
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			cur.WriteRune(r)
+		case r == '#' || (r == '-' && next == '-'):
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			cur.WriteRune('\n')
+		case r == '/' && next == '*':
+			i += 2
+			for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+				i++
+			}
+			i++
+			cur.WriteRune(' ')
+		case r == ';':
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return stmts
+}
@@ -0,0 +1,151 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// ErrUnknownProvider 配置里没有这个登录方式
+	ErrUnknownProvider = errors.New("unknown oidc provider")
+	// ErrNonceMismatch ID Token 里的 nonce 和发起登录时的不一致 (可能是重放)
+	ErrNonceMismatch = errors.New("oidc nonce mismatch")
+	// ErrMissingIDToken 授权服务器没有返回 id_token (scope 里没带 openid)
+	ErrMissingIDToken = errors.New("oidc id_token missing")
+)
+
+// ProviderConfig 一个身份提供方的配置 (oidc.providers.<name>)
+type ProviderConfig struct {
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"` // 不填默认 openid email profile
+}
+
+// Identity 从 ID Token 里取出来的用户身份
+type Identity struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Username      string `json:"preferred_username"`
+	Name          string `json:"name"`
+}
+
+// Provider 一个身份提供方：授权码 + PKCE 流程
+type Provider struct {
+	Name     string
+	config   oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewProvider 通过 issuer 的 /.well-known/openid-configuration 发现各个端点
+func NewProvider(ctx context.Context, name string, cfg ProviderConfig) (*Provider, error) {
+	p, err := gooidc.NewProvider(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discover oidc provider %s: %w", name, err)
+	}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{gooidc.ScopeOpenID, "email", "profile"}
+	}
+	return &Provider{
+		Name: name,
+		config: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     p.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: p.Verifier(&gooidc.Config{ClientID: cfg.ClientID}),
+	}, nil
+}
+
+// AuthCodeURL 跳转到身份提供方登录页的地址
+// state 防 CSRF，nonce 防 ID Token 重放，verifier 是 PKCE 的原文 (只发 S256 摘要出去)
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	return p.config.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+}
+
+// Exchange 用回调拿到的 code 换 Token，并校验 ID Token (签名、issuer、audience、过期时间、nonce)
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := token.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return nil, ErrMissingIDToken
+	}
+	idToken, err := p.verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	if idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+	identity := new(Identity)
+	if err = idToken.Claims(identity); err != nil {
+		return nil, err
+	}
+	identity.Subject = idToken.Subject
+	return identity, nil
+}
+
+// Registry 按 oidc.providers 配置创建 Provider，并缓存已经发现过的 Provider，避免每次登录都请求一次 discovery
+type Registry struct {
+	cfg       *viper.Viper
+	mu        sync.Mutex
+	providers map[string]*Provider
+}
+
+func NewRegistry(cfg *viper.Viper) *Registry {
+	return &Registry{cfg: cfg, providers: make(map[string]*Provider)}
+}
+
+// Get 按名字取配置里的 Provider，第一次使用时才去做 discovery
+// 这样身份提供方暂时连不上也不影响服务启动
+func (r *Registry) Get(ctx context.Context, name string) (*Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.providers[name]; ok {
+		return p, nil
+	}
+	key := "oidc.providers." + name
+	if name == "" || !r.cfg.IsSet(key) {
+		return nil, ErrUnknownProvider
+	}
+	var cfg ProviderConfig
+	if err := r.cfg.UnmarshalKey(key, &cfg); err != nil {
+		return nil, err
+	}
+	p, err := NewProvider(ctx, name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	r.providers[name] = p
+	return p, nil
+}
+
+// NewState 生成 state / nonce 用的随机串
+func NewState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewVerifier 生成 PKCE code_verifier
+func NewVerifier() string {
+	return oauth2.GenerateVerifier()
+}
@@ -0,0 +1,61 @@
+package oidc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-api-scaffold-v1/pkg/oidc/oidctest"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Server) {
+	srv := oidctest.NewServer("bluebell", "secret")
+	t.Cleanup(srv.Close)
+	p, err := NewProvider(context.Background(), "mock", ProviderConfig{
+		Issuer:       srv.URL,
+		ClientID:     "bluebell",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/oauth/mock/callback",
+	})
+	assert.NoError(t, err)
+	return p, srv
+}
+
+// TestAuthorizationCodeFlow 完整走一遍授权码 + PKCE 流程
+func TestAuthorizationCodeFlow(t *testing.T) {
+	p, srv := newTestProvider(t)
+	verifier := NewVerifier()
+	authURL := p.AuthCodeURL("state-1", "nonce-1", verifier)
+
+	code, state, err := srv.Authorize(authURL, oidctest.User{
+		Subject: "u-1", Email: "alice@example.com", EmailVerified: true, Username: "alice",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	id, err := p.Exchange(context.Background(), code, verifier, "nonce-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "u-1", id.Subject)
+	assert.Equal(t, "alice@example.com", id.Email)
+	assert.True(t, id.EmailVerified)
+	assert.Equal(t, "alice", id.Username)
+
+	_, err = p.Exchange(context.Background(), code, verifier, "nonce-1")
+	assert.Error(t, err, "授权码只能用一次")
+}
+
+// TestPKCEAndNonce verifier 不对换不到 Token，nonce 不对拒绝 ID Token
+func TestPKCEAndNonce(t *testing.T) {
+	p, srv := newTestProvider(t)
+	verifier := NewVerifier()
+	user := oidctest.User{Subject: "u-1"}
+
+	code, _, _ := srv.Authorize(p.AuthCodeURL("s", "n", verifier), user)
+	_, err := p.Exchange(context.Background(), code, NewVerifier(), "n")
+	assert.Error(t, err)
+
+	code, _, _ = srv.Authorize(p.AuthCodeURL("s", "n", verifier), user)
+	_, err = p.Exchange(context.Background(), code, verifier, "other")
+	assert.ErrorIs(t, err, ErrNonceMismatch)
+}
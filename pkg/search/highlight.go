@@ -0,0 +1,78 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// 高亮标签，前端直接当 HTML 渲染 (其余内容已经转义过)
+const (
+	highlightOpen  = "<em>"
+	highlightClose = "</em>"
+)
+
+// Highlight 把 text 里命中 query 的部分用 <em> 包起来
+// text 超过 maxRunes 个字时截取第一个命中位置附近的片段，两端加省略号；maxRunes <= 0 表示不截取
+// 返回值已经做过 HTML 转义，可以直接渲染
+func Highlight(text, query string, maxRunes int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// 1. 标出所有命中的字，相邻 / 重叠的命中会连成一段
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range uniqueTokens(Tokenize(query)) {
+		t := []rune(term)
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) != term {
+				continue
+			}
+			for j := i; j < i+len(t); j++ {
+				marked[j] = true
+			}
+			if first == -1 || i < first {
+				first = i
+			}
+		}
+	}
+
+	// 2. 截取片段：命中位置前面留四分之一的长度做上下文
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		if first > maxRunes/4 {
+			start = first - maxRunes/4
+		}
+		end = start + maxRunes
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxRunes
+		}
+	}
+
+	// 3. 拼接，普通文字转义，命中的部分加标签
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marked[j] == marked[i] {
+			j++
+		}
+		seg := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			b.WriteString(highlightOpen + seg + highlightClose)
+		} else {
+			b.WriteString(seg)
+		}
+		i = j
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryEngine 内存倒排索引，分词规则和 MySQL ngram 一致
+// 不持久化，用于测试和没有 MySQL 的本地开发
+type MemoryEngine struct {
+	mu    sync.RWMutex
+	docs  map[string]map[int64]Document       // 类型 -> ID -> 文档
+	index map[string]map[string]map[int64]int // 类型 -> 词 -> ID -> 出现次数
+}
+
+func NewMemoryEngine() *MemoryEngine {
+	return &MemoryEngine{
+		docs:  make(map[string]map[int64]Document),
+		index: make(map[string]map[string]map[int64]int),
+	}
+}
+
+// Index 添加或更新文档
+func (e *MemoryEngine) Index(_ context.Context, doc Document) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.remove(doc.Type, doc.ID)
+	if e.docs[doc.Type] == nil {
+		e.docs[doc.Type] = make(map[int64]Document)
+		e.index[doc.Type] = make(map[string]map[int64]int)
+	}
+	e.docs[doc.Type][doc.ID] = doc
+	for _, term := range Tokenize(doc.Text) {
+		postings := e.index[doc.Type][term]
+		if postings == nil {
+			postings = make(map[int64]int)
+			e.index[doc.Type][term] = postings
+		}
+		postings[doc.ID]++
+	}
+	return nil
+}
+
+// Remove 删除文档，不存在时什么都不做
+func (e *MemoryEngine) Remove(_ context.Context, docType string, id int64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.remove(docType, id)
+	return nil
+}
+
+// Search 命中任意一个词就算匹配 (和 MySQL 自然语言模式一样)，相关度是命中词的出现次数之和
+// 相关度相同的按 ID 倒序，也就是新的在前
+func (e *MemoryEngine) Search(_ context.Context, q Query) (*Result, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	scores := make(map[int64]int)
+	for _, term := range uniqueTokens(Tokenize(q.Text)) {
+		for id, tf := range e.index[q.Type][term] {
+			if e.match(e.docs[q.Type][id], q) {
+				scores[id] += tf
+			}
+		}
+	}
+
+	ids := make([]int64, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+
+	res := &Result{Total: int64(len(ids))}
+	if q.Offset < len(ids) {
+		ids = ids[q.Offset:]
+		if q.Limit > 0 && len(ids) > q.Limit {
+			ids = ids[:q.Limit]
+		}
+		res.IDs = ids
+	}
+	return res, nil
+}
+
+// match 社区和时间范围过滤
+func (e *MemoryEngine) match(doc Document, q Query) bool {
+	if q.CommunityID != 0 && doc.CommunityID != q.CommunityID {
+		return false
+	}
+	if !q.Start.IsZero() && doc.CreateTime.Before(q.Start) {
+		return false
+	}
+	if !q.End.IsZero() && !doc.CreateTime.Before(q.End) {
+		return false
+	}
+	return true
+}
+
+// remove 调用方持有写锁
+func (e *MemoryEngine) remove(docType string, id int64) {
+	doc, ok := e.docs[docType][id]
+	if !ok {
+		return
+	}
+	for _, term := range Tokenize(doc.Text) {
+		postings := e.index[docType][term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(e.index[docType], term)
+		}
+	}
+	delete(e.docs[docType], id)
+}
@@ -0,0 +1,63 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// 可以搜索的内容类型
+const (
+	TypePost = "post"
+	TypeUser = "user"
+)
+
+// Document 一条可搜索的内容
+// Text 是参与搜索的全部文字 (帖子是标题 + 内容，用户是用户名)
+type Document struct {
+	Type        string
+	ID          int64
+	Text        string
+	CommunityID int64 // 只有帖子有
+	CreateTime  time.Time
+}
+
+// Query 搜索条件，零值的过滤条件表示不限制
+type Query struct {
+	Type        string
+	Text        string
+	CommunityID int64
+	Start       time.Time // 包含
+	End         time.Time // 不包含
+	Offset      int
+	Limit       int
+}
+
+// Result 按相关度从高到低排好的 ID，Total 是符合条件的总数
+type Result struct {
+	IDs   []int64
+	Total int64
+}
+
+// Engine 搜索引擎
+// MySQL FULLTEXT 的索引由数据库自己维护，Index / Remove 什么都不做；内存倒排索引需要业务代码在增删改时调用
+type Engine interface {
+	Index(ctx context.Context, doc Document) error
+	Remove(ctx context.Context, docType string, id int64) error
+	Search(ctx context.Context, q Query) (*Result, error)
+}
+
+// New 根据 search.driver 选择搜索引擎
+// mysql (默认): 使用传进来的 MySQL FULLTEXT 实现；memory: 内存倒排索引，重启后为空，只适合测试和本地开发
+func New(cfg *viper.Viper, mysql Engine) (Engine, error) {
+	switch cfg.GetString("search.driver") {
+	case "", "mysql":
+		return mysql, nil
+	case "memory":
+		return NewMemoryEngine(), nil
+	default:
+		return nil, fmt.Errorf("unknown search.driver %q", cfg.GetString("search.driver"))
+	}
+}
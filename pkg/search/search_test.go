@@ -0,0 +1,67 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"go", "语言", "言入", "入门", "v2"}, Tokenize("Go语言入门 v2!"))
+	assert.Equal(t, []string{"猫"}, Tokenize("猫"))
+	assert.Empty(t, Tokenize("  ，。"))
+}
+
+func TestHighlight(t *testing.T) {
+	// 命中的中文连成一段，英文不区分大小写，其余内容做 HTML 转义
+	assert.Equal(t, "学习<em>Go语言</em> &lt;b&gt;", Highlight("学习Go语言 <b>", "go 语言", 0))
+
+	// 太长时截取命中位置附近的片段
+	text := "一二三四五六七八九十Redis一二三四五六七八九十"
+	assert.Equal(t, "…八九十<em>Redis</em>一二三四五…", Highlight(text, "redis", 13))
+
+	// 没有命中时从头截取
+	assert.Equal(t, "一二三…", Highlight(text, "mysql", 3))
+}
+
+// TestMemoryEngine 相关度排序、社区和时间过滤、分页、更新和删除
+func TestMemoryEngine(t *testing.T) {
+	ctx := context.Background()
+	e := NewMemoryEngine()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	docs := []Document{
+		{Type: TypePost, ID: 1, Text: "Redis 入门", CommunityID: 10, CreateTime: day},
+		{Type: TypePost, ID: 2, Text: "Redis 集群 Redis 持久化", CommunityID: 10, CreateTime: day.Add(24 * time.Hour)},
+		{Type: TypePost, ID: 3, Text: "MySQL 入门", CommunityID: 20, CreateTime: day.Add(48 * time.Hour)},
+		{Type: TypeUser, ID: 1, Text: "redis_fan", CreateTime: day},
+	}
+	for _, d := range docs {
+		assert.NoError(t, e.Index(ctx, d))
+	}
+
+	search := func(q Query) []int64 {
+		res, err := e.Search(ctx, q)
+		assert.NoError(t, err)
+		return res.IDs
+	}
+
+	// 命中次数多的在前；类型之间互不影响
+	assert.Equal(t, []int64{2, 1}, search(Query{Type: TypePost, Text: "redis"}))
+	assert.Equal(t, []int64{3, 1}, search(Query{Type: TypePost, Text: "入门"}))
+	assert.Equal(t, []int64{3}, search(Query{Type: TypePost, Text: "入门", CommunityID: 20}))
+	assert.Equal(t, []int64{1}, search(Query{Type: TypePost, Text: "redis", End: day.Add(24 * time.Hour)}))
+	assert.Equal(t, []int64{1}, search(Query{Type: TypePost, Text: "redis", Offset: 1, Limit: 1}))
+
+	res, err := e.Search(ctx, Query{Type: TypePost, Text: "redis", Offset: 5, Limit: 1})
+	assert.NoError(t, err)
+	assert.Empty(t, res.IDs)
+	assert.Equal(t, int64(2), res.Total)
+
+	// 更新会覆盖旧的词，删除后搜不到
+	assert.NoError(t, e.Index(ctx, Document{Type: TypePost, ID: 2, Text: "Kafka", CommunityID: 10, CreateTime: day}))
+	assert.Equal(t, []int64{1}, search(Query{Type: TypePost, Text: "redis"}))
+	assert.NoError(t, e.Remove(ctx, TypePost, 1))
+	assert.Empty(t, search(Query{Type: TypePost, Text: "redis"}))
+}
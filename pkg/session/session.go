@@ -0,0 +1,178 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// Cookie 模式 (auth.cookie.mode)
+const (
+	// ModeOff 默认，只支持 Authorization: Bearer
+	ModeOff = "off"
+	// ModeBoth 登录后既设置 Cookie，也在响应体里返回 Token (兼容 App 和浏览器)
+	ModeBoth = "both"
+	// ModeCookie 只设置 Cookie，响应体里不返回 Token，前端 JS 完全接触不到 Token
+	ModeCookie = "cookie"
+)
+
+// CSRFHeader 前端从 CSRF Cookie 里读出值，放在这个请求头里 (double-submit)
+const CSRFHeader = "X-CSRF-Token"
+
+// Manager 按 auth.cookie 配置读写登录 Cookie
+type Manager struct {
+	cfg *viper.Viper
+}
+
+func New(cfg *viper.Viper) *Manager {
+	return &Manager{cfg: cfg}
+}
+
+// Mode 当前的 Cookie 模式
+func (m *Manager) Mode() string {
+	switch mode := m.cfg.GetString("auth.cookie.mode"); mode {
+	case ModeBoth, ModeCookie:
+		return mode
+	default:
+		return ModeOff
+	}
+}
+
+// Enabled 是否开启了 Cookie 登录
+func (m *Manager) Enabled() bool {
+	return m.Mode() != ModeOff
+}
+
+// SetTokens 登录 / 刷新成功后写入 Token Cookie，同时换一个新的 CSRF Token
+// Access Token 对整个站点可见，Refresh Token 只发给刷新接口，减少暴露
+func (m *Manager) SetTokens(c *gin.Context, accessToken string, accessExpire time.Duration, refreshToken string, refreshExpire time.Duration) error {
+	csrf, err := newCSRFToken()
+	if err != nil {
+		return err
+	}
+	m.setCookie(c, m.accessCookieName(), accessToken, m.cookiePath(), accessExpire, true)
+	m.setCookie(c, m.refreshCookieName(), refreshToken, m.refreshCookiePath(), refreshExpire, true)
+	// CSRF Cookie 必须能被前端 JS 读到，所以不能 HttpOnly；有效期跟 Refresh Token 一致
+	m.setCookie(c, m.csrfCookieName(), csrf, m.cookiePath(), refreshExpire, false)
+	return nil
+}
+
+// Clear 退出登录时删除所有 Cookie
+func (m *Manager) Clear(c *gin.Context) {
+	m.setCookie(c, m.accessCookieName(), "", m.cookiePath(), -1, true)
+	m.setCookie(c, m.refreshCookieName(), "", m.refreshCookiePath(), -1, true)
+	m.setCookie(c, m.csrfCookieName(), "", m.cookiePath(), -1, false)
+}
+
+// AccessToken 从 Cookie 读取 Access Token，没开启 Cookie 模式或者没有时返回空字符串
+func (m *Manager) AccessToken(c *gin.Context) string {
+	return m.readCookie(c, m.accessCookieName())
+}
+
+// RefreshToken 从 Cookie 读取 Refresh Token
+func (m *Manager) RefreshToken(c *gin.Context) string {
+	return m.readCookie(c, m.refreshCookieName())
+}
+
+// HasTokenCookie 请求是否带着登录 Cookie (浏览器会自动带上，所以需要 CSRF 校验)
+func (m *Manager) HasTokenCookie(c *gin.Context) bool {
+	return m.AccessToken(c) != "" || m.RefreshToken(c) != ""
+}
+
+// VerifyCSRF double-submit 校验：请求头里的值必须和 CSRF Cookie 一致
+// 跨站的页面能让浏览器带上 Cookie，但读不到 Cookie 的值，也就伪造不了请求头
+func (m *Manager) VerifyCSRF(c *gin.Context) bool {
+	cookie := m.readCookie(c, m.csrfCookieName())
+	header := c.GetHeader(CSRFHeader)
+	if cookie == "" || header == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+func (m *Manager) readCookie(c *gin.Context, name string) string {
+	if !m.Enabled() {
+		return ""
+	}
+	v, err := c.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return v
+}
+
+// setCookie maxAge 小于 0 表示删除
+func (m *Manager) setCookie(c *gin.Context, name, value, path string, maxAge time.Duration, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   m.cfg.GetString("auth.cookie.domain"),
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   m.secure(),
+		HttpOnly: httpOnly,
+		SameSite: m.sameSite(),
+	})
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (m *Manager) accessCookieName() string {
+	return m.stringOr("auth.cookie.name", "bluebell_token")
+}
+
+func (m *Manager) refreshCookieName() string {
+	return m.stringOr("auth.cookie.refresh_name", "bluebell_refresh")
+}
+
+func (m *Manager) csrfCookieName() string {
+	return m.stringOr("auth.cookie.csrf_name", "bluebell_csrf")
+}
+
+func (m *Manager) cookiePath() string {
+	return m.stringOr("auth.cookie.path", "/")
+}
+
+// refreshCookiePath Refresh Token Cookie 只在刷新接口上发送
+func (m *Manager) refreshCookiePath() string {
+	return m.stringOr("auth.cookie.refresh_path", "/api/v1/auth")
+}
+
+// secure 默认只通过 HTTPS 发送，本地 http 调试时可以设置 auth.cookie.secure: false
+func (m *Manager) secure() bool {
+	if !m.cfg.IsSet("auth.cookie.secure") {
+		return true
+	}
+	return m.cfg.GetBool("auth.cookie.secure")
+}
+
+// sameSite 默认 Lax；前后端跨站部署时需要 None (此时必须 Secure)
+func (m *Manager) sameSite() http.SameSite {
+	switch strings.ToLower(m.cfg.GetString("auth.cookie.same_site")) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+func (m *Manager) stringOr(key, def string) string {
+	if v := m.cfg.GetString(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// newManager 指定 Cookie 模式的 Manager
+func newManager(mode string) *Manager {
+	cfg := viper.New()
+	cfg.Set("auth.cookie.mode", mode)
+	return New(cfg)
+}
+
+func newContext(req *http.Request) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	return c, w
+}
+
+// TestSetTokens Token Cookie 是 HttpOnly + Secure，CSRF Cookie 能被 JS 读取
+func TestSetTokens(t *testing.T) {
+	m := newManager(ModeCookie)
+
+	c, w := newContext(httptest.NewRequest(http.MethodPost, "/api/v1/login", nil))
+	assert.NoError(t, m.SetTokens(c, "access", time.Minute, "refresh", time.Hour))
+
+	cookies := map[string]*http.Cookie{}
+	for _, ck := range w.Result().Cookies() {
+		cookies[ck.Name] = ck
+	}
+	access := cookies["bluebell_token"]
+	assert.Equal(t, "access", access.Value)
+	assert.True(t, access.HttpOnly)
+	assert.True(t, access.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, access.SameSite)
+	assert.Equal(t, "/api/v1/auth", cookies["bluebell_refresh"].Path)
+	assert.False(t, cookies["bluebell_csrf"].HttpOnly)
+	assert.Len(t, cookies["bluebell_csrf"].Value, 64)
+}
+
+// TestVerifyCSRF 请求头必须和 Cookie 一致
+func TestVerifyCSRF(t *testing.T) {
+	m := newManager(ModeBoth)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/logout", nil)
+	req.AddCookie(&http.Cookie{Name: "bluebell_token", Value: "access"})
+	req.AddCookie(&http.Cookie{Name: "bluebell_csrf", Value: "abc"})
+	c, _ := newContext(req)
+	assert.True(t, m.HasTokenCookie(c))
+	assert.False(t, m.VerifyCSRF(c), "没带请求头")
+
+	req.Header.Set(CSRFHeader, "abd")
+	assert.False(t, m.VerifyCSRF(c))
+
+	req.Header.Set(CSRFHeader, "abc")
+	assert.True(t, m.VerifyCSRF(c))
+}
+
+// TestDisabled 没开启时忽略 Cookie
+func TestDisabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "bluebell_token", Value: "access"})
+	c, _ := newContext(req)
+	m := newManager("")
+	assert.False(t, m.Enabled())
+	assert.Equal(t, "", m.AccessToken(c))
+}
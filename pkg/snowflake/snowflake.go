@@ -1,35 +1,45 @@
 package snowflake
 
 import (
+	"sync"
 	"time"
 
 	sf "github.com/bwmarrin/snowflake"
 )
 
-// 定义一个全局节点
-var node *sf.Node
+// epochMu 底层库的起始时间是包级变量，NewNode 时才会读取，创建节点时加锁避免互相覆盖
+var epochMu sync.Mutex
 
-// Init 初始化雪花算法节点
+// Node 雪花算法节点，每个应用实例持有自己的节点
+type Node struct {
+	node *sf.Node
+}
+
+// NewNode 创建雪花算法节点
 // startTime: 项目上线时间 (格式: "2006-01-02")，比如 "2026-01-01"
 // machineID: 机器ID (0-1023)，在分布式部署时，每台服务器必须不同！
-func Init(startTime string, machineID int64) (err error) {
+func NewNode(startTime string, machineID int64) (*Node, error) {
 	// 1. 定制开始时间 (这一步很重要！)
 	// 如果不设置，默认是从 2010 年开始算，你就会损失 16 年的有效期
-	var st time.Time
-	st, err = time.Parse("2006-01-02", startTime)
+	st, err := time.Parse("2006-01-02", startTime)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	// 设置库的 Epoch (起始时间) 为你指定的时间
+	epochMu.Lock()
+	defer epochMu.Unlock()
+	// 设置库的 Epoch (起始时间) 为你指定的时间，节点创建时会记下来
 	sf.Epoch = st.UnixNano() / 1000000
 
 	// 2. 创建节点
-	node, err = sf.NewNode(machineID)
-	return
+	node, err := sf.NewNode(machineID)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{node: node}, nil
 }
 
 // GenID 生成一个 int64 的分布式唯一 ID
-func GenID() int64 {
-	return node.Generate().Int64()
+func (n *Node) GenID() int64 {
+	return n.node.Generate().Int64()
 }
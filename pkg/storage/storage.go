@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// ErrNotFound 文件不存在
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrInvalidSignature 下载链接签名不对或者已经过期
+	ErrInvalidSignature = errors.New("storage: invalid or expired signature")
+)
+
+// Storage 文件存储接口
+// 本地开发用 LocalStorage，线上用 S3Storage (AWS S3 / MinIO / 其他兼容 S3 的对象存储)
+type Storage interface {
+	// Put 保存文件，key 相同会覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open 读取文件，不存在返回 ErrNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Exists 文件是否存在
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete 删除文件，不存在不报错
+	Delete(ctx context.Context, key string) error
+	// SignedURL 生成一个限时有效的下载地址
+	SignedURL(key string, expire time.Duration) (string, error)
+}
+
+// Verifier 由我们自己的接口提供下载的存储 (LocalStorage) 需要校验下载链接的签名
+// S3 的下载链接由对象存储自己校验，不需要实现
+type Verifier interface {
+	Verify(key, expires, signature string) error
+}
+
+// New 根据 storage.driver 创建存储
+// local (默认): 存在本地磁盘，下载走 GET /files/*key；s3: 兼容 S3 的对象存储
+func New(cfg *viper.Viper) (Storage, error) {
+	switch cfg.GetString("storage.driver") {
+	case "", "local":
+		secret := cfg.GetString("storage.local.secret")
+		if secret == "" {
+			return nil, errors.New("storage.local.secret is required")
+		}
+		return &LocalStorage{
+			Dir:     cfg.GetString("storage.local.dir"),
+			BaseURL: cfg.GetString("storage.local.base_url"),
+			Secret:  []byte(secret),
+		}, nil
+	case "s3":
+		s, err := NewS3Storage(S3Config{
+			Endpoint:  cfg.GetString("storage.s3.endpoint"),
+			Region:    cfg.GetString("storage.s3.region"),
+			Bucket:    cfg.GetString("storage.s3.bucket"),
+			AccessKey: cfg.GetString("storage.s3.access_key"),
+			SecretKey: cfg.GetString("storage.s3.secret_key"),
+			PathStyle: cfg.GetBool("storage.s3.path_style"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown storage.driver %q", cfg.GetString("storage.driver"))
+	}
+}
+
+// OpenSigned 校验下载链接后读取文件 (只有 LocalStorage 这类需要我们自己提供下载的存储支持)
+func OpenSigned(ctx context.Context, s Storage, key, expires, signature string) (io.ReadCloser, error) {
+	v, ok := s.(Verifier)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if err := v.Verify(key, expires, signature); err != nil {
+		return nil, err
+	}
+	return s.Open(ctx, key)
+}
@@ -0,0 +1,59 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcSecret RFC 6238 附录 B 的 SHA1 测试密钥 "12345678901234567890"
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// TestRFC6238Vectors 用 RFC 6238 的测试向量校验算法 (取 8 位结果的后 6 位)
+func TestRFC6238Vectors(t *testing.T) {
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for ts, want := range cases {
+		code, err := GenerateCode(rfcSecret, time.Unix(ts, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code, "time=%d", ts)
+	}
+}
+
+// TestValidate 允许前后一个时间片的偏差，超出范围不行
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	code, _ := GenerateCode(secret, now)
+	s, ok := Validate(secret, code, now.Add(Period))
+	assert.True(t, ok)
+	assert.Equal(t, step(now), s)
+
+	_, ok = Validate(secret, code, now.Add(3*Period))
+	assert.False(t, ok)
+
+	_, ok = Validate(secret, "12345", now)
+	assert.False(t, ok, "位数不对直接失败")
+}
+
+// TestURI otpauth 地址包含 App 需要的全部参数
+func TestURI(t *testing.T) {
+	u, err := url.Parse(URI("Bluebell", "alice", "JBSWY3DPEHPK3PXP"))
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Bluebell:alice", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "Bluebell", u.Query().Get("issuer"))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/locales/zh"
@@ -12,66 +13,138 @@ import (
 	zh_translations "github.com/go-playground/validator/v10/translations/zh"
 )
 
-// 定义一个全局翻译器，方便在其他地方调用
-var Trans ut.Translator
+var (
+	mu          sync.Mutex
+	translators = map[string]ut.Translator{} // 已经初始化过的翻译器，按语言缓存
+)
 
-// InitTrans 初始化翻译器
+// NewTranslator 返回指定语言的翻译器 (校验错误信息翻译成中文)
 // locale: 语言环境，通常传 "zh"
-func InitTrans(locale string) (err error) {
+// Gin 的校验引擎是进程级的，自定义规则和翻译只注册一次，同一种语言多次调用拿到的是同一个翻译器，
+// 所以同一个进程里的多个应用实例可以放心各自调用
+func NewTranslator(locale string) (ut.Translator, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if trans, ok := translators[locale]; ok {
+		return trans, nil
+	}
+
 	// 1. 修改 Gin 框架中的 Validator 引擎属性，实现定制
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-
-		// =============================================================
-		// 🔥 核心功能：自定义错误字段名 (使用 json tag)
-		// =============================================================
-		// 注册一个获取 json tag 的自定义方法
-		// 默认情况下 validator 返回的是结构体字段名 (如 "UserName")
-		// 这样写之后，校验失败时，错误信息就会显示 "user_name" (即 json tag 的值)
-		v.RegisterTagNameFunc(func(fld reflect.StructField) string {
-			// 获取 tag 中的 json 值，例如 `json:"user_name,omitempty"`
-			name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-			// 如果 json tag 是 "-"，说明忽略该字段，返回空
-			if name == "-" {
-				return ""
-			}
-			return name
-		})
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil, fmt.Errorf("unexpected validator engine %T", binding.Validator.Engine())
+	}
 
-		// 2. 初始化翻译器
-		zhT := zh.New() // 中文翻译器
-		// 第一个参数是备用语言，第二个是当前语言
-		uni := ut.New(zhT, zhT)
-
-		// 获取具体的翻译实例
-		// 通常我们 locale 传 "zh"，这里就会获取到中文翻译器
-		var ok bool
-		Trans, ok = uni.GetTranslator(locale)
-		if !ok {
-			return fmt.Errorf("uni.GetTranslator(%s) failed", locale)
+	// =============================================================
+	// 🔥 核心功能：自定义错误字段名 (使用 json tag)
+	// =============================================================
+	// 注册一个获取 json tag 的自定义方法
+	// 默认情况下 validator 返回的是结构体字段名 (如 "UserName")
+	// 这样写之后，校验失败时，错误信息就会显示 "user_name" (即 json tag 的值)
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		// 获取 tag 中的 json 值，例如 `json:"user_name,omitempty"`
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		// 如果 json tag 是 "-"，说明忽略该字段，返回空
+		if name == "-" {
+			return ""
 		}
+		return name
+	})
 
-		// =============================================================
-		// 🔥 核心功能：注册中文翻译
-		// =============================================================
-		// 这一步把 validator 内置的英文错误信息替换成中文
-		switch locale {
-		case "zh":
-			err = zh_translations.RegisterDefaultTranslations(v, Trans)
-		default:
-			err = zh_translations.RegisterDefaultTranslations(v, Trans)
-		}
-		return
+	// 2. 初始化翻译器
+	zhT := zh.New() // 中文翻译器
+	// 第一个参数是备用语言，第二个是当前语言
+	uni := ut.New(zhT, zhT)
+
+	// 获取具体的翻译实例
+	// 通常我们 locale 传 "zh"，这里就会获取到中文翻译器
+	trans, ok := uni.GetTranslator(locale)
+	if !ok {
+		return nil, fmt.Errorf("uni.GetTranslator(%s) failed", locale)
+	}
+
+	// =============================================================
+	// 🔥 核心功能：注册中文翻译
+	// =============================================================
+	// 这一步把 validator 内置的英文错误信息替换成中文
+	if err := zh_translations.RegisterDefaultTranslations(v, trans); err != nil {
+		return nil, err
+	}
+
+	// =============================================================
+	// 🔥 自定义校验：notblank 不能全是空白 (required 只拦得住空字符串，拦不住 "   ")
+	// =============================================================
+	if err := v.RegisterValidation("notblank", notBlank); err != nil {
+		return nil, err
+	}
+	err := v.RegisterTranslation("notblank", trans,
+		func(ut ut.Translator) error {
+			return ut.Add("notblank", "{0}不能为空白", true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T("notblank", fe.Field())
+			return t
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// =============================================================
+	// 🔥 自定义校验：username 只能包含字母、数字、下划线、点和短横线
+	// =============================================================
+	// 不允许空白、控制字符和各种 Unicode 同形字，避免冒充别人的用户名
+	if err := v.RegisterValidation("username", username); err != nil {
+		return nil, err
+	}
+	err = v.RegisterTranslation("username", trans,
+		func(ut ut.Translator) error {
+			return ut.Add("username", "{0}只能包含字母、数字、下划线、点和短横线", true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T("username", fe.Field())
+			return t
+		})
+	if err != nil {
+		return nil, err
+	}
+	translators[locale] = trans
+	return trans, nil
+}
+
+// notBlank 字符串去掉首尾空白后不能为空
+func notBlank(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.String {
+		return true
+	}
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// UsernameMinLen / UsernameMaxLen 用户名长度范围，和 ParamSignUp 的 min / max 保持一致
+const (
+	UsernameMinLen = 3
+	UsernameMaxLen = 32
+)
+
+// IsUsernameRune 用户名允许的字符：ASCII 字母、数字、下划线、点和短横线
+func IsUsernameRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-'
+}
+
+// username 字符串里的每个字符都必须是 IsUsernameRune 允许的 (长度交给 min / max 校验)
+func username(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.String {
+		return true
 	}
-	return
+	return !strings.ContainsFunc(fl.Field().String(), func(r rune) bool { return !IsUsernameRune(r) })
 }
 
 // RemoveTopStruct 去除结构体名称前缀
-// validator 返回的错误 key 默认是 "StructName.FieldName" (例如 "SignUpParam.Password")
+// validator 返回的错误 key 默认是 "StructName.FieldName" (例如 "ParamSignUp.Password")
 // 我们想要的是纯粹的 "password" 或者 "mobile"
 func RemoveTopStruct(fields map[string]string) map[string]string {
 	res := map[string]string{}
 	for field, err := range fields {
-		// field 可能是 "SignUpParam.password"
+		// field 可能是 "ParamSignUp.password"
 		// err 是翻译后的错误信息，例如 "password 为必填字段"
 
 		// 截取点号之后的部分
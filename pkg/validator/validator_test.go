@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNotBlank 全是空白的标题过不了 notblank，错误信息翻译成中文并去掉结构体名
+func TestNotBlank(t *testing.T) {
+	trans, err := NewTranslator("zh")
+	assert.NoError(t, err)
+
+	type param struct {
+		Title string `json:"title" binding:"required,notblank"`
+	}
+	assert.NoError(t, binding.Validator.ValidateStruct(&param{Title: " 标题 "}))
+
+	err = binding.Validator.ValidateStruct(&param{Title: " \t\n"})
+	errs, ok := err.(validator.ValidationErrors)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"title": "title不能为空白"}, RemoveTopStruct(errs.Translate(trans)))
+}
+
+// TestUsername 用户名只能包含字母、数字、下划线、点和短横线
+func TestUsername(t *testing.T) {
+	trans, err := NewTranslator("zh")
+	assert.NoError(t, err)
+
+	type param struct {
+		Username string `json:"username" binding:"required,min=3,max=32,username"`
+	}
+	assert.NoError(t, binding.Validator.ValidateStruct(&param{Username: "john.doe_1-x"}))
+	for _, name := range []string{"ad min", "аdmin", "admin\u200b", "<b>"} {
+		assert.Error(t, binding.Validator.ValidateStruct(&param{Username: name}), name)
+	}
+
+	err = binding.Validator.ValidateStruct(&param{Username: "a/b"})
+	errs, ok := err.(validator.ValidationErrors)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"username": "username只能包含字母、数字、下划线、点和短横线"}, RemoveTopStruct(errs.Translate(trans)))
+}
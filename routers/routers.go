@@ -6,19 +6,22 @@ import (
 
 	"github.com/gin-contrib/pprof" // 👈 【🔥新增】PProf 性能分析专用包
 	"github.com/gin-gonic/gin"
-	"github.com/spf13/viper" // 👈 【新增】需要读取配置文件
 
 	// 👇 【新增】这里必须导入 swagger 的两个包，否则下面的 gs 和 swaggerFiles 会报错 undefined
 	swaggerFiles "github.com/swaggo/files"
 	gs "github.com/swaggo/gin-swagger"
 
+	"gin-api-scaffold-v1/app"
 	"gin-api-scaffold-v1/controller"
+	"gin-api-scaffold-v1/logic"
 	"gin-api-scaffold-v1/middleware"
 )
 
 // SetupRouter 配置路由入口
-// 负责把所有的 URL 路径和 Controller 里的函数对应起来
-func SetupRouter() *gin.Engine {
+// 负责把所有的 URL 路径和 Controller 里的函数对应起来，中间件和处理函数用的都是 a 上的依赖
+func SetupRouter(a *app.App) *gin.Engine {
+	h := a.Handler
+
 	// 1. 创建 Gin 实例 (白纸模式)
 	// 使用 gin.New() 而不是 gin.Default()，以便我们自己定制中间件
 	r := gin.New()
@@ -34,15 +37,17 @@ func SetupRouter() *gin.Engine {
 	// 2. 注册全局中间件 (Middleware)
 	// =======================================================
 	// 记录请求日志：把 Gin 的请求详情记录到我们的 Zap 日志文件中
-	r.Use(middleware.GinLogger())
+	r.Use(middleware.GinLogger(a.Logger))
 	// 崩溃恢复：防止程序 Panic 导致整个服务挂掉
-	r.Use(middleware.GinRecovery(true))
-	// 跨域处理 (CORS)：允许前端跨域访问
-	r.Use(middleware.Cors())
+	r.Use(middleware.GinRecovery(a.Logger, true))
+	// 参数校验的错误信息用这个实例的翻译器
+	r.Use(middleware.Translator(a.Trans))
+	// 跨域处理 (CORS)：只允许 cors.allowed_origins 里的前端跨域访问
+	r.Use(middleware.Cors(a.Config.GetStringSlice("cors.allowed_origins")))
 
 	// 🔥 【新增】注册全局限流中间件 (令牌桶)
 	// 从配置文件读取 QPS (每秒请求数)
-	qps := viper.GetInt64("rate_limit.qps")
+	qps := a.Config.GetInt64("rate_limit.qps")
 	if qps > 0 {
 		// 计算填充间隔: 如果 QPS 是 1000，那么间隔就是 1秒/1000 = 1毫秒
 		// 也就是说：每 1 毫秒往桶里放一个令牌，一秒钟正好放 1000 个
@@ -57,6 +62,10 @@ func SetupRouter() *gin.Engine {
 	// =======================================================
 	// 健康检查接口，访问：GET /ping
 	r.GET("/ping", controller.Ping)
+	// JWKS 公钥集合，其他服务用它独立验证我们签发的 Token：GET /.well-known/jwks.json
+	r.GET("/.well-known/jwks.json", h.JWKSHandler)
+	// 本地存储的文件下载 (链接自带签名和过期时间，不需要 Token)：GET /files/*key
+	r.GET("/files/*key", h.DownloadFileHandler)
 
 	// =======================================================
 	// 4. 业务路由分组 (Business Logic)
@@ -64,14 +73,42 @@ func SetupRouter() *gin.Engine {
 	// 创建一个路由组，前缀是 /api/v1
 	// 此时 api 变量还没有挂载 JWT 中间件
 	api := r.Group("/api/v1")
+	// 接口处理超时 (app.request_timeout 秒) 后取消还在执行的数据库查询，0 表示不限制
+	// 只挂在 /api/v1 上，pprof 采样、文件下载这种本来就耗时的请求不受影响
+	if timeout := a.Config.GetInt("app.request_timeout"); timeout > 0 {
+		api.Use(middleware.Timeout(time.Duration(timeout) * time.Second))
+	}
+	// Cookie 登录模式下，靠 Cookie 鉴权的写请求必须带上 X-CSRF-Token (没开启时直接放行)
+	api.Use(middleware.CSRFMiddleware(a.Cookies))
 	{
 		// ---------------------------------------------------
 		// 🚫 公开路由 (无需 Token 即可访问)
 		// ---------------------------------------------------
 		// 用户注册：POST /api/v1/signup
-		api.POST("/signup", controller.SignUpHandler)
+		api.POST("/signup", h.SignUpHandler)
 		// 用户登录：POST /api/v1/login
-		api.POST("/login", controller.LoginHandler)
+		api.POST("/login", h.LoginHandler)
+		// 两步登录第二步 (临时 Token + 验证码换正式 Token)：POST /api/v1/login/mfa
+		api.POST("/login/mfa", h.LoginMFAHandler)
+		// 刷新 Token：POST /api/v1/auth/refresh (用 Refresh Token 换新 Token，不需要 Access Token)
+		api.POST("/auth/refresh", h.RefreshTokenHandler)
+		// 第三方登录 (OIDC)：获取跳转地址 / 回调换 Token
+		api.GET("/oauth/:provider/authorize", h.OIDCAuthorizeHandler)
+		api.POST("/oauth/:provider/callback", h.OIDCCallbackHandler)
+		// 邮件链接：验证邮箱 / 找回密码 / 重置密码
+		api.POST("/verify-email", h.VerifyEmailHandler)
+		api.POST("/forgot-password", h.ForgotPasswordHandler)
+		api.POST("/reset-password", h.ResetPasswordHandler)
+		// 社区：列表 / 详情
+		api.GET("/communities", h.ListCommunitiesHandler)
+		api.GET("/communities/:id", h.GetCommunityHandler)
+		// 帖子：社区帖子列表 / 帖子详情
+		api.GET("/communities/:id/posts", h.ListCommunityPostsHandler)
+		api.GET("/posts", h.ListPostsHandler)
+		api.GET("/search", h.SearchHandler)
+		api.GET("/posts/:id", h.GetPostHandler)
+		api.GET("/posts/:id/comments", h.ListCommentsHandler)
+		api.GET("/comments/:id/replies", h.ListCommentRepliesHandler)
 
 		// ---------------------------------------------------
 		// 🔒 私有路由 (必须带 Token 才能访问)
@@ -79,16 +116,86 @@ func SetupRouter() *gin.Engine {
 		// ⚡️ 核心技巧：创建一个新的路由组 auth
 		// 虽然 auth 的路径前缀和 api 一样 (都是 /api/v1)，
 		// 但我们只给 auth 这个组挂载了 JWT 中间件！
+		// API Key 也走这个组，所以每个接口都要挂 RequireScope 声明需要的 Scope，
+		// 不该让 API Key 调用的接口放进下面的 account 组
 		auth := api.Group("")
-		auth.Use(middleware.JWTAuthMiddleware()) // 挂载鉴权中间件
+		auth.Use(middleware.JWTAuthMiddleware(a.Service, a.JWT, a.Cookies)) // 挂载鉴权中间件
 		{
 			// 获取个人信息 (测试 JWT 用)
 			// 访问路径：GET /api/v1/home
-			// 只有 Token 验证通过，才会进入 controller.GetProfileHandler
-			auth.GET("/home", controller.GetProfileHandler)
-
-			// 未来其他的私有接口写在这里...
-			// auth.POST("/article/publish", controller.CreateArticleHandler)
+			// 只有 Token 验证通过，才会进入 h.GetProfileHandler
+			auth.GET("/home", middleware.RequireScope("profile:read"), h.GetProfileHandler)
+			// 个人资料 (从数据库读写)：GET / PATCH /api/v1/me
+			auth.GET("/me", middleware.RequireScope("profile:read"), h.GetMeHandler)
+			auth.PATCH("/me", middleware.RequireScope("profile:write"), h.UpdateMeHandler)
+			// 头像：上传 / 删除
+			auth.POST("/me/avatar", middleware.RequireScope("profile:write"), h.UploadAvatarHandler)
+			auth.DELETE("/me/avatar", middleware.RequireScope("profile:write"), h.DeleteAvatarHandler)
+			// 上传文件：POST /api/v1/files (multipart/form-data，字段名 file)
+			auth.POST("/files", middleware.RequireScope("file:upload"), h.UploadFileHandler)
+
+			// 社区：创建 / 加入 / 退出 / 任免版主
+			auth.POST("/communities", middleware.RequireScope("community:create"), h.CreateCommunityHandler)
+			auth.POST("/communities/:id/join", middleware.RequireScope("community:join"), h.JoinCommunityHandler)
+			auth.POST("/communities/:id/leave", middleware.RequireScope("community:join"), h.LeaveCommunityHandler)
+			auth.PUT("/communities/:id/moderators/:user_id", middleware.RequireScope(logic.PermissionCommunityManage), h.AddCommunityModeratorHandler)
+			auth.DELETE("/communities/:id/moderators/:user_id", middleware.RequireScope(logic.PermissionCommunityManage), h.RemoveCommunityModeratorHandler)
+
+			// 帖子：发帖 / 编辑 / 删除
+			auth.POST("/posts", middleware.RequireScope("post:write"), h.CreatePostHandler)
+			auth.PUT("/posts/:id", middleware.RequireScope("post:write"), h.UpdatePostHandler)
+			auth.DELETE("/posts/:id", middleware.RequireScope(logic.PermissionPostDelete), h.DeletePostHandler)
+			auth.POST("/posts/:id/vote", middleware.RequireScope("post:vote"), h.VotePostHandler)
+			auth.POST("/posts/:id/comments", middleware.RequireScope("comment:write"), h.CreateCommentHandler)
+			auth.DELETE("/comments/:id", middleware.RequireScope(logic.PermissionCommentDelete), h.DeleteCommentHandler)
+
+			// ---------------------------------------------------
+			// 🔐 账号安全相关 (只能本人登录后操作，API Key 不能调用)
+			// ---------------------------------------------------
+			account := auth.Group("")
+			account.Use(middleware.DenyAPIKey())
+			{
+				// 两步验证：开始绑定 / 确认绑定 / 重新生成恢复码
+				account.POST("/mfa/enroll", h.MFAEnrollHandler)
+				account.POST("/mfa/confirm", h.MFAConfirmHandler)
+				account.POST("/mfa/recovery-codes", h.MFARecoveryCodesHandler)
+
+				// 修改密码 / 修改邮箱 (都需要输入当前密码)
+				account.POST("/me/password", h.ChangePasswordHandler)
+				account.POST("/me/email", h.ChangeEmailHandler)
+
+				// 重新发送验证邮件：POST /api/v1/verify-email/resend
+				account.POST("/verify-email/resend", h.ResendVerifyEmailHandler)
+
+				// 个人 API Key：创建 / 列表 / 吊销
+				account.POST("/api-keys", h.CreateAPIKeyHandler)
+				account.GET("/api-keys", h.ListAPIKeysHandler)
+				account.DELETE("/api-keys/:id", h.RevokeAPIKeyHandler)
+
+				// 登录设备：列表 / 踢掉某台设备
+				account.GET("/sessions", h.ListSessionsHandler)
+				account.DELETE("/sessions/:id", h.RevokeSessionHandler)
+
+				// 退出当前设备：POST /api/v1/logout
+				account.POST("/logout", h.LogoutHandler)
+				// 退出所有设备：POST /api/v1/logout-all
+				account.POST("/logout-all", h.LogoutAllHandler)
+			}
+
+			// ---------------------------------------------------
+			// 🛡️ 管理路由 (在登录的基础上还要求有对应的权限，admin 角色拥有全部权限)
+			// ---------------------------------------------------
+			admin := auth.Group("/admin")
+			{
+				// 解除登录锁定：POST /api/v1/admin/login/unlock
+				admin.POST("/login/unlock", middleware.RequirePermission(a.Service, "user:unlock"), h.UnlockLoginHandler)
+				// 分配 / 收回角色：POST /api/v1/admin/users/:user_id/roles  DELETE /api/v1/admin/users/:user_id/roles/:role
+				admin.POST("/users/:user_id/roles", middleware.RequirePermission(a.Service, "role:assign"), h.AssignRoleHandler)
+				admin.DELETE("/users/:user_id/roles/:role", middleware.RequirePermission(a.Service, "role:assign"), h.RevokeRoleHandler)
+			}
+
+			// 未来其他的私有接口写在这里，记得声明 Scope...
+			// auth.POST("/article/publish", middleware.RequireScope("article:write"), controller.CreateArticleHandler)
 		}
 	}
 
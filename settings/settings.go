@@ -7,22 +7,28 @@ import (
 	"github.com/spf13/viper"
 )
 
-// InitConfig 读取配置文件
-func InitConfig() error {
-	viper.SetConfigName("config") // 文件名 (不带后缀)
-	viper.SetConfigType("yaml")   // 文件类型
-	viper.AddConfigPath(".")      // 搜索路径 (当前目录)
+// Load 读取配置文件，返回一个独立的 viper 实例 (不使用 viper 的全局实例)
+// path 为空时读取当前目录下的 config.yaml，否则读取指定的文件 (命令行 --config)
+func Load(path string) (*viper.Viper, error) {
+	cfg := viper.New()
+	if path != "" {
+		cfg.SetConfigFile(path) // 类型按文件后缀判断
+	} else {
+		cfg.SetConfigName("config") // 文件名 (不带后缀)
+		cfg.SetConfigType("yaml")   // 文件类型
+		cfg.AddConfigPath(".")      // 搜索路径 (当前目录)
+	}
 
-	err := viper.ReadInConfig()
+	err := cfg.ReadInConfig()
 	if err != nil {
-		return fmt.Errorf("读取配置失败: %s", err)
+		return nil, fmt.Errorf("读取配置失败: %s", err)
 	}
 
 	// 开启热加载 (改了配置文件不用重启)
-	viper.WatchConfig()
-	viper.OnConfigChange(func(e fsnotify.Event) {
+	cfg.WatchConfig()
+	cfg.OnConfigChange(func(e fsnotify.Event) {
 		fmt.Println("配置文件已修改:", e.Name)
 	})
 
-	return nil
+	return cfg, nil
 }